@@ -33,6 +33,16 @@ func fileExists(path string) (bool, error) {
 	return true, nil
 }
 
+// GetExamples returns the examples under examples/ of the blueprint at bpPath.
+func GetExamples(bpPath string) ([]*BlueprintMiscContent, error) {
+	return getExamples(filepath.Join(bpPath, examplesPath))
+}
+
+// GetModules returns the sub-modules under modules/ of the blueprint at bpPath.
+func GetModules(bpPath string) ([]*BlueprintMiscContent, error) {
+	return getModules(filepath.Join(bpPath, modulesPath))
+}
+
 func getExamples(configPath string) ([]*BlueprintMiscContent, error) {
 	return getDirPaths(configPath, reExamples)
 }
@@ -239,6 +239,12 @@ func parseBlueprintProviderVersions(versionsFile *hcl.File) ([]*ProviderVersion,
 	return v, nil
 }
 
+// GetBlueprintInterfaces gets the variables and outputs declared by the
+// Terraform module at configPath.
+func GetBlueprintInterfaces(configPath string) (*BlueprintInterface, error) {
+	return getBlueprintInterfaces(configPath)
+}
+
 // getBlueprintInterfaces gets the variables and outputs associated
 // with the blueprint
 func getBlueprintInterfaces(configPath string) (*BlueprintInterface, error) {
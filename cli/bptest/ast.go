@@ -27,3 +27,46 @@ func getTestFuncsFromFile(filePath string) ([]string, error) {
 	}
 	return testFuncs, nil
 }
+
+// stageDefinePrefix is the prefix of blueprint test methods defining custom stages, e.g. DefineVerify.
+const stageDefinePrefix = "Define"
+
+// getCustomStagesFromFile parses a go source file and returns the stages customized by each test
+// function, detected from calls to methods like DefineVerify within the function.
+func getCustomStagesFromFile(filePath string) (map[string][]string, error) {
+	fileSet := token.NewFileSet()
+	f, err := parser.ParseFile(fileSet, filePath, nil, parser.AllErrors)
+	if err != nil {
+		return nil, err
+	}
+	customStages := make(map[string][]string)
+	for _, decl := range f.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || !strings.HasPrefix(funcDecl.Name.Name, "Test") || funcDecl.Body == nil {
+			continue
+		}
+		defined := make(map[string]bool)
+		ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if stage, ok := strings.CutPrefix(sel.Sel.Name, stageDefinePrefix); ok {
+				defined[strings.ToLower(stage)] = true
+			}
+			return true
+		})
+		testStages := []string{}
+		for _, stage := range stages {
+			if defined[stage] {
+				testStages = append(testStages, stage)
+			}
+		}
+		customStages[funcDecl.Name.Name] = testStages
+	}
+	return customStages, nil
+}
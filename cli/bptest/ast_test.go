@@ -78,3 +78,57 @@ func writeTmpFile(t *testing.T, data string) (string, func()) {
 	f.Close()
 	return f.Name(), cleanup
 }
+
+func TestGetCustomStagesFromFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		want   map[string][]string
+		errMsg string
+	}{
+		{
+			name: "custom stages",
+			data: `package test
+
+import "testing"
+
+func TestA(t *testing.T) {
+	bpt := tft.NewTFBlueprintTest(t)
+	bpt.DefineVerify(func(assert *assert.Assertions) {
+		bpt.DefaultVerify(assert)
+	})
+	bpt.DefineApply(func(assert *assert.Assertions) {})
+	bpt.Test()
+}
+
+func TestB(t *testing.T) {
+	tft.NewTFBlueprintTest(t).Test()
+}
+
+func helper(bpt *tft.TFBlueprintTest) {
+	bpt.DefineTeardown(nil)
+}
+`,
+			want: map[string][]string{"TestA": {"apply", "verify"}, "TestB": {}},
+		},
+		{
+			name:   "invalid",
+			data:   `package test func`,
+			errMsg: "expected",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			filePath, cleanup := writeTmpFile(t, tt.data)
+			defer cleanup()
+			got, err := getCustomStagesFromFile(filePath)
+			if tt.errMsg != "" {
+				assert.ErrorContains(err, tt.errMsg)
+			} else {
+				assert.NoError(err)
+				assert.Equal(tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package bptest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/cli/bpmetadata"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// BlueprintConnectionOutputRule checks that connections refer to an output
+// published by the source blueprint with a type compatible with the target variable.
+//
+// Only sources resolvable by the lint context resolver are checked.
+type BlueprintConnectionOutputRule struct{}
+
+func (r *BlueprintConnectionOutputRule) name() string {
+	return "blueprint_connection_output_rule"
+}
+
+func (r *BlueprintConnectionOutputRule) enabled() bool {
+	return true
+}
+
+func (r *BlueprintConnectionOutputRule) severity() Severity {
+	return SeverityError
+}
+
+func (r *BlueprintConnectionOutputRule) check(ctx lintContext) error {
+	if ctx.resolver == nil {
+		return nil
+	}
+
+	var errs []error
+	for i, variable := range ctx.metadata.GetSpec().GetInterfaces().GetVariables() {
+		for j, conn := range variable.GetConnections() {
+			if conn.GetSource().GetSource() == "" || conn.GetSpec() == nil {
+				continue
+			}
+			field := fmt.Sprintf("spec.interfaces.variables[%d].connections[%d]", i, j)
+			source, err := ctx.resolver.resolve(conn.Source.Source)
+			if err != nil {
+				errs = append(errs, newLintError(field+".source.source", "%w", err))
+				continue
+			}
+			if source == nil {
+				continue // unknown source
+			}
+			if err := checkConnection(variable, conn, source); err != nil {
+				errs = append(errs, &lintError{field: field + ".spec." + err.field, err: err.err})
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkConnection checks a connection of variable against outputs of the source blueprint.
+//
+// The returned lint error field is relative to the connection spec.
+func checkConnection(variable *bpmetadata.BlueprintVariable, conn *bpmetadata.BlueprintConnection, source *bpmetadata.BlueprintMetadata) *lintError {
+	expr := conn.Spec.OutputExpr
+	traversal, diags := hclsyntax.ParseTraversalAbs([]byte(expr), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil // complex expressions are not validated
+	}
+	outputName := traversal.RootName()
+
+	var output *bpmetadata.BlueprintOutput
+	for _, o := range source.GetSpec().GetInterfaces().GetOutputs() {
+		if o.GetName() == outputName {
+			output = o
+			break
+		}
+	}
+	if output == nil {
+		return &lintError{field: "outputExpr", err: fmt.Errorf("output %q is not published by %s", outputName, conn.Source.Source)}
+	}
+
+	outType, ok := outputType(output)
+	if !ok {
+		return nil // output type unknown
+	}
+	rootType := outType
+	for _, step := range traversal[1:] {
+		if outType, ok = traverseType(outType, step); !ok {
+			return &lintError{field: "outputExpr", err: fmt.Errorf("outputExpr %q is not valid for output %q of type %s",
+				expr, outputName, typeexpr.TypeString(rootType))}
+		}
+	}
+
+	targetType, err := variableType(variable.VarType)
+	if err != nil {
+		return nil // invalid type constraints are not validated here
+	}
+	if conn.Spec.InputPath != nil && *conn.Spec.InputPath != "" {
+		for _, attr := range strings.Split(*conn.Spec.InputPath, ".") {
+			if targetType == cty.DynamicPseudoType {
+				break
+			}
+			if targetType, ok = attributeType(targetType, attr); !ok {
+				return &lintError{field: "inputPath", err: fmt.Errorf("inputPath %q is not valid for variable %q of type %s",
+					*conn.Spec.InputPath, variable.Name, variable.VarType)}
+			}
+		}
+	}
+
+	// Terraform converts module inputs using unsafe conversions, e.g. list to set
+	if !outType.Equals(targetType) && convert.GetConversionUnsafe(outType, targetType) == nil {
+		return &lintError{field: "outputExpr", err: fmt.Errorf("type %s of outputExpr %q is not compatible with variable %q of type %s",
+			typeexpr.TypeString(outType), expr, variable.Name, typeexpr.TypeString(targetType))}
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package bptest
+
+import (
+	"fmt"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlueprintConnectionOutputRule(t *testing.T) {
+	dir := path.Join("testdata", "lint", "connections")
+	cfg, err := loadLintConfig(path.Join(dir, lintConfigFile))
+	assert.NoError(t, err)
+	ctx, err := newLintContext(dir)
+	assert.NoError(t, err)
+	ctx.resolver = newLocalConnectionResolver(cfg)
+
+	runner := lintRunner{}
+	runner.RegisterRule(&BlueprintConnectionOutputRule{})
+	var got []string
+	for _, res := range runner.Run(ctx) {
+		got = append(got, fmt.Sprintf("%d: %s", res.Line, res.Message))
+	}
+	assert.Equal(t, []string{
+		`24: outputExpr "network.project" is not valid for output "network" of type object({id=string,name=string})`,
+		`39: type list(string) of outputExpr "subnets_ids" is not compatible with variable "subnet_count" of type number`,
+		`50: output "subnet_ids" is not published by github.com/terraform-google-modules/terraform-google-network`,
+		`63: inputPath "zone" is not valid for variable "vpc" of type object({ id = string, region = optional(string) })`,
+		`65: error reading connection source github.com/terraform-google-modules/terraform-google-missing: stat testdata/lint/connections/sources/missing: no such file or directory`,
+	}, got)
+
+	assert.NoError(t, (&BlueprintConnectionOutputRule{}).check(lintContext{metadata: ctx.metadata}), "rule should be skipped without resolver")
+}
@@ -1,7 +1,9 @@
 package bptest
 
 import (
+	"errors"
 	"fmt"
+
 	"github.com/hashicorp/go-version"
 )
 
@@ -15,19 +17,23 @@ func (r *BlueprintConnectionSourceVersionRule) enabled() bool {
 	return true
 }
 
+func (r *BlueprintConnectionSourceVersionRule) severity() Severity {
+	return SeverityError
+}
+
 func (r *BlueprintConnectionSourceVersionRule) check(ctx lintContext) error {
 	// Check if Spec or Interfaces is nil to avoid null pointer dereference
 	if ctx.metadata == nil || ctx.metadata.Spec == nil || ctx.metadata.Spec.Interfaces == nil {
-		fmt.Println("metadata, spec, or interfaces are nil")
 		return nil
 	}
 
-	for _, variable := range ctx.metadata.Spec.Interfaces.Variables {
+	var errs []error
+	for i, variable := range ctx.metadata.Spec.Interfaces.Variables {
 		if variable == nil {
 			continue // Skip if variable is nil
 		}
 
-		for _, conn := range variable.Connections {
+		for j, conn := range variable.Connections {
 			if conn == nil || conn.Source == nil {
 				continue // Skip if connection or source is nil
 			}
@@ -35,12 +41,12 @@ func (r *BlueprintConnectionSourceVersionRule) check(ctx lintContext) error {
 			if conn.Source.Version != "" {
 				_, err := version.NewConstraint(conn.Source.Version)
 				if err != nil {
-					return fmt.Errorf("invalid version: %w", err)
+					field := fmt.Sprintf("spec.interfaces.variables[%d].connections[%d].source.version", i, j)
+					errs = append(errs, newLintError(field, "invalid version: %w", err))
 				}
-				return nil
 			}
 		}
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
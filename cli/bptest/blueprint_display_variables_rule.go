@@ -0,0 +1,55 @@
+package bptest
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// BlueprintDisplayVariablesRule checks that every ui.input.variables entry in
+// display metadata refers to a variable declared by the TF module.
+type BlueprintDisplayVariablesRule struct{}
+
+func (r *BlueprintDisplayVariablesRule) name() string {
+	return "blueprint_display_variables_rule"
+}
+
+func (r *BlueprintDisplayVariablesRule) enabled() bool {
+	return true
+}
+
+func (r *BlueprintDisplayVariablesRule) severity() Severity {
+	return SeverityError
+}
+
+func (r *BlueprintDisplayVariablesRule) check(ctx lintContext) error {
+	if ctx.moduleInterfaces == nil || ctx.displayMetadata == nil {
+		return nil
+	}
+	displayVars := ctx.displayMetadata.GetSpec().GetUi().GetInput().GetVariables()
+
+	moduleVars := make(map[string]bool)
+	for _, v := range ctx.moduleInterfaces.Variables {
+		moduleVars[v.Name] = true
+	}
+
+	// sort for deterministic results
+	names := make([]string, 0, len(displayVars))
+	for name := range displayVars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		if !moduleVars[name] {
+			errs = append(errs, &lintError{
+				file:  ctx.displayFilePath,
+				field: fmt.Sprintf("spec.ui.input.variables.%s", name),
+				err:   fmt.Errorf("display variable %q is not declared in the module", name),
+			})
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,218 @@
+package bptest
+
+import (
+	"path"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/cli/bpmetadata"
+	"github.com/stretchr/testify/assert"
+)
+
+// newModuleLintContext returns a lint context for metadata and module variables.
+func newModuleLintContext(metadataVars, moduleVars []*bpmetadata.BlueprintVariable) lintContext {
+	return lintContext{
+		metadata: &bpmetadata.BlueprintMetadata{
+			Spec: &bpmetadata.BlueprintMetadataSpec{
+				Interfaces: &bpmetadata.BlueprintInterface{Variables: metadataVars},
+			},
+		},
+		moduleInterfaces: &bpmetadata.BlueprintInterface{Variables: moduleVars},
+	}
+}
+
+func TestBlueprintVariablesMatchModuleRule(t *testing.T) {
+	tests := []struct {
+		name         string
+		metadataVars []*bpmetadata.BlueprintVariable
+		moduleVars   []*bpmetadata.BlueprintVariable
+		wantErrs     []string
+	}{
+		{
+			name:         "matching variables",
+			metadataVars: []*bpmetadata.BlueprintVariable{{Name: "a"}, {Name: "b"}},
+			moduleVars:   []*bpmetadata.BlueprintVariable{{Name: "b"}, {Name: "a"}},
+		},
+		{
+			name:         "drift in both directions",
+			metadataVars: []*bpmetadata.BlueprintVariable{{Name: "a"}, {Name: "c"}},
+			moduleVars:   []*bpmetadata.BlueprintVariable{{Name: "a"}, {Name: "b"}},
+			wantErrs: []string{
+				`variable "c" is not declared in the module`,
+				`variable "b" declared in the module is missing from metadata`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&BlueprintVariablesMatchModuleRule{}).check(newModuleLintContext(tt.metadataVars, tt.moduleVars))
+			assertLintErrors(t, tt.wantErrs, err)
+		})
+	}
+}
+
+func TestBlueprintOutputsMatchModuleRule(t *testing.T) {
+	ctx := lintContext{
+		metadata: &bpmetadata.BlueprintMetadata{
+			Spec: &bpmetadata.BlueprintMetadataSpec{
+				Interfaces: &bpmetadata.BlueprintInterface{
+					Outputs: []*bpmetadata.BlueprintOutput{{Name: "a"}, {Name: "c"}},
+				},
+			},
+		},
+		moduleInterfaces: &bpmetadata.BlueprintInterface{
+			Outputs: []*bpmetadata.BlueprintOutput{{Name: "a"}, {Name: "b"}},
+		},
+	}
+	err := (&BlueprintOutputsMatchModuleRule{}).check(ctx)
+	assertLintErrors(t, []string{
+		`output "c" is not declared in the module`,
+		`output "b" declared in the module is missing from metadata`,
+	}, err)
+
+	assert.NoError(t, (&BlueprintOutputsMatchModuleRule{}).check(lintContext{metadata: ctx.metadata}), "rule should be skipped without module")
+}
+
+func TestBlueprintVariableTypeRule(t *testing.T) {
+	tests := []struct {
+		name       string
+		metaType   string
+		moduleType string
+		wantErrs   []string
+	}{
+		{
+			name:       "same type",
+			metaType:   "string",
+			moduleType: "string",
+		},
+		{
+			name:       "whitespace differences",
+			metaType:   "list(object({\n  name = string\n}))",
+			moduleType: "list(object({ name = string }))",
+		},
+		{
+			name:       "untyped variable",
+			metaType:   "any",
+			moduleType: "",
+		},
+		{
+			name:       "different type",
+			metaType:   "map(any)",
+			moduleType: "map(string)",
+			wantErrs:   []string{`variable "a" has varType "map(any)" but the module declares "map(string)"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newModuleLintContext(
+				[]*bpmetadata.BlueprintVariable{{Name: "a", VarType: tt.metaType}},
+				[]*bpmetadata.BlueprintVariable{{Name: "a", VarType: tt.moduleType}},
+			)
+			assertLintErrors(t, tt.wantErrs, (&BlueprintVariableTypeRule{}).check(ctx))
+		})
+	}
+}
+
+func TestBlueprintVariableRequiredRule(t *testing.T) {
+	tests := []struct {
+		name           string
+		metaRequired   bool
+		moduleRequired bool
+		wantErrs       []string
+	}{
+		{
+			name:           "both required",
+			metaRequired:   true,
+			moduleRequired: true,
+		},
+		{
+			name:           "both optional",
+			metaRequired:   false,
+			moduleRequired: false,
+		},
+		{
+			name:           "missing required",
+			metaRequired:   false,
+			moduleRequired: true,
+			wantErrs:       []string{`variable "a" has no default in the module but is not marked required`},
+		},
+		{
+			name:           "required with default",
+			metaRequired:   true,
+			moduleRequired: false,
+			wantErrs:       []string{`variable "a" has a default in the module but is marked required`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newModuleLintContext(
+				[]*bpmetadata.BlueprintVariable{{Name: "a", Required: tt.metaRequired}},
+				[]*bpmetadata.BlueprintVariable{{Name: "a", Required: tt.moduleRequired}},
+			)
+			assertLintErrors(t, tt.wantErrs, (&BlueprintVariableRequiredRule{}).check(ctx))
+		})
+	}
+}
+
+func TestBlueprintDisplayVariablesRule(t *testing.T) {
+	ctx := newModuleLintContext(nil, []*bpmetadata.BlueprintVariable{{Name: "a"}})
+	ctx.displayFilePath = "metadata.display.yaml"
+	ctx.displayMetadata = &bpmetadata.BlueprintMetadata{
+		Spec: &bpmetadata.BlueprintMetadataSpec{
+			Ui: &bpmetadata.BlueprintUI{
+				Input: &bpmetadata.BlueprintUIInput{
+					Variables: map[string]*bpmetadata.DisplayVariable{
+						"a": {Name: "a"},
+						"c": {Name: "c"},
+						"b": {Name: "b"},
+					},
+				},
+			},
+		},
+	}
+	err := (&BlueprintDisplayVariablesRule{}).check(ctx)
+	assertLintErrors(t, []string{
+		`display variable "b" is not declared in the module`,
+		`display variable "c" is not declared in the module`,
+	}, err)
+	for _, e := range flattenErrors(err) {
+		assert.Equal(t, "metadata.display.yaml", e.(*lintError).file)
+	}
+}
+
+func TestLintBlueprintModuleDrift(t *testing.T) {
+	report, err := LintBlueprint(path.Join("testdata", "lint", "module-drift"), "")
+	assert.NoError(t, err)
+
+	type location struct {
+		rule string
+		file string
+		line int
+	}
+	var got []location
+	for _, res := range report.Results {
+		got = append(got, location{res.Rule, path.Base(res.File), res.Line})
+	}
+	assert.Equal(t, []location{
+		{"blueprint_variables_match_module_rule", "metadata.yaml", 25},
+		{"blueprint_outputs_match_module_rule", "metadata.yaml", 29},
+		{"blueprint_outputs_match_module_rule", "metadata.yaml", 27},
+		{"blueprint_variable_type_rule", "metadata.yaml", 17},
+		{"blueprint_variable_required_rule", "metadata.yaml", 11},
+		{"blueprint_variable_required_rule", "metadata.yaml", 13},
+		{"blueprint_display_variables_rule", "metadata.display.yaml", 14},
+	}, got)
+}
+
+// assertLintErrors asserts messages of errors joined by a rule.
+func assertLintErrors(t *testing.T, want []string, err error) {
+	t.Helper()
+	if len(want) == 0 {
+		assert.NoError(t, err)
+		return
+	}
+	var got []string
+	for _, e := range flattenErrors(err) {
+		got = append(got, e.Error())
+	}
+	assert.Equal(t, want, got)
+}
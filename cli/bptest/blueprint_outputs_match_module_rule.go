@@ -0,0 +1,53 @@
+package bptest
+
+import (
+	"errors"
+	"fmt"
+)
+
+// BlueprintOutputsMatchModuleRule checks that outputs in metadata and
+// outputs declared by the TF module are the same.
+type BlueprintOutputsMatchModuleRule struct{}
+
+func (r *BlueprintOutputsMatchModuleRule) name() string {
+	return "blueprint_outputs_match_module_rule"
+}
+
+func (r *BlueprintOutputsMatchModuleRule) enabled() bool {
+	return true
+}
+
+func (r *BlueprintOutputsMatchModuleRule) severity() Severity {
+	return SeverityError
+}
+
+func (r *BlueprintOutputsMatchModuleRule) check(ctx lintContext) error {
+	if ctx.moduleInterfaces == nil {
+		return nil
+	}
+
+	moduleOutputs := make(map[string]bool)
+	for _, o := range ctx.moduleInterfaces.Outputs {
+		moduleOutputs[o.Name] = true
+	}
+
+	var errs []error
+	metadataOutputs := make(map[string]bool)
+	for i, o := range ctx.metadata.GetSpec().GetInterfaces().GetOutputs() {
+		if o == nil {
+			continue
+		}
+		metadataOutputs[o.Name] = true
+		if !moduleOutputs[o.Name] {
+			field := fmt.Sprintf("spec.interfaces.outputs[%d].name", i)
+			errs = append(errs, newLintError(field, "output %q is not declared in the module", o.Name))
+		}
+	}
+	for _, o := range ctx.moduleInterfaces.Outputs {
+		if !metadataOutputs[o.Name] {
+			errs = append(errs, newLintError("spec.interfaces.outputs", "output %q declared in the module is missing from metadata", o.Name))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,54 @@
+package bptest
+
+import (
+	"errors"
+	"fmt"
+)
+
+// BlueprintVariableRequiredRule checks that required of each metadata variable
+// matches the absence of a default in the TF module.
+type BlueprintVariableRequiredRule struct{}
+
+func (r *BlueprintVariableRequiredRule) name() string {
+	return "blueprint_variable_required_rule"
+}
+
+func (r *BlueprintVariableRequiredRule) enabled() bool {
+	return true
+}
+
+func (r *BlueprintVariableRequiredRule) severity() Severity {
+	return SeverityError
+}
+
+func (r *BlueprintVariableRequiredRule) check(ctx lintContext) error {
+	if ctx.moduleInterfaces == nil {
+		return nil
+	}
+
+	moduleRequired := make(map[string]bool)
+	for _, v := range ctx.moduleInterfaces.Variables {
+		moduleRequired[v.Name] = v.Required
+	}
+
+	var errs []error
+	for i, v := range ctx.metadata.GetSpec().GetInterfaces().GetVariables() {
+		if v == nil {
+			continue
+		}
+		required, ok := moduleRequired[v.Name]
+		if !ok {
+			continue // reported by BlueprintVariablesMatchModuleRule
+		}
+		if v.Required != required {
+			field := fmt.Sprintf("spec.interfaces.variables[%d]", i)
+			if required {
+				errs = append(errs, newLintError(field, "variable %q has no default in the module but is not marked required", v.Name))
+			} else {
+				errs = append(errs, newLintError(field, "variable %q has a default in the module but is marked required", v.Name))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
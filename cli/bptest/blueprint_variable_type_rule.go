@@ -0,0 +1,67 @@
+package bptest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// BlueprintVariableTypeRule checks that varType of each metadata variable
+// matches the type declared by the TF module.
+type BlueprintVariableTypeRule struct{}
+
+func (r *BlueprintVariableTypeRule) name() string {
+	return "blueprint_variable_type_rule"
+}
+
+func (r *BlueprintVariableTypeRule) enabled() bool {
+	return true
+}
+
+func (r *BlueprintVariableTypeRule) severity() Severity {
+	return SeverityError
+}
+
+func (r *BlueprintVariableTypeRule) check(ctx lintContext) error {
+	if ctx.moduleInterfaces == nil {
+		return nil
+	}
+
+	moduleTypes := make(map[string]string)
+	for _, v := range ctx.moduleInterfaces.Variables {
+		moduleTypes[v.Name] = v.VarType
+	}
+
+	var errs []error
+	for i, v := range ctx.metadata.GetSpec().GetInterfaces().GetVariables() {
+		if v == nil {
+			continue
+		}
+		moduleType, ok := moduleTypes[v.Name]
+		if !ok {
+			continue // reported by BlueprintVariablesMatchModuleRule
+		}
+		if normalizeVarType(v.VarType) != normalizeVarType(moduleType) {
+			field := fmt.Sprintf("spec.interfaces.variables[%d].varType", i)
+			errs = append(errs, newLintError(field, "variable %q has varType %q but the module declares %q", v.Name, v.VarType, moduleType))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// normalizeVarType removes whitespace from a TF type expression for comparison.
+// An untyped variable is treated as any.
+func normalizeVarType(t string) string {
+	t = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, t)
+	if t == "" {
+		return "any"
+	}
+	return t
+}
@@ -0,0 +1,53 @@
+package bptest
+
+import (
+	"errors"
+	"fmt"
+)
+
+// BlueprintVariablesMatchModuleRule checks that variables in metadata and
+// variables declared by the TF module are the same.
+type BlueprintVariablesMatchModuleRule struct{}
+
+func (r *BlueprintVariablesMatchModuleRule) name() string {
+	return "blueprint_variables_match_module_rule"
+}
+
+func (r *BlueprintVariablesMatchModuleRule) enabled() bool {
+	return true
+}
+
+func (r *BlueprintVariablesMatchModuleRule) severity() Severity {
+	return SeverityError
+}
+
+func (r *BlueprintVariablesMatchModuleRule) check(ctx lintContext) error {
+	if ctx.moduleInterfaces == nil {
+		return nil
+	}
+
+	moduleVars := make(map[string]bool)
+	for _, v := range ctx.moduleInterfaces.Variables {
+		moduleVars[v.Name] = true
+	}
+
+	var errs []error
+	metadataVars := make(map[string]bool)
+	for i, v := range ctx.metadata.GetSpec().GetInterfaces().GetVariables() {
+		if v == nil {
+			continue
+		}
+		metadataVars[v.Name] = true
+		if !moduleVars[v.Name] {
+			field := fmt.Sprintf("spec.interfaces.variables[%d].name", i)
+			errs = append(errs, newLintError(field, "variable %q is not declared in the module", v.Name))
+		}
+	}
+	for _, v := range ctx.moduleInterfaces.Variables {
+		if !metadataVars[v.Name] {
+			errs = append(errs, newLintError("spec.interfaces.variables", "variable %q declared in the module is missing from metadata", v.Name))
+		}
+	}
+
+	return errors.Join(errs...)
+}
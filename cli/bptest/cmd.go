@@ -6,15 +6,29 @@ import (
 	"path"
 
 	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/cli/util"
-	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var flags struct {
-	testDir   string
-	testStage string
-	setupVars map[string]string
+	testDir       string
+	testStage     string
+	setupVars     map[string]string
+	parallel      int
+	labels        []string
+	listFormat    string
+	listFilter    string
+	listLabels    []string
+	report        string
+	reportFile    string
+	resume        bool
+	keep          bool
+	fromState     bool
+	lintFormat    string
+	lintConfig    string
+	lintPath      string
+	lintRecursive bool
+	goldenOutputs []string
 }
 
 func init() {
@@ -26,8 +40,23 @@ func init() {
 	Cmd.AddCommand(lintCmd)
 
 	Cmd.PersistentFlags().StringVar(&flags.testDir, "test-dir", "", "Path to directory containing integration tests (default is computed by scanning current working directory)")
-	runCmd.Flags().StringVar(&flags.testStage, "stage", "", "Test stage to execute (default is running all stages in order - init, plan, apply, verify, teardown)")
+	listCmd.Flags().StringVar(&flags.listFormat, "format", listFormatTable, "Output format of tests (table, json or yaml)")
+	listCmd.Flags().StringVar(&flags.listFilter, "filter", "", "Only list tests with names matching the regex")
+	listCmd.Flags().StringSliceVar(&flags.listLabels, "label", []string{}, "Only list tests with one of the labels in their BlueprintTest config")
+	runCmd.Flags().StringVar(&flags.testStage, "stage", "", "Test stage to execute (default is running all stages in order - init, guardrail, upgrade, plan, apply, verify, teardown)")
 	runCmd.Flags().StringToStringVar(&flags.setupVars, "setup-var", map[string]string{}, "Specify outputs from the setup phase (useful with --stage=verify)")
+	runCmd.Flags().IntVar(&flags.parallel, "parallel", 1, "Number of tests to run concurrently, each in a separate go test process")
+	runCmd.Flags().StringSliceVar(&flags.labels, "label", []string{}, "Only run tests with one of the labels in their BlueprintTest config, running each test in a separate go test process")
+	runCmd.Flags().StringVar(&flags.report, "report", "", "Write a test report in the given format (junit or json), stages of parallel tests are reported if stages are logged with t.Logf")
+	runCmd.Flags().StringVar(&flags.reportFile, "report-file", "", "Path to write the test report to (default is stdout)")
+	runCmd.Flags().BoolVar(&flags.resume, "resume", false, "Resume a test from the first stage not completed in a previous run with --keep")
+	runCmd.Flags().BoolVar(&flags.keep, "keep", false, "Run stages one at a time persisting state under .bptest/ and skip teardown")
+	runCmd.Flags().BoolVar(&flags.fromState, "from-state", false, "Run the stage specified by --stage using state persisted by a previous run with --keep")
+	initCmd.Flags().StringSliceVar(&flags.goldenOutputs, "golden-output", []string{}, "Example outputs to compare with a golden file recorded by running the test with UPDATE_GOLDEN=true")
+	lintCmd.Flags().StringVar(&flags.lintFormat, "format", lintFormatText, "Output format of lint results (text, json or sarif)")
+	lintCmd.Flags().StringVar(&flags.lintConfig, "config", "", "Path to lint config (default is .bptlint.yaml in the blueprint path)")
+	lintCmd.Flags().StringVar(&flags.lintPath, "path", "", "Path to the blueprint to lint (default is current working directory)")
+	lintCmd.Flags().BoolVar(&flags.lintRecursive, "recursive", false, "Also lint every blueprint under modules/ and examples/ of the blueprint path")
 }
 
 var Cmd = &cobra.Command{
@@ -50,22 +79,15 @@ var listCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		tests, err = filterTests(tests, flags.listFilter, flags.listLabels)
+		if err != nil {
+			return err
+		}
 		// Warn if no tests found
 		if len(tests) < 1 {
 			Log.Warn("no tests discovered")
-			return nil
 		}
-		tbl := newTable()
-		tbl.AppendHeader(table.Row{"Name", "Config", "Location"})
-		for _, t := range tests {
-			if t.bptestCfg.Spec.Skip {
-				Log.Info(fmt.Sprintf("skipping %s due to BlueprintTest config %s", t.name, t.bptestCfg.Name))
-				continue
-			}
-			tbl.AppendRow(table.Row{t.name, t.config, t.location})
-		}
-		tbl.Render()
-		return nil
+		return writeTestList(os.Stdout, tests, flags.listFormat)
 	},
 }
 
@@ -75,12 +97,19 @@ var runCmd = &cobra.Command{
 	Long:  "Runs auto discovered and explicit integration tests",
 
 	Args: func(cmd *cobra.Command, args []string) error {
+		// tests can be selected by labels only
+		if len(flags.labels) > 0 {
+			return cobra.MaximumNArgs(1)(cmd, args)
+		}
 		if err := cobra.ExactArgs(1)(cmd, args); err != nil {
 			return err
 		}
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			args = []string{allTests}
+		}
 		intTestDir, err := getIntTestDir(flags.testDir)
 		if err != nil {
 			return fmt.Errorf("error discovering test dir: %w", err)
@@ -89,14 +118,57 @@ var runCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		if err := validateReportFormat(flags.report); err != nil {
+			return err
+		}
+		withReport := flags.report != ""
+		if flags.resume || flags.keep || flags.fromState {
+			if err := validateStateFlags(testStage, flags.resume, flags.keep, flags.fromState, flags.parallel, withReport); err != nil {
+				return err
+			}
+			if err := runWithState(intTestDir, testStage, args[0], flags.labels, flags.setupVars, flags.resume, flags.keep, flags.fromState); err != nil {
+				Log.Error(err.Error())
+				os.Exit(1)
+			}
+			return nil
+		}
+		if flags.parallel > 1 || len(flags.labels) > 0 {
+			results, err := runParallel(intTestDir, testStage, args[0], flags.labels, flags.setupVars, flags.parallel, withReport)
+			if err != nil {
+				return err
+			}
+			if withReport {
+				if err := mergeReports(results).writeFile(flags.reportFile, flags.report); err != nil {
+					Log.Error(err.Error())
+					os.Exit(1)
+				}
+			}
+			if hasFailures(results) {
+				os.Exit(1)
+			}
+			return nil
+		}
 		relTestPkg, err := validateAndGetRelativeTestPkg(intTestDir, args[0])
 		if err != nil {
 			return err
 		}
-		testCmd, err := getTestCmd(intTestDir, testStage, args[0], relTestPkg, flags.setupVars)
+		testCmd, err := getTestCmd(intTestDir, testStage, args[0], relTestPkg, flags.setupVars, withReport)
 		if err != nil {
 			return err
 		}
+		if withReport {
+			report, err := execWithReport(testCmd, func(line string) { fmt.Println(line) })
+			// report is written even if tests failed
+			if werr := report.writeFile(flags.reportFile, flags.report); werr != nil {
+				Log.Error(werr.Error())
+				os.Exit(1)
+			}
+			if err != nil {
+				Log.Error(err.Error())
+				os.Exit(1)
+			}
+			return nil
+		}
 		// if err during exec, exit instead of returning an error
 		// this prevents printing usage as the args were validated above
 		if err := streamExec(testCmd); err != nil {
@@ -121,7 +193,7 @@ var convertCmd = &cobra.Command{
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "initialize blueprint test",
-	Long:  "Initialize a new blueprint test",
+	Long:  "Initialize a new blueprint test for an example with assertions for the example outputs and the services required in metadata.yaml",
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var initTestName string
@@ -143,7 +215,7 @@ var initCmd = &cobra.Command{
 		} else {
 			initTestName = args[0]
 		}
-		return initTest(initTestName)
+		return initTest(initTestName, flags.goldenOutputs)
 	},
 }
 
@@ -153,7 +225,14 @@ var lintCmd = &cobra.Command{
 	Long:  "Lints TF blueprint",
 	Args:  cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		RunLintCommand()
+		report, err := RunLintCommand(flags.lintPath, flags.lintFormat, flags.lintConfig, flags.lintRecursive)
+		if err != nil {
+			return err
+		}
+		// exit instead of returning an error to avoid printing usage on lint failures
+		if report.HasErrors() {
+			os.Exit(1)
+		}
 		return nil
 	},
 }
@@ -0,0 +1,159 @@
+package bptest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/cli/bpmetadata"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// connectionResolver resolves metadata of blueprints referenced as connection sources.
+type connectionResolver interface {
+	// resolve returns metadata of the source blueprint, or nil if the source is unknown.
+	resolve(source string) (*bpmetadata.BlueprintMetadata, error)
+}
+
+// localConnectionResolver resolves connection sources from local checkouts or cached metadata files.
+type localConnectionResolver struct {
+	sources map[string]string // sources maps a connection source to a local path.
+	cache   map[string]*bpmetadata.BlueprintMetadata
+}
+
+// newLocalConnectionResolver returns a resolver for sources configured in cfg.
+func newLocalConnectionResolver(cfg *LintConfig) *localConnectionResolver {
+	r := &localConnectionResolver{
+		sources: make(map[string]string),
+		cache:   make(map[string]*bpmetadata.BlueprintMetadata),
+	}
+	if cfg == nil {
+		return r
+	}
+	for source, p := range cfg.ConnectionSources {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(cfg.dir, p)
+		}
+		r.sources[source] = p
+	}
+	return r
+}
+
+// resolve reads metadata of the source blueprint.
+//
+// A source with a sub directory, e.g. github.com/org/repo//modules/foo, is resolved
+// to the sub directory of the checkout configured for github.com/org/repo.
+func (r *localConnectionResolver) resolve(source string) (*bpmetadata.BlueprintMetadata, error) {
+	if m, ok := r.cache[source]; ok {
+		return m, nil
+	}
+	base, subDir, _ := strings.Cut(source, "//")
+	p, ok := r.sources[source]
+	if !ok {
+		if p, ok = r.sources[base]; !ok {
+			return nil, nil
+		}
+		p = filepath.Join(p, subDir)
+	}
+
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, fmt.Errorf("error reading connection source %s: %w", source, err)
+	}
+	dir, file := p, metadataFile
+	if !info.IsDir() {
+		dir, file = filepath.Split(p)
+	}
+	m, err := bpmetadata.UnmarshalMetadata(dir, file)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing metadata of connection source %s: %w", source, err)
+	}
+	r.cache[source] = m
+	return m, nil
+}
+
+// outputType converts the serialized type of an output into a cty type.
+//
+// outputType returns false if the output has no type or the type cannot be interpreted.
+func outputType(o *bpmetadata.BlueprintOutput) (cty.Type, bool) {
+	if o.GetType() == nil {
+		return cty.NilType, false
+	}
+	if _, isNull := o.Type.GetKind().(*structpb.Value_NullValue); isNull {
+		return cty.NilType, false
+	}
+	j, err := o.Type.MarshalJSON()
+	if err != nil {
+		return cty.NilType, false
+	}
+	t, err := ctyjson.UnmarshalType(j)
+	if err != nil {
+		return cty.NilType, false
+	}
+	return t, true
+}
+
+// variableType parses a TF type constraint expression into a cty type.
+// An empty type constraint is any type.
+func variableType(varType string) (cty.Type, error) {
+	if strings.TrimSpace(varType) == "" {
+		return cty.DynamicPseudoType, nil
+	}
+	expr, diags := hclsyntax.ParseExpression([]byte(varType), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return cty.NilType, fmt.Errorf("invalid varType %q: %s", varType, diags.Error())
+	}
+	t, _, diags := typeexpr.TypeConstraintWithDefaults(expr)
+	if diags.HasErrors() {
+		return cty.NilType, fmt.Errorf("invalid varType %q: %s", varType, diags.Error())
+	}
+	return t, nil
+}
+
+// traverseType returns the type of the value reached by a traversal step from a value of type t.
+func traverseType(t cty.Type, step hcl.Traverser) (cty.Type, bool) {
+	if t == cty.DynamicPseudoType {
+		return cty.DynamicPseudoType, true
+	}
+	switch s := step.(type) {
+	case hcl.TraverseAttr:
+		return attributeType(t, s.Name)
+	case hcl.TraverseIndex:
+		switch {
+		case t.IsListType(), t.IsMapType():
+			return t.ElementType(), true
+		case t.IsTupleType():
+			if s.Key.Type() != cty.Number || !s.Key.IsKnown() {
+				return cty.NilType, false
+			}
+			i, acc := s.Key.AsBigFloat().Int64()
+			if acc != 0 || i < 0 || int(i) >= len(t.TupleElementTypes()) {
+				return cty.NilType, false
+			}
+			return t.TupleElementType(int(i)), true
+		case t.IsObjectType():
+			if s.Key.Type() != cty.String {
+				return cty.NilType, false
+			}
+			return attributeType(t, s.Key.AsString())
+		}
+	}
+	return cty.NilType, false
+}
+
+// attributeType returns the type of attribute name of an object or the element type of a map.
+func attributeType(t cty.Type, name string) (cty.Type, bool) {
+	switch {
+	case t.IsObjectType() && t.HasAttribute(name):
+		return t.AttributeType(name), true
+	case t.IsMapType():
+		return t.ElementType(), true
+	}
+	return cty.NilType, false
+}
@@ -0,0 +1,113 @@
+package bptest
+
+import (
+	"path"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/stretchr/testify/assert"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestLocalConnectionResolver(t *testing.T) {
+	cfg, err := loadLintConfig(path.Join("testdata", "lint", "connections", lintConfigFile))
+	assert.NoError(t, err)
+	r := newLocalConnectionResolver(cfg)
+
+	tests := []struct {
+		name     string
+		source   string
+		wantName string
+		wantErr  bool
+	}{
+		{
+			name:     "local checkout",
+			source:   "github.com/terraform-google-modules/terraform-google-network",
+			wantName: "terraform-google-network",
+		},
+		{
+			name:     "cached metadata",
+			source:   "github.com/terraform-google-modules/terraform-google-vpc",
+			wantName: "terraform-google-vpc",
+		},
+		{
+			name:   "unknown source",
+			source: "github.com/terraform-google-modules/terraform-google-unknown",
+		},
+		{
+			name:    "missing checkout",
+			source:  "github.com/terraform-google-modules/terraform-google-missing",
+			wantErr: true,
+		},
+		{
+			name:    "sub module of local checkout",
+			source:  "github.com/terraform-google-modules/terraform-google-network//modules/subnets",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := r.resolve(tt.source)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			if tt.wantName == "" {
+				assert.Nil(t, m)
+				return
+			}
+			assert.Equal(t, tt.wantName, m.GetMetadata().GetName())
+		})
+	}
+}
+
+func TestTraverseType(t *testing.T) {
+	obj := cty.Object(map[string]cty.Type{"id": cty.String, "ports": cty.List(cty.Number)})
+	tests := []struct {
+		name   string
+		in     cty.Type
+		steps  hcl.Traversal
+		want   cty.Type
+		wantOk bool
+	}{
+		{"object attribute", obj, hcl.Traversal{hcl.TraverseAttr{Name: "id"}}, cty.String, true},
+		{"missing attribute", obj, hcl.Traversal{hcl.TraverseAttr{Name: "name"}}, cty.NilType, false},
+		{"nested list index", obj, hcl.Traversal{hcl.TraverseAttr{Name: "ports"}, hcl.TraverseIndex{Key: cty.NumberIntVal(0)}}, cty.Number, true},
+		{"object index by key", obj, hcl.Traversal{hcl.TraverseIndex{Key: cty.StringVal("id")}}, cty.String, true},
+		{"map attribute", cty.Map(cty.Bool), hcl.Traversal{hcl.TraverseAttr{Name: "any"}}, cty.Bool, true},
+		{"tuple index", cty.Tuple([]cty.Type{cty.String, cty.Number}), hcl.Traversal{hcl.TraverseIndex{Key: cty.NumberIntVal(1)}}, cty.Number, true},
+		{"tuple out of range", cty.Tuple([]cty.Type{cty.String}), hcl.Traversal{hcl.TraverseIndex{Key: cty.NumberIntVal(1)}}, cty.NilType, false},
+		{"any type", cty.DynamicPseudoType, hcl.Traversal{hcl.TraverseAttr{Name: "id"}}, cty.DynamicPseudoType, true},
+		{"primitive", cty.String, hcl.Traversal{hcl.TraverseAttr{Name: "id"}}, cty.NilType, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.in, true
+			for _, step := range tt.steps {
+				if got, ok = traverseType(got, step); !ok {
+					break
+				}
+			}
+			assert.Equal(t, tt.wantOk, ok)
+			if ok {
+				assert.True(t, tt.want.Equals(got), "got %s", typeexpr.TypeString(got))
+			}
+		})
+	}
+}
+
+func TestVariableType(t *testing.T) {
+	got, err := variableType("object({ id = string, region = optional(string) })")
+	assert.NoError(t, err)
+	assert.True(t, got.IsObjectType())
+	assert.True(t, got.AttributeOptional("region"))
+
+	got, err = variableType("")
+	assert.NoError(t, err)
+	assert.Equal(t, cty.DynamicPseudoType, got)
+
+	_, err = variableType("list(")
+	assert.Error(t, err)
+}
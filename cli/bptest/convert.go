@@ -5,10 +5,10 @@ import (
 	"embed"
 	"encoding/json"
 	"fmt"
-	"html/template"
 	"os"
 	"path"
 	"strings"
+	"text/template"
 
 	"github.com/iancoleman/strcase"
 	cb "google.golang.org/api/cloudbuild/v1"
@@ -28,10 +28,10 @@ var (
 	//go:embed templates
 	templateFiles          embed.FS
 	kitchenCFTStageMapping = map[string]string{
-		"create":   stages[0],
-		"converge": stages[2],
-		"verify":   stages[3],
-		"destroy":  stages[4],
+		"create":   "init",
+		"converge": "apply",
+		"verify":   "verify",
+		"destroy":  teardownStage,
 	}
 )
 
@@ -127,9 +127,14 @@ func convertTest(dir string) error {
 	for _, i := range inspec.Attributes {
 		inputs = append(inputs, i.Name)
 	}
+	// translate inspec controls
+	checks, err := convertInSpecControls(dir, inputs)
+	if err != nil {
+		return fmt.Errorf("error converting inspec controls: %w", err)
+	}
 	// get bpt skeleton
 	testName := path.Base(dir)
-	bpTest, err := getBPTestFromTmpl(testName, inputs)
+	bpTest, err := getBPTestFromTmpl(testName, inputs, checks)
 	if err != nil {
 		return fmt.Errorf("error creating blueprint test: %w", err)
 	}
@@ -161,8 +166,11 @@ func getTestFnName(name string) string {
 	return fmt.Sprintf("Test%s", strcase.ToCamel(name))
 }
 
-// getBPTestFromTmpl returns a skeleton blueprint test
-func getBPTestFromTmpl(testName string, inputs []string) (string, error) {
+// getBPTestFromTmpl returns a skeleton blueprint test with optional translated InSpec checks
+func getBPTestFromTmpl(testName string, inputs []string, checks *inspecChecks) (string, error) {
+	if checks == nil {
+		checks = &inspecChecks{}
+	}
 	pkgName := strcase.ToSnake(testName)
 	fnName := getTestFnName(testName)
 	tmpl, err := getTmplFileContents(bptTestFilename)
@@ -178,10 +186,14 @@ func getBPTestFromTmpl(testName string, inputs []string) (string, error) {
 		PkgName string
 		FnName  string
 		Inputs  []string
+		Checks  []string
+		TODOs   []string
 	}{
 		PkgName: pkgName,
 		FnName:  fnName,
 		Inputs:  inputs,
+		Checks:  checks.Checks,
+		TODOs:   checks.TODOs,
 	},
 	)
 	if err != nil {
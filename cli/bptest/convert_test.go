@@ -133,6 +133,54 @@ func TestSimpleExample(t *testing.T) {
 
 	bpt.Test()
 }
+`},
+		},
+		{
+			name: "inspec controls",
+			dir:  "gcloud-example",
+			expectedFilesContents: map[string]string{"gcloud_example_test.go": `package gcloud_example
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/gcloud"
+	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/tft"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGcloudExample(t *testing.T) {
+	bpt := tft.NewTFBlueprintTest(t)
+
+	bpt.DefineVerify(func(assert *assert.Assertions) {
+		bpt.DefaultVerify(assert)
+		
+		projectId := bpt.GetStringOutput("project_id")
+		clusterName := bpt.GetStringOutput("cluster_name")
+
+		// gcloud: GKE cluster configuration
+		op := gcloud.Runf(t, "--project=%s container clusters describe %s --zone=us-central1-a", projectId, clusterName)
+		assert.Equal("RUNNING", op.Get("status").String(), "is running")
+		assert.Equal(clusterName, op.Get("name").String(), "has the expected name")
+		assert.Equal(int64(3), op.Get("currentNodeCount").Int(), "has the expected node count")
+		assert.False(op.Get("legacyAbac.enabled").Bool(), "has the expected node count")
+
+		op = gcloud.Runf(t, "--project=%s container node-pools list --cluster %s --zone=us-central1-a", projectId, clusterName)
+		assert.Len(op.Array(), 2)
+		assert.Equal("n1-standard-2", op.Get("0.config.machineType").String())
+		assert.Equal("pd-standard", op.Get("0.config.diskType").String())
+		assert.Contains(op.Get("0.name").String(), "pool")
+
+		gcloud.Runf(t, "services list --project %s", projectId)
+
+		// TODO: convert remaining InSpec checks:
+		// - controls/gcloud.rb:43: expect(data['addonsConfig']).to include( "networkPolicyConfig" => { "disabled" => true, }, )
+		// - controls/gcloud.rb:70: its(:stdout) { should match(/container/) }
+		// - controls/gcloud.rb:73: describe google_compute_network(project: project_id, name: "default") do
+	})
+
+	bpt.Test()
+}
 `},
 		},
 	}
@@ -159,3 +207,77 @@ func TestSimpleExample(t *testing.T) {
 		})
 	}
 }
+
+func TestTranslateExpectation(t *testing.T) {
+	tr := &inspecTranslator{
+		inputs: map[string]bool{"project_id": true},
+		vars:   map[string]string{"project_id": "projectId"},
+	}
+	tests := []struct {
+		name   string
+		expect string
+		desc   string
+		want   []string
+	}{
+		{
+			name:   "eq string",
+			expect: "expect(data['status']).to eq 'RUNNING'",
+			desc:   "is running",
+			want:   []string{`assert.Equal("RUNNING", op.Get("status").String(), "is running")`},
+		},
+		{
+			name:   "not eq interpolated",
+			expect: `expect(data['network']).not_to eq "projects/#{project_id}/global/networks/default"`,
+			want:   []string{`assert.NotEqual(fmt.Sprintf("projects/%s/global/networks/default", projectId), op.Get("network").String())`},
+		},
+		{
+			name:   "eq bool",
+			expect: "expect(data['a.b'][0]['enabled']).to_not eq true",
+			want:   []string{`assert.False(op.Get("a\\.b.0.enabled").Bool())`},
+		},
+		{
+			name:   "length",
+			expect: "expect(data['items'].size).to eq(1)",
+			want:   []string{`assert.Len(op.Get("items").Array(), 1)`},
+		},
+		{
+			name:   "include pairs",
+			expect: "expect(data).to include('name' => project_id, 'count' => 2)",
+			want:   []string{`assert.Equal(projectId, op.Get("name").String())`, `assert.Equal(int64(2), op.Get("count").Int())`},
+		},
+		{
+			name:   "match",
+			expect: "expect(data['zone']).to match(/us-central1/i)",
+			want:   []string{"assert.Regexp(`(?i)us-central1`, op.Get(\"zone\").String())"},
+		},
+		{
+			name:   "be empty",
+			expect: "expect(data['labels']).not_to be_empty",
+			want:   []string{`assert.NotEmpty(op.Get("labels").Value())`},
+		},
+		{
+			name:   "nested hash",
+			expect: "expect(data).to include('config' => { 'a' => 1 })",
+		},
+		{
+			name:   "unknown var",
+			expect: "expect(data['name']).to eq cluster_name",
+		},
+		{
+			name:   "unknown matcher",
+			expect: "expect(data['name']).to start_with 'foo'",
+		},
+		{
+			name:   "not data",
+			expect: "expect(other['name']).to eq 'foo'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			got, ok := tr.translateExpectation(tt.expect, "data", tt.desc)
+			assert.Equal(tt.want != nil, ok)
+			assert.Equal(tt.want, got)
+		})
+	}
+}
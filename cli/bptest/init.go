@@ -1,17 +1,32 @@
 package bptest
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"go/format"
 	"os"
 	"path"
+	"slices"
+	"strings"
+	"text/template"
 
+	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/cli/bpmetadata"
 	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/cli/util"
 	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/discovery"
-	"github.com/hashicorp/terraform-config-inspect/tfconfig"
 	"github.com/iancoleman/strcase"
 )
 
-func initTest(name string) error {
+const (
+	bptInitTestFilename = "blueprint_init_test.go"
+	// goldenDir is the dir golden files are read from relative to the test.
+	goldenDir = "testdata"
+)
+
+// initTest scaffolds a blueprint test for the example name from the example outputs
+// and the requirements in the blueprint metadata.
+// goldenOutputs are compared with a golden file which is recorded by running the test with UPDATE_GOLDEN=true.
+func initTest(name string, goldenOutputs []string) error {
 	// check if test already exist
 	testDir := path.Join(intTestPath, name)
 	exists, err := util.Exists(testDir)
@@ -49,19 +64,14 @@ func initTest(name string) error {
 		return fmt.Errorf("unable to discover test configs for %s: %w", testDir, err)
 	}
 
-	// Parse config to expose outputs within test
-	mod, diags := tfconfig.LoadModule(testCfg)
-	if diags.HasErrors() {
-		return fmt.Errorf("error parsing outputs: %w", diags)
-	}
-	outputs := make([]string, 0, len(mod.Outputs))
-	for _, op := range mod.Outputs {
-		// todo(bharathkkb): make templates type aware
-		outputs = append(outputs, op.Name)
+	// introspect example outputs and blueprint requirements
+	data, err := getInitTestData(name, testCfg, goldenOutputs)
+	if err != nil {
+		return err
 	}
 
 	// render and write test
-	testFile, err := getBPTestFromTmpl(name, outputs)
+	testFile, err := getInitTestFromTmpl(data)
 	if err != nil {
 		return fmt.Errorf("error creating blueprint test: %w", err)
 	}
@@ -71,3 +81,86 @@ func initTest(name string) error {
 	}
 	return writeFile(path.Join(testDir, fmt.Sprintf("%s_test.go", strcase.ToSnake(name))), testFile)
 }
+
+// initTestData is the data used to render a new blueprint test.
+type initTestData struct {
+	PkgName string
+	FnName  string
+	// Outputs are the outputs of the example under test.
+	Outputs []string
+	// GoldenOutputs are the outputs compared with the golden file.
+	GoldenOutputs []string
+	// GoldenFile is the path of the golden file relative to the test.
+	GoldenFile string
+	// Services are the services required by the blueprint.
+	Services []string
+	// Roles are the roles required to deploy the blueprint.
+	Roles []string
+	// ProjectID is the expression for the project the services are enabled in.
+	ProjectID string
+}
+
+// getInitTestData introspects the example in testCfg and the blueprint metadata in the current dir.
+// goldenOutputs must be outputs of the example.
+func getInitTestData(name, testCfg string, goldenOutputs []string) (*initTestData, error) {
+	data := &initTestData{
+		PkgName:       strcase.ToSnake(name),
+		FnName:        getTestFnName(name),
+		GoldenOutputs: goldenOutputs,
+		// examples commonly use the project created by setup
+		ProjectID: `bpt.GetTFSetupStringOutput("project_id")`,
+	}
+	data.GoldenFile = path.Join(goldenDir, fmt.Sprintf("%s.json", data.FnName))
+	bpInterfaces, err := bpmetadata.GetBlueprintInterfaces(testCfg)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing outputs: %w", err)
+	}
+	for _, o := range bpInterfaces.Outputs {
+		data.Outputs = append(data.Outputs, o.Name)
+		if o.Name == "project_id" {
+			data.ProjectID = `outputs.Get("project_id.value").String()`
+		}
+	}
+	for _, o := range goldenOutputs {
+		if !slices.Contains(data.Outputs, o) {
+			return nil, fmt.Errorf("golden output %s is not an output of %s, one of %v expected", o, testCfg, data.Outputs)
+		}
+	}
+
+	metadata, err := bpmetadata.UnmarshalMetadata(".", "/"+metadataFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return data, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", metadataFile, err)
+	}
+	reqs := metadata.GetSpec().GetRequirements()
+	data.Services = reqs.GetServices()
+	for _, r := range reqs.GetRoles() {
+		for _, role := range r.GetRoles() {
+			data.Roles = append(data.Roles, fmt.Sprintf("%s on %s", role, strings.ToLower(r.GetLevel())))
+		}
+	}
+	return data, nil
+}
+
+// getInitTestFromTmpl returns a formatted blueprint test rendered from data.
+func getInitTestFromTmpl(data *initTestData) (string, error) {
+	tmpl, err := getTmplFileContents(bptInitTestFilename)
+	if err != nil {
+		return "", err
+	}
+	t, err := template.New("test").Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+	var tpl bytes.Buffer
+	if err := t.Execute(&tpl, data); err != nil {
+		return "", err
+	}
+	formatted, err := format.Source(tpl.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("error formatting test: %w", err)
+	}
+	return string(formatted), nil
+}
@@ -17,6 +17,7 @@ func TestInitTest(t *testing.T) {
 	tests := []struct {
 		name                  string
 		bptName               string
+		goldenOutputs         []string
 		preProcessTestDir     func(t *testing.T, dir string)
 		expectedFilesContents map[string]string
 		errMsg                string
@@ -28,10 +29,8 @@ func TestInitTest(t *testing.T) {
 				"test/integration/foo/foo_test.go": `package foo
 
 import (
-	"fmt"
 	"testing"
 
-	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/gcloud"
 	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/tft"
 	"github.com/stretchr/testify/assert"
 )
@@ -41,11 +40,9 @@ func TestFoo(t *testing.T) {
 
 	bpt.DefineVerify(func(assert *assert.Assertions) {
 		bpt.DefaultVerify(assert)
-		
-		foo := bpt.GetStringOutput("foo")
 
-		op := gcloud.Run(t,"")
-		assert.Contains(op.Get("result").String(), "foo", "contains foo")
+		outputs := bpt.GetJsonOutput("")
+		assert.NotEmpty(outputs.Get("foo.value").Value(), "output foo should not be empty")
 	})
 
 	bpt.Test()
@@ -67,10 +64,8 @@ func TestFoo(t *testing.T) {
 				"test/integration/foo/foo_test.go": `package foo
 
 import (
-	"fmt"
 	"testing"
 
-	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/gcloud"
 	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/tft"
 	"github.com/stretchr/testify/assert"
 )
@@ -80,11 +75,9 @@ func TestFoo(t *testing.T) {
 
 	bpt.DefineVerify(func(assert *assert.Assertions) {
 		bpt.DefaultVerify(assert)
-		
-		foo := bpt.GetStringOutput("foo")
 
-		op := gcloud.Run(t,"")
-		assert.Contains(op.Get("result").String(), "foo", "contains foo")
+		outputs := bpt.GetJsonOutput("")
+		assert.NotEmpty(outputs.Get("foo.value").Value(), "output foo should not be empty")
 	})
 
 	bpt.Test()
@@ -101,6 +94,110 @@ require (
 `,
 			},
 		},
+		{
+			name:    "with metadata requirements",
+			bptName: "foo",
+			expectedFilesContents: map[string]string{
+				"test/integration/foo/foo_test.go": `package foo
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/gcloud"
+	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/tft"
+	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFoo(t *testing.T) {
+	bpt := tft.NewTFBlueprintTest(t)
+
+	bpt.DefineVerify(func(assert *assert.Assertions) {
+		bpt.DefaultVerify(assert)
+
+		outputs := bpt.GetJsonOutput("")
+		assert.NotEmpty(outputs.Get("foo.value").Value(), "output foo should not be empty")
+
+		// requirements from metadata.yaml
+		// requires roles/storage.admin on project
+		projectID := bpt.GetTFSetupStringOutput("project_id")
+		enabledServices := utils.GetResultStrSlice(gcloud.Runf(t, "services list --project %s", projectID).Get("#.config.name").Array())
+		for _, service := range []string{
+			"iam.googleapis.com",
+			"storage-api.googleapis.com",
+		} {
+			assert.Contains(enabledServices, service, "service %s should be enabled", service)
+		}
+	})
+
+	bpt.Test()
+}
+`,
+			},
+			preProcessTestDir: func(t *testing.T, dir string) {
+				err := os.WriteFile(path.Join(dir, "metadata.yaml"), []byte(`apiVersion: blueprints.cloud.google.com/v1alpha1
+kind: BlueprintMetadata
+metadata:
+  name: terraform-google-init
+spec:
+  info:
+    title: Init
+  requirements:
+    roles:
+      - level: Project
+        roles:
+          - roles/storage.admin
+    services:
+      - iam.googleapis.com
+      - storage-api.googleapis.com
+`), 0644)
+				if err != nil {
+					t.Fatalf("error creating metadata.yaml: %v", err)
+				}
+			},
+		},
+		{
+			name:          "with golden outputs",
+			bptName:       "foo",
+			goldenOutputs: []string{"foo"},
+			expectedFilesContents: map[string]string{
+				"test/integration/foo/foo_test.go": `package foo
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/golden"
+	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/tft"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFoo(t *testing.T) {
+	bpt := tft.NewTFBlueprintTest(t)
+
+	bpt.DefineVerify(func(assert *assert.Assertions) {
+		bpt.DefaultVerify(assert)
+
+		outputs := bpt.GetJsonOutput("")
+		assert.NotEmpty(outputs.Get("foo.value").Value(), "output foo should not be empty")
+
+		// run with UPDATE_GOLDEN=true to record testdata/TestFoo.json
+		g := golden.NewOrUpdate(t, outputs.String())
+		g.JSONPathEqs(assert, outputs, []string{
+			"foo.value",
+		})
+	})
+
+	bpt.Test()
+}
+`,
+			},
+		},
+		{
+			name:          "invalid golden output",
+			bptName:       "foo",
+			goldenOutputs: []string{"bar"},
+			errMsg:        "golden output bar is not an output of examples/foo, one of [foo] expected",
+		},
 		{
 			name:    "invalid already exists",
 			bptName: "bar",
@@ -126,7 +223,7 @@ require (
 			// switch to tmp dir for test
 			t.Cleanup(switchDir(t, tmpDir))
 
-			err = initTest(tt.bptName)
+			err = initTest(tt.bptName, tt.goldenOutputs)
 			if tt.errMsg != "" {
 				assert.NotNil(err)
 				assert.Contains(err.Error(), tt.errMsg)
@@ -139,6 +236,8 @@ require (
 					assert.NoError(err)
 					assert.Equal(expectedContent, string(gotContents))
 				}
+				// golden files are recorded by running the test, never scaffolded
+				assert.NoFileExists(path.Join(tmpDir, intTestPath, tt.bptName, goldenDir, "TestFoo.json"))
 			}
 		})
 	}
@@ -0,0 +1,573 @@
+package bptest
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+const inspecControlsDir = "controls"
+
+var (
+	inspecAttrRe     = regexp.MustCompile(`^(\w+)\s*=\s*(?:attribute|input)\(\s*['"]([\w-]+)['"]`)
+	inspecAttrRefRe  = regexp.MustCompile(`^(?:attribute|input)\(\s*['"]([\w-]+)['"]\s*\)$`)
+	inspecControlRe  = regexp.MustCompile(`^control\s+['"](.+?)['"]\s+do$`)
+	inspecTitleRe    = regexp.MustCompile(`^title\s+['"](.*)['"]$`)
+	inspecMetadataRe = regexp.MustCompile(`^(impact|desc|tag|ref)\b`)
+	inspecCommandRe  = regexp.MustCompile(`^describe\s+command\(\s*("(?:[^"\\]|\\.)*"|'[^']*')\s*\)\s+do$`)
+	inspecGroupRe    = regexp.MustCompile(`^describe\s+['"].*['"]\s+do$`)
+	inspecItsRe      = regexp.MustCompile(`^its\(\s*(?::(\w+)|['"](\w+)['"])\s*\)\s*\{\s*should\s+eq\s+(.+?)\s*\}$`)
+	inspecLetRe      = regexp.MustCompile(`^let!?\(\s*:(\w+)\s*\)\s+do$`)
+	inspecItRe       = regexp.MustCompile(`^it\s+(?:['"](.*)['"]\s+)?do$`)
+	inspecItInlineRe = regexp.MustCompile(`^it\s*\{\s*(.+?)\s*\}$`)
+	inspecExpectRe   = regexp.MustCompile(`^expect\((.+)\)\.(to|not_to|to_not)\s+(.+)$`)
+	inspecSubjectRe  = regexp.MustCompile(`^(\w+)((?:\[(?:'[^']*'|"[^"]*"|\d+)\])*)(?:\.(length|size|count))?$`)
+	inspecIndexRe    = regexp.MustCompile(`\[(?:'([^']*)'|"([^"]*)"|(\d+))\]`)
+	inspecMatcherRe  = regexp.MustCompile(`^(eq|include|match|be_empty)(?:\s*\((.*)\)|\s+(.+))?$`)
+	inspecRegexRe    = regexp.MustCompile(`^/(.*)/([imx]*)$`)
+	inspecFormatRe   = regexp.MustCompile(`\s*--format(?:=|\s+)(['"]?)json(['"]?)`)
+	inspecInterpRe   = regexp.MustCompile(`#\{([^}]*)\}`)
+	inspecOpenRe     = regexp.MustCompile(`(\sdo(\s*\|[^|]*\|)?$)|^(if|unless|case|begin|while|until)\b`)
+)
+
+// inspecChecks are InSpec controls translated into Go statements for a verify stage.
+type inspecChecks struct {
+	// Checks are Go statements, an empty statement is a blank line.
+	Checks []string
+	// TODOs are InSpec checks that could not be translated.
+	TODOs []string
+}
+
+// inspecLine is a logical line of an InSpec control file.
+type inspecLine struct {
+	text string
+	num  int
+}
+
+// inspecFrame is an open Ruby block while translating controls.
+type inspecFrame struct {
+	kind string
+	desc string
+	cmd  *inspecCommand
+}
+
+const (
+	inspecFrameControl = "control"
+	inspecFrameCommand = "command"
+	inspecFrameGroup   = "group"
+	inspecFrameIt      = "it"
+	inspecFrameSkip    = "skip"
+)
+
+// inspecCommand is a gcloud command check being translated.
+type inspecCommand struct {
+	call    string
+	dataVar string
+	asserts []string
+}
+
+// inspecTranslator translates InSpec controls into Go verify assertions.
+type inspecTranslator struct {
+	inputs     map[string]bool
+	vars       map[string]string
+	opDeclared bool
+	checks     inspecChecks
+}
+
+// convertInSpecControls translates InSpec controls in the controls dir of a kitchen test dir.
+// Recognised gcloud command checks are converted into gcloud.Runf calls with assertions
+// and anything else is reported as a TODO.
+func convertInSpecControls(dir string, inputs []string) (*inspecChecks, error) {
+	controlsDir := path.Join(dir, inspecControlsDir)
+	files, err := os.ReadDir(controlsDir)
+	if os.IsNotExist(err) {
+		return &inspecChecks{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading controls: %w", err)
+	}
+	var controlFiles []string
+	for _, f := range files {
+		if !f.IsDir() && path.Ext(f.Name()) == ".rb" {
+			controlFiles = append(controlFiles, f.Name())
+		}
+	}
+	sort.Strings(controlFiles)
+
+	tr := &inspecTranslator{inputs: make(map[string]bool), vars: make(map[string]string)}
+	for _, i := range inputs {
+		tr.inputs[i] = true
+	}
+	for _, f := range controlFiles {
+		b, err := os.ReadFile(path.Join(controlsDir, f))
+		if err != nil {
+			return nil, fmt.Errorf("error reading control %s: %w", f, err)
+		}
+		tr.translateFile(path.Join(inspecControlsDir, f), string(b))
+	}
+	return &tr.checks, nil
+}
+
+// translateFile translates the controls in contents of file f.
+func (tr *inspecTranslator) translateFile(f, contents string) {
+	var stack []*inspecFrame
+	// current returns the innermost frame of kind or nil.
+	current := func(kind string) *inspecFrame {
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i].kind == kind {
+				return stack[i]
+			}
+		}
+		return nil
+	}
+	todo := func(l inspecLine) {
+		tr.checks.TODOs = append(tr.checks.TODOs, fmt.Sprintf("%s:%d: %s", f, l.num, l.text))
+	}
+
+	for _, l := range inspecLogicalLines(contents) {
+		// skip nested blocks until they are closed
+		if len(stack) > 0 && stack[len(stack)-1].kind == inspecFrameSkip {
+			switch {
+			case l.text == "end":
+				stack = stack[:len(stack)-1]
+			case inspecOpenRe.MatchString(l.text):
+				stack = append(stack, &inspecFrame{kind: inspecFrameSkip})
+			}
+			continue
+		}
+		if l.text == "end" {
+			if len(stack) == 0 {
+				continue
+			}
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if top.kind == inspecFrameCommand {
+				tr.addCommand(top.cmd)
+			}
+			continue
+		}
+		if m := inspecAttrRe.FindStringSubmatch(l.text); m != nil {
+			if tr.inputs[m[2]] {
+				tr.vars[m[1]] = strcase.ToLowerCamel(m[2])
+			}
+			continue
+		}
+
+		cmd := current(inspecFrameCommand)
+		switch {
+		case cmd != nil:
+			stack = tr.translateCommandLine(stack, cmd.cmd, current(inspecFrameIt), l, todo)
+		case inspecControlRe.MatchString(l.text):
+			m := inspecControlRe.FindStringSubmatch(l.text)
+			stack = append(stack, &inspecFrame{kind: inspecFrameControl, desc: m[1]})
+		case current(inspecFrameControl) == nil:
+			// only controls are translated
+			if inspecOpenRe.MatchString(l.text) {
+				stack = append(stack, &inspecFrame{kind: inspecFrameSkip})
+			}
+		case inspecTitleRe.MatchString(l.text):
+			ctrl := current(inspecFrameControl)
+			ctrl.desc = fmt.Sprintf("%s: %s", ctrl.desc, inspecTitleRe.FindStringSubmatch(l.text)[1])
+		case inspecMetadataRe.MatchString(l.text):
+			continue
+		case inspecCommandRe.MatchString(l.text):
+			call, ok := tr.gcloudCall(inspecCommandRe.FindStringSubmatch(l.text)[1])
+			if !ok {
+				todo(l)
+				stack = append(stack, &inspecFrame{kind: inspecFrameSkip})
+				continue
+			}
+			tr.checks.Checks = append(tr.checks.Checks, "")
+			if ctrl := current(inspecFrameControl); ctrl.desc != "" {
+				tr.checks.Checks = append(tr.checks.Checks, fmt.Sprintf("// %s", ctrl.desc))
+				ctrl.desc = ""
+			}
+			stack = append(stack, &inspecFrame{kind: inspecFrameCommand, cmd: &inspecCommand{call: call}})
+		default:
+			todo(l)
+			if inspecOpenRe.MatchString(l.text) {
+				stack = append(stack, &inspecFrame{kind: inspecFrameSkip})
+			}
+		}
+	}
+}
+
+// translateCommandLine translates line l within a gcloud command check returning the new block stack.
+func (tr *inspecTranslator) translateCommandLine(stack []*inspecFrame, cmd *inspecCommand, it *inspecFrame, l inspecLine, todo func(inspecLine)) []*inspecFrame {
+	if m := inspecItsRe.FindStringSubmatch(l.text); m != nil {
+		attr, want := m[1]+m[2], m[3]
+		// gcloud.Runf fails the test on errors
+		if (attr == "exit_status" && want == "0") || (attr == "stderr" && (want == "''" || want == `""`)) {
+			return stack
+		}
+		todo(l)
+		return stack
+	}
+	if m := inspecLetRe.FindStringSubmatch(l.text); m != nil {
+		// gcloud.Runf parses the JSON output
+		cmd.dataVar = m[1]
+		return append(stack, &inspecFrame{kind: inspecFrameSkip})
+	}
+	if m := inspecItRe.FindStringSubmatch(l.text); m != nil {
+		return append(stack, &inspecFrame{kind: inspecFrameIt, desc: m[1]})
+	}
+	if inspecGroupRe.MatchString(l.text) {
+		return append(stack, &inspecFrame{kind: inspecFrameGroup})
+	}
+	expect, desc := l.text, ""
+	if m := inspecItInlineRe.FindStringSubmatch(l.text); m != nil {
+		expect = m[1]
+	} else if it != nil {
+		desc = it.desc
+	}
+	asserts, ok := tr.translateExpectation(expect, cmd.dataVar, desc)
+	if !ok {
+		todo(l)
+		if inspecOpenRe.MatchString(l.text) {
+			return append(stack, &inspecFrame{kind: inspecFrameSkip})
+		}
+		return stack
+	}
+	cmd.asserts = append(cmd.asserts, asserts...)
+	return stack
+}
+
+// addCommand adds statements for a translated gcloud command check.
+func (tr *inspecTranslator) addCommand(cmd *inspecCommand) {
+	switch {
+	case len(cmd.asserts) == 0:
+		tr.checks.Checks = append(tr.checks.Checks, cmd.call)
+	case tr.opDeclared:
+		tr.checks.Checks = append(tr.checks.Checks, fmt.Sprintf("op = %s", cmd.call))
+	default:
+		tr.checks.Checks = append(tr.checks.Checks, fmt.Sprintf("op := %s", cmd.call))
+		tr.opDeclared = true
+	}
+	tr.checks.Checks = append(tr.checks.Checks, cmd.asserts...)
+}
+
+// gcloudCall returns a gcloud.Runf call for a Ruby gcloud command string literal.
+func (tr *inspecTranslator) gcloudCall(lit string) (string, bool) {
+	quote, cmd := lit[0], lit[1:len(lit)-1]
+	cmd = strings.TrimSpace(cmd)
+	if !strings.HasPrefix(cmd, "gcloud ") {
+		return "", false
+	}
+	cmd = strings.TrimSpace(strings.TrimPrefix(cmd, "gcloud "))
+	// gcloud.Runf always requests JSON output
+	cmd = inspecFormatRe.ReplaceAllString(cmd, "")
+	if strings.Contains(cmd, "--format") {
+		return "", false
+	}
+	if quote == '\'' {
+		return fmt.Sprintf("gcloud.Run(t, %s)", strconv.Quote(cmd)), true
+	}
+	cmd = strings.ReplaceAll(cmd, `\"`, `"`)
+	var args []string
+	var format strings.Builder
+	last := 0
+	for _, loc := range inspecInterpRe.FindAllStringSubmatchIndex(cmd, -1) {
+		arg, ok := tr.goExpr(strings.TrimSpace(cmd[loc[2]:loc[3]]))
+		if !ok {
+			return "", false
+		}
+		format.WriteString(strings.ReplaceAll(cmd[last:loc[0]], "%", "%%"))
+		format.WriteString("%s")
+		args = append(args, arg)
+		last = loc[1]
+	}
+	if len(args) == 0 {
+		return fmt.Sprintf("gcloud.Run(t, %s)", strconv.Quote(cmd)), true
+	}
+	format.WriteString(strings.ReplaceAll(cmd[last:], "%", "%%"))
+	return fmt.Sprintf("gcloud.Runf(t, %s, %s)", strconv.Quote(format.String()), strings.Join(args, ", ")), true
+}
+
+// goExpr returns the Go variable for a Ruby expression referencing an input.
+func (tr *inspecTranslator) goExpr(expr string) (string, bool) {
+	if v, ok := tr.vars[expr]; ok {
+		return v, true
+	}
+	if m := inspecAttrRefRe.FindStringSubmatch(expr); m != nil && tr.inputs[m[1]] {
+		return strcase.ToLowerCamel(m[1]), true
+	}
+	return "", false
+}
+
+// goValue returns a Go expression and its kind (string, int or bool) for a Ruby literal or input reference.
+func (tr *inspecTranslator) goValue(v string) (string, string, bool) {
+	v = strings.TrimSpace(v)
+	switch {
+	case v == "true" || v == "false":
+		return v, "bool", true
+	case len(v) >= 2 && v[0] == '\'' && v[len(v)-1] == '\'':
+		return strconv.Quote(v[1 : len(v)-1]), "string", true
+	case len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"':
+		s := v[1 : len(v)-1]
+		var args []string
+		var failed bool
+		format := inspecInterpRe.ReplaceAllStringFunc(strings.ReplaceAll(s, "%", "%%"), func(m string) string {
+			arg, ok := tr.goExpr(strings.TrimSpace(m[2 : len(m)-1]))
+			if !ok {
+				failed = true
+			}
+			args = append(args, arg)
+			return "%s"
+		})
+		if failed {
+			return "", "", false
+		}
+		if len(args) == 0 {
+			return strconv.Quote(s), "string", true
+		}
+		return fmt.Sprintf("fmt.Sprintf(%s, %s)", strconv.Quote(format), strings.Join(args, ", ")), "string", true
+	}
+	if _, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return v, "int", true
+	}
+	if e, ok := tr.goExpr(v); ok {
+		return e, "string", true
+	}
+	return "", "", false
+}
+
+// translateExpectation translates an RSpec expectation on the parsed JSON output dataVar into assertions.
+func (tr *inspecTranslator) translateExpectation(expect, dataVar, desc string) ([]string, bool) {
+	m := inspecExpectRe.FindStringSubmatch(expect)
+	if m == nil || dataVar == "" {
+		return nil, false
+	}
+	subject, negate, matcher := strings.TrimSpace(m[1]), m[2] != "to", strings.TrimSpace(m[3])
+	s := inspecSubjectRe.FindStringSubmatch(subject)
+	if s == nil || s[1] != dataVar {
+		return nil, false
+	}
+	var keys []string
+	for _, idx := range inspecIndexRe.FindAllStringSubmatch(s[2], -1) {
+		keys = append(keys, gjsonKey(idx[1]+idx[2]+idx[3]))
+	}
+	op := gjsonGet(keys)
+	mm := inspecMatcherRe.FindStringSubmatch(matcher)
+	if mm == nil {
+		return nil, false
+	}
+	arg := strings.TrimSpace(mm[2] + mm[3])
+	msg := ""
+	if desc != "" {
+		msg = fmt.Sprintf(", %s", strconv.Quote(desc))
+	}
+
+	// length of the subject
+	if s[3] != "" {
+		n, err := strconv.Atoi(arg)
+		if mm[1] != "eq" || negate || err != nil {
+			return nil, false
+		}
+		return []string{fmt.Sprintf("assert.Len(%s.Array(), %d%s)", op, n, msg)}, true
+	}
+
+	switch mm[1] {
+	case "eq":
+		a, ok := tr.equalAssertion(op, arg, negate, msg)
+		if !ok {
+			return nil, false
+		}
+		return []string{a}, true
+	case "match":
+		r := inspecRegexRe.FindStringSubmatch(arg)
+		if r == nil {
+			return nil, false
+		}
+		re := r[1]
+		if r[2] != "" {
+			re = fmt.Sprintf("(?%s)%s", strings.ReplaceAll(r[2], "x", ""), re)
+		}
+		fn := "Regexp"
+		if negate {
+			fn = "NotRegexp"
+		}
+		return []string{fmt.Sprintf("assert.%s(%s, %s.String()%s)", fn, goRawString(re), op, msg)}, true
+	case "be_empty":
+		if arg != "" {
+			return nil, false
+		}
+		fn := "Empty"
+		if negate {
+			fn = "NotEmpty"
+		}
+		return []string{fmt.Sprintf("assert.%s(%s.Value()%s)", fn, op, msg)}, true
+	case "include":
+		pairs := splitRubyArgs(arg)
+		if len(pairs) == 0 {
+			return nil, false
+		}
+		// include a value
+		if len(pairs) == 1 && !strings.Contains(pairs[0], "=>") {
+			v, kind, ok := tr.goValue(pairs[0])
+			if !ok || kind != "string" {
+				return nil, false
+			}
+			fn := "Contains"
+			if negate {
+				fn = "NotContains"
+			}
+			return []string{fmt.Sprintf("assert.%s(%s.String(), %s%s)", fn, op, v, msg)}, true
+		}
+		// include key value pairs
+		if negate {
+			return nil, false
+		}
+		var asserts []string
+		for _, p := range pairs {
+			kv := strings.SplitN(p, "=>", 2)
+			if len(kv) != 2 {
+				return nil, false
+			}
+			k, kind, ok := tr.goValue(kv[0])
+			if !ok || kind != "string" || strings.HasPrefix(k, "fmt.") {
+				return nil, false
+			}
+			key, err := strconv.Unquote(k)
+			if err != nil {
+				return nil, false
+			}
+			a, ok := tr.equalAssertion(gjsonGet(append(append([]string{}, keys...), gjsonKey(key))), kv[1], false, msg)
+			if !ok {
+				return nil, false
+			}
+			asserts = append(asserts, a)
+		}
+		return asserts, true
+	}
+	return nil, false
+}
+
+// equalAssertion returns an assertion that the JSON value op equals the Ruby value v.
+func (tr *inspecTranslator) equalAssertion(op, v string, negate bool, msg string) (string, bool) {
+	want, kind, ok := tr.goValue(v)
+	if !ok {
+		return "", false
+	}
+	switch kind {
+	case "bool":
+		fn := "True"
+		if (want == "true") == negate {
+			fn = "False"
+		}
+		return fmt.Sprintf("assert.%s(%s.Bool()%s)", fn, op, msg), true
+	case "int":
+		want, op = fmt.Sprintf("int64(%s)", want), op+".Int()"
+	default:
+		op += ".String()"
+	}
+	fn := "Equal"
+	if negate {
+		fn = "NotEqual"
+	}
+	return fmt.Sprintf("assert.%s(%s, %s%s)", fn, want, op, msg), true
+}
+
+// gjsonKey escapes gjson path characters in key.
+func gjsonKey(key string) string {
+	r := strings.NewReplacer(".", `\.`, "*", `\*`, "?", `\?`, "|", `\|`, "#", `\#`, "@", `\@`)
+	return r.Replace(key)
+}
+
+// gjsonGet returns an expression getting the gjson path keys from op.
+func gjsonGet(keys []string) string {
+	if len(keys) == 0 {
+		return "op"
+	}
+	return fmt.Sprintf("op.Get(%s)", strconv.Quote(strings.Join(keys, ".")))
+}
+
+// goRawString returns s as a Go raw string literal if possible.
+func goRawString(s string) string {
+	if strings.Contains(s, "`") {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprintf("`%s`", s)
+}
+
+// splitRubyArgs splits Ruby arguments on top level commas.
+func splitRubyArgs(s string) []string {
+	var args []string
+	depth, start := 0, 0
+	var quote rune
+	for i, c := range s {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case c == ',' && depth == 0:
+			args = append(args, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		args = append(args, last)
+	}
+	return args
+}
+
+// inspecLogicalLines returns the trimmed non comment lines of contents,
+// joining lines with unclosed brackets.
+func inspecLogicalLines(contents string) []inspecLine {
+	var lines []inspecLine
+	var current []string
+	depth, start := 0, 0
+	for i, line := range strings.Split(contents, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if len(current) == 0 {
+			start = i + 1
+		}
+		current = append(current, line)
+		depth += bracketDepth(line)
+		if depth > 0 {
+			continue
+		}
+		lines = append(lines, inspecLine{text: strings.Join(current, " "), num: start})
+		current, depth = nil, 0
+	}
+	if len(current) > 0 {
+		lines = append(lines, inspecLine{text: strings.Join(current, " "), num: start})
+	}
+	return lines
+}
+
+// bracketDepth returns the change in bracket depth of line ignoring quoted strings.
+func bracketDepth(line string) int {
+	depth := 0
+	var quote rune
+	for _, c := range line {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		}
+	}
+	return depth
+}
@@ -1,47 +1,206 @@
 package bptest
 
 import (
+	"errors"
 	"fmt"
-	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/cli/bpmetadata"
 	"os"
+	"path/filepath"
+
+	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/cli/bpmetadata"
+	"gopkg.in/yaml.v3"
 )
 
-const metadataFile = "metadata.yaml"
+const (
+	metadataFile        = "metadata.yaml"
+	metadataDisplayFile = "metadata.display.yaml"
+	metadataParseRule   = "metadata_parse"
+)
+
+// LintBlueprint runs the metadata.yaml lint checks for the blueprint in bpDir.
+//
+// Rule defaults are overridden by the lint config at configPath. If configPath is empty,
+// .bptlint.yaml in bpDir is used if present. LintBlueprint returns an error only if
+// the lint checks could not be run, rule violations are returned in the report.
+func LintBlueprint(bpDir, configPath string) (*LintReport, error) {
+	cfg, err := loadLintConfig(lintConfigPath(bpDir, configPath))
+	if err != nil {
+		return nil, err
+	}
+	return lintBlueprint(bpDir, cfg)
+}
+
+// LintBlueprints runs the metadata.yaml lint checks for the blueprint in bpPath and,
+// if recursive, for every blueprint under its modules/ and examples/ directories.
+//
+// The lint config is resolved relative to bpPath and applied to all blueprints.
+// Unlike LintBlueprint, nested blueprints without metadata.yaml are skipped and a
+// metadata file that cannot be parsed is reported as a result so that remaining
+// blueprints are still linted.
+func LintBlueprints(bpPath, configPath string, recursive bool) (*LintReport, error) {
+	if !recursive {
+		return LintBlueprint(bpPath, configPath)
+	}
+	bpPath, err := filepath.Abs(bpPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := loadLintConfig(lintConfigPath(bpPath, configPath))
+	if err != nil {
+		return nil, err
+	}
+	bpDirs, err := getNestedBlueprintDirs(bpPath)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &LintReport{}
+	for _, bpDir := range bpDirs {
+		if _, err := os.Stat(filepath.Join(bpDir, metadataFile)); err != nil {
+			Log.Info(fmt.Sprintf("skipping %s without %s", bpDir, metadataFile))
+			continue
+		}
+		r, err := lintBlueprint(bpDir, cfg)
+		if err != nil {
+			r = &LintReport{
+				Files: []string{filepath.Join(bpDir, metadataFile)},
+				Results: []LintResult{{
+					Rule:     metadataParseRule,
+					Severity: SeverityError,
+					Message:  err.Error(),
+					File:     filepath.Join(bpDir, metadataFile),
+				}},
+			}
+		}
+		report.merge(r)
+	}
+	return report, nil
+}
 
-// RunLintCommand is the entry function that will run the metadata.yml lint checks.
-func RunLintCommand() {
-	dir, err := os.Getwd()
+// lintBlueprint runs the metadata.yaml lint checks for the blueprint in bpDir using cfg.
+func lintBlueprint(bpDir string, cfg *LintConfig) (*LintReport, error) {
+	ctx, err := newLintContext(bpDir)
 	if err != nil {
-		fmt.Printf("Error getting current directory: %v\n", err)
-		os.Exit(1)
+		return nil, err
+	}
+	ctx.resolver = newLocalConnectionResolver(cfg)
+	return &LintReport{
+		Files:   []string{ctx.filePath},
+		Results: newLintRunner(cfg).Run(ctx),
+	}, nil
+}
+
+// lintConfigPath returns configPath or .bptlint.yaml in bpDir if configPath is empty.
+func lintConfigPath(bpDir, configPath string) string {
+	if configPath == "" {
+		return filepath.Join(bpDir, lintConfigFile)
 	}
+	return configPath
+}
+
+// getNestedBlueprintDirs returns bpPath followed by the directories of its
+// sub-modules and examples.
+func getNestedBlueprintDirs(bpPath string) ([]string, error) {
+	dirs := []string{bpPath}
+	for _, getNested := range []func(string) ([]*bpmetadata.BlueprintMiscContent, error){
+		bpmetadata.GetModules,
+		bpmetadata.GetExamples,
+	} {
+		nested, err := getNested(bpPath)
+		if err != nil {
+			// modules/ and examples/ are optional
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		for _, n := range nested {
+			dirs = append(dirs, filepath.Join(bpPath, n.Location))
+		}
+	}
+	return dirs, nil
+}
+
+// newLintContext parses metadata.yaml, metadata.display.yaml and the TF module in bpDir into a lint context.
+func newLintContext(bpDir string) (lintContext, error) {
+	metadataPath := filepath.Join(bpDir, metadataFile)
 
 	// Parse medata.yaml to proto
-	metadata, err := bpmetadata.UnmarshalMetadata(dir, "/"+metadataFile)
-	metadataFile := dir + "/" + metadataFile
+	metadata, err := bpmetadata.UnmarshalMetadata(bpDir, "/"+metadataFile)
+	if err != nil {
+		return lintContext{}, fmt.Errorf("error parsing metadata file: %w", err)
+	}
 
+	// Parse metadata.yaml to node for locating violations
+	node, err := readYAMLNode(metadataPath)
 	if err != nil {
-		fmt.Printf("Error parsing metadata file: %v\n", err)
-		os.Exit(1)
+		return lintContext{}, fmt.Errorf("error parsing metadata file: %w", err)
 	}
 
 	ctx := lintContext{
-		metadata: metadata,
-		filePath: metadataFile,
+		metadata:        metadata,
+		filePath:        metadataPath,
+		node:            node,
+		displayFilePath: filepath.Join(bpDir, metadataDisplayFile),
 	}
 
-	runner := &lintRunner{}
-	runner.RegisterRule(&BlueprintConnectionSourceVersionRule{})
+	// Display metadata is optional
+	if _, err := os.Stat(ctx.displayFilePath); err == nil {
+		ctx.displayMetadata, err = bpmetadata.UnmarshalMetadata(bpDir, "/"+metadataDisplayFile)
+		if err != nil {
+			return lintContext{}, fmt.Errorf("error parsing display metadata file: %w", err)
+		}
+		ctx.displayNode, err = readYAMLNode(ctx.displayFilePath)
+		if err != nil {
+			return lintContext{}, fmt.Errorf("error parsing display metadata file: %w", err)
+		}
+	}
 
-	// Run lint checks
-	errs := runner.Run(ctx)
-	if len(errs) > 0 {
-		fmt.Println("Linting failed with the following errors:")
-		for _, err := range errs {
-			fmt.Println("- ", err)
+	// Rules comparing metadata to HCL are skipped if there is no TF module
+	tfFiles, err := filepath.Glob(filepath.Join(bpDir, "*.tf"))
+	if err != nil {
+		return lintContext{}, err
+	}
+	if len(tfFiles) > 0 {
+		ctx.moduleInterfaces, err = bpmetadata.GetBlueprintInterfaces(bpDir)
+		if err != nil {
+			return lintContext{}, fmt.Errorf("error parsing TF module: %w", err)
 		}
-		os.Exit(1)
-	} else {
-		fmt.Println("All lint checks passed!")
 	}
+
+	return ctx, nil
+}
+
+// readYAMLNode parses the YAML file at path into a node.
+func readYAMLNode(path string) (*yaml.Node, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	node := &yaml.Node{}
+	if err := yaml.Unmarshal(b, node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// RunLintCommand is the entry function that will run the metadata.yml lint checks
+// for the blueprint in bpPath and write the report to stdout in the given format.
+//
+// If bpPath is empty, the current working directory is used.
+func RunLintCommand(bpPath, format, configPath string, recursive bool) (*LintReport, error) {
+	if bpPath == "" {
+		dir, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("error getting current directory: %w", err)
+		}
+		bpPath = dir
+	}
+	report, err := LintBlueprints(bpPath, configPath, recursive)
+	if err != nil {
+		return nil, err
+	}
+	if err := report.Write(os.Stdout, format); err != nil {
+		return nil, err
+	}
+	return report, nil
 }
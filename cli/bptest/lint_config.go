@@ -0,0 +1,85 @@
+package bptest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+const lintConfigFile = ".bptlint.yaml"
+
+// LintConfig overrides lint rule defaults for a repository.
+//
+// An example .bptlint.yaml:
+//
+//	rules:
+//	  blueprint_connection_source_version_rule:
+//	    enabled: false
+//	  some_other_rule:
+//	    severity: warning
+//	connectionSources:
+//	  github.com/terraform-google-modules/terraform-google-network: ../terraform-google-network
+type LintConfig struct {
+	Rules map[string]LintRuleConfig `yaml:"rules"`
+	// ConnectionSources maps connection sources to a local checkout of the source
+	// blueprint or a cached copy of its metadata.yaml. Relative paths are resolved
+	// from the directory of the config file.
+	ConnectionSources map[string]string `yaml:"connectionSources"`
+
+	dir string // Directory of the config file
+}
+
+// LintRuleConfig overrides defaults of a single rule. Unset fields keep the rule default.
+type LintRuleConfig struct {
+	Enabled  *bool    `yaml:"enabled,omitempty"`
+	Severity Severity `yaml:"severity,omitempty"`
+}
+
+// loadLintConfig reads lint config from path.
+//
+// An empty config is returned if path is empty or the file does not exist.
+func loadLintConfig(path string) (*LintConfig, error) {
+	cfg := &LintConfig{dir: filepath.Dir(path)}
+	if path == "" {
+		return cfg, nil
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading lint config %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(b, cfg); err != nil {
+		return nil, fmt.Errorf("error parsing lint config %s: %w", path, err)
+	}
+	for name, rc := range cfg.Rules {
+		if rc.Severity != "" && !validSeverity(rc.Severity) {
+			return nil, fmt.Errorf("invalid severity %q for rule %s in %s", rc.Severity, name, path)
+		}
+	}
+	return cfg, nil
+}
+
+// ruleEnabled returns whether rule should run, honoring config overrides.
+func (c *LintConfig) ruleEnabled(rule lintRule) bool {
+	if c != nil {
+		if rc, ok := c.Rules[rule.name()]; ok && rc.Enabled != nil {
+			return *rc.Enabled
+		}
+	}
+	return rule.enabled()
+}
+
+// ruleSeverity returns severity of violations reported by rule, honoring config overrides.
+func (c *LintConfig) ruleSeverity(rule lintRule) Severity {
+	if c != nil {
+		if rc, ok := c.Rules[rule.name()]; ok && rc.Severity != "" {
+			return rc.Severity
+		}
+	}
+	return rule.severity()
+}
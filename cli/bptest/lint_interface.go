@@ -1,15 +1,39 @@
 package bptest
 
 import (
+	"errors"
 	"fmt"
-	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/cli/bpmetadata"
 	"os"
+	"strconv"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/cli/bpmetadata"
+	"gopkg.in/yaml.v3"
 )
 
+// Severity is the severity level of a lint rule violation.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// validSeverity returns true if s is a known severity.
+func validSeverity(s Severity) bool {
+	switch s {
+	case SeverityError, SeverityWarning, SeverityInfo:
+		return true
+	}
+	return false
+}
+
 // lintRule defines the common interface for all metadata lint rules.
 type lintRule interface {
 	name() string            // Unique name of the rule
 	enabled() bool           // Indicates if the rule is enabled by default
+	severity() Severity      // Default severity of violations reported by the rule
 	check(lintContext) error // Main entrypoint for rule validation
 }
 
@@ -17,11 +41,69 @@ type lintRule interface {
 type lintContext struct {
 	metadata *bpmetadata.BlueprintMetadata // Parsed metadata for the blueprint
 	filePath string                        // Path of the metadata file being checked
+	node     *yaml.Node                    // Parsed YAML node of the metadata file used to locate violations
+
+	displayMetadata *bpmetadata.BlueprintMetadata // Parsed display metadata, nil if the blueprint has none
+	displayFilePath string                        // Path of the display metadata file
+	displayNode     *yaml.Node                    // Parsed YAML node of the display metadata file
+
+	moduleInterfaces *bpmetadata.BlueprintInterface // Variables and outputs parsed from HCL, nil if the blueprint has no TF module
+	resolver         connectionResolver             // Resolver for metadata of connection sources
+}
+
+// lintError is a rule violation located at a field of the metadata file.
+//
+// Rules may report multiple violations by joining lintErrors with errors.Join.
+type lintError struct {
+	file  string // Path of the file containing the field, defaults to the metadata file
+	field string // Dot separated path of the offending field, e.g. spec.interfaces.variables[0].name
+	err   error
+}
+
+func (e *lintError) Error() string { return e.err.Error() }
+
+func (e *lintError) Unwrap() error { return e.err }
+
+// newLintError returns a lint error located at the given field of the metadata file.
+func newLintError(field string, format string, a ...interface{}) error {
+	return &lintError{field: field, err: fmt.Errorf(format, a...)}
+}
+
+// LintResult is a single violation reported by a lint rule.
+type LintResult struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	File     string   `json:"file"`
+	Line     int      `json:"line,omitempty"` // Line is 0 if the violation could not be located.
 }
 
 // LintRunner is responsible for running all registered lint rules.
 type lintRunner struct {
-	rules []lintRule
+	rules  []lintRule
+	config *LintConfig
+}
+
+// newLintRunner returns a runner with all default rules registered using the given config.
+func newLintRunner(cfg *LintConfig) *lintRunner {
+	r := &lintRunner{config: cfg}
+	for _, rule := range defaultLintRules() {
+		r.RegisterRule(rule)
+	}
+	return r
+}
+
+// defaultLintRules returns all rules run by bptest lint.
+func defaultLintRules() []lintRule {
+	return []lintRule{
+		&BlueprintConnectionSourceVersionRule{},
+		&BlueprintVariablesMatchModuleRule{},
+		&BlueprintOutputsMatchModuleRule{},
+		&BlueprintVariableTypeRule{},
+		&BlueprintVariableRequiredRule{},
+		&BlueprintDisplayVariablesRule{},
+		&BlueprintConnectionOutputRule{},
+	}
 }
 
 // RegisterRule adds a new rule to the runner.
@@ -30,20 +112,121 @@ func (r *lintRunner) RegisterRule(rule lintRule) {
 }
 
 // Run runs all the registered rules on the provided context.
-func (r *lintRunner) Run(ctx lintContext) []error {
-	var errs []error
+func (r *lintRunner) Run(ctx lintContext) []LintResult {
+	var results []LintResult
 	if os.Getenv("BLUEPRINT_LINT_DISABLE") == "1" {
-		fmt.Println("BLUEPRINT_LINT_DISABLE is set to 1. Skipping lint checks.")
-		return errs
+		Log.Info("BLUEPRINT_LINT_DISABLE is set to 1. Skipping lint checks.")
+		return results
 	}
 
 	for _, rule := range r.rules {
-		if rule.enabled() {
-			err := rule.check(ctx)
-			if err != nil {
-				errs = append(errs, err)
+		if !r.config.ruleEnabled(rule) {
+			continue
+		}
+		err := rule.check(ctx)
+		if err == nil {
+			continue
+		}
+		sev := r.config.ruleSeverity(rule)
+		for _, e := range flattenErrors(err) {
+			res := LintResult{
+				Rule:     rule.name(),
+				Severity: sev,
+				Message:  e.Error(),
+				File:     ctx.filePath,
+			}
+			var le *lintError
+			if errors.As(e, &le) {
+				node := ctx.node
+				if le.file != "" && le.file != ctx.filePath {
+					res.File = le.file
+					node = nil
+					if le.file == ctx.displayFilePath {
+						node = ctx.displayNode
+					}
+				}
+				res.Line = yamlFieldLine(node, le.field)
 			}
+			results = append(results, res)
 		}
 	}
+	return results
+}
+
+// flattenErrors splits errors joined by errors.Join into individual errors.
+func flattenErrors(err error) []error {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	var errs []error
+	for _, e := range joined.Unwrap() {
+		errs = append(errs, flattenErrors(e)...)
+	}
 	return errs
 }
+
+// yamlFieldLine returns the line of the field in the YAML node.
+//
+// field is a dot separated path with list indexes, e.g. spec.interfaces.variables[0].name.
+// If the field cannot be found, the line of the closest existing parent is returned.
+// yamlFieldLine returns 0 if node is nil.
+func yamlFieldLine(node *yaml.Node, field string) int {
+	if node == nil {
+		return 0
+	}
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	line := node.Line
+	for _, seg := range strings.Split(field, ".") {
+		if seg == "" {
+			continue
+		}
+		key, idxs := splitFieldSegment(seg)
+		next := yamlMappingValue(node, key)
+		if next == nil {
+			return line
+		}
+		node, line = next.value, next.key.Line
+		for _, idx := range idxs {
+			if node.Kind != yaml.SequenceNode || idx >= len(node.Content) {
+				return line
+			}
+			node = node.Content[idx]
+			line = node.Line
+		}
+	}
+	return line
+}
+
+// splitFieldSegment splits a path segment like variables[0] into key and indexes.
+func splitFieldSegment(seg string) (string, []int) {
+	parts := strings.Split(seg, "[")
+	var idxs []int
+	for _, p := range parts[1:] {
+		i, err := strconv.Atoi(strings.TrimSuffix(p, "]"))
+		if err != nil {
+			break
+		}
+		idxs = append(idxs, i)
+	}
+	return parts[0], idxs
+}
+
+type yamlKeyValue struct {
+	key, value *yaml.Node
+}
+
+// yamlMappingValue returns the key and value nodes of key in a mapping node.
+func yamlMappingValue(node *yaml.Node, key string) *yamlKeyValue {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return &yamlKeyValue{key: node.Content[i], value: node.Content[i+1]}
+		}
+	}
+	return nil
+}
@@ -2,19 +2,24 @@ package bptest
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"path"
+	"path/filepath"
 	"testing"
 
 	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/cli/bpmetadata"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
 )
 
 const BlueprintLintDisableEnv = "BLUEPRINT_LINT_DISABLE"
 
 type mockLintRule struct {
-	Name    string
-	Enabled bool
-	Err     error
+	Name     string
+	Enabled  bool
+	Severity Severity
+	Err      error
 }
 
 func (m *mockLintRule) name() string {
@@ -25,6 +30,10 @@ func (m *mockLintRule) enabled() bool {
 	return m.Enabled
 }
 
+func (m *mockLintRule) severity() Severity {
+	return m.Severity
+}
+
 func (m *mockLintRule) check(ctx lintContext) error {
 	return m.Err
 }
@@ -32,7 +41,7 @@ func (m *mockLintRule) check(ctx lintContext) error {
 func TestLintRunner(t *testing.T) {
 	t.Run("register and run rules with lintRunner", func(t *testing.T) {
 		mockRule1 := &mockLintRule{Name: "MockRule1", Enabled: true, Err: nil}
-		mockRule2 := &mockLintRule{Name: "MockRule2", Enabled: true, Err: errors.New("lint error")}
+		mockRule2 := &mockLintRule{Name: "MockRule2", Enabled: true, Severity: SeverityWarning, Err: errors.New("lint error")}
 		mockRule3 := &mockLintRule{Name: "MockRule3", Enabled: false, Err: nil}
 
 		runner := lintRunner{}
@@ -45,9 +54,11 @@ func TestLintRunner(t *testing.T) {
 			filePath: "/path/to/metadata/file.yaml",
 		}
 
-		errs := runner.Run(ctx)
-		assert.Len(t, errs, 1, "Only one rule should return an error")
-		assert.Equal(t, "lint error", errs[0].Error(), "Error message should match the expected lint error")
+		results := runner.Run(ctx)
+		assert.Len(t, results, 1, "Only one rule should return an error")
+		assert.Equal(t, "lint error", results[0].Message, "Error message should match the expected lint error")
+		assert.Equal(t, "MockRule2", results[0].Rule)
+		assert.Equal(t, SeverityWarning, results[0].Severity)
 	})
 
 	t.Run("run without registered rules", func(t *testing.T) {
@@ -57,8 +68,8 @@ func TestLintRunner(t *testing.T) {
 			filePath: "/path/to/metadata/file.yaml",
 		}
 
-		errs := runner.Run(ctx)
-		assert.Empty(t, errs, "No errors should be returned when no rules are registered")
+		results := runner.Run(ctx)
+		assert.Empty(t, results, "No errors should be returned when no rules are registered")
 	})
 	t.Run("skip lint rules when BLUEPRINT_LINT_DISABLE is set", func(t *testing.T) {
 		os.Setenv(BlueprintLintDisableEnv, "1")
@@ -76,7 +87,160 @@ func TestLintRunner(t *testing.T) {
 			filePath: "/path/to/metadata/file.yaml",
 		}
 
-		errs := runner.Run(ctx)
-		assert.Empty(t, errs, "No errors should be returned when BLUEPRINT_LINT_DISABLE is set")
+		results := runner.Run(ctx)
+		assert.Empty(t, results, "No errors should be returned when BLUEPRINT_LINT_DISABLE is set")
 	})
 }
+
+func TestLintRunnerConfig(t *testing.T) {
+	disabled := false
+	enabled := true
+	tests := []struct {
+		name         string
+		config       *LintConfig
+		wantSeverity []Severity
+	}{
+		{
+			name:         "rule defaults",
+			config:       nil,
+			wantSeverity: []Severity{SeverityError},
+		},
+		{
+			name: "disable rule",
+			config: &LintConfig{Rules: map[string]LintRuleConfig{
+				"MockRule1": {Enabled: &disabled},
+			}},
+		},
+		{
+			name: "enable rule and override severity",
+			config: &LintConfig{Rules: map[string]LintRuleConfig{
+				"MockRule1": {Severity: SeverityInfo},
+				"MockRule2": {Enabled: &enabled},
+			}},
+			wantSeverity: []Severity{SeverityInfo, SeverityError},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := lintRunner{config: tt.config}
+			runner.RegisterRule(&mockLintRule{Name: "MockRule1", Enabled: true, Severity: SeverityError, Err: errors.New("err1")})
+			runner.RegisterRule(&mockLintRule{Name: "MockRule2", Enabled: false, Severity: SeverityError, Err: errors.New("err2")})
+
+			var got []Severity
+			for _, res := range runner.Run(lintContext{}) {
+				got = append(got, res.Severity)
+			}
+			assert.Equal(t, tt.wantSeverity, got)
+		})
+	}
+}
+
+func TestLoadLintConfig(t *testing.T) {
+	cfg, err := loadLintConfig(path.Join("testdata", "lint", "with-config", lintConfigFile))
+	assert.NoError(t, err)
+	assert.Equal(t, SeverityWarning, cfg.Rules["blueprint_connection_source_version_rule"].Severity)
+
+	cfg, err = loadLintConfig(path.Join("testdata", "lint", "does-not-exist", lintConfigFile))
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.Rules)
+
+	invalid := path.Join(t.TempDir(), lintConfigFile)
+	assert.NoError(t, os.WriteFile(invalid, []byte("rules:\n  foo:\n    severity: fatal\n"), 0644))
+	_, err = loadLintConfig(invalid)
+	assert.ErrorContains(t, err, "invalid severity")
+}
+
+func TestLintBlueprint(t *testing.T) {
+	tests := []struct {
+		name         string
+		dir          string
+		wantSeverity Severity
+	}{
+		{
+			name:         "default config",
+			dir:          "invalid-version",
+			wantSeverity: SeverityError,
+		},
+		{
+			name:         "repo config",
+			dir:          "with-config",
+			wantSeverity: SeverityWarning,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := LintBlueprint(path.Join("testdata", "lint", tt.dir), "")
+			assert.NoError(t, err)
+			assert.Len(t, report.Results, 1)
+			res := report.Results[0]
+			assert.Equal(t, "blueprint_connection_source_version_rule", res.Rule)
+			assert.Equal(t, tt.wantSeverity, res.Severity)
+			assert.Equal(t, 24, res.Line)
+			assert.Contains(t, res.Message, "invalid_version")
+			assert.Equal(t, tt.wantSeverity == SeverityError, report.HasErrors())
+		})
+	}
+}
+
+func TestYamlFieldLine(t *testing.T) {
+	node := &yaml.Node{}
+	err := yaml.Unmarshal([]byte("a:\n  b:\n    - c: 1\n    - c: 2\n      d: 3\n"), node)
+	assert.NoError(t, err)
+	tests := []struct {
+		field string
+		want  int
+	}{
+		{"a", 1},
+		{"a.b", 2},
+		{"a.b[1]", 4},
+		{"a.b[1].d", 5},
+		{"a.b[1].missing", 4},
+		{"a.b[5].c", 2},
+		{"missing", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			assert.Equal(t, tt.want, yamlFieldLine(node, tt.field))
+		})
+	}
+	assert.Equal(t, 0, yamlFieldLine(nil, "a"))
+}
+
+func TestLintBlueprints(t *testing.T) {
+	repo := path.Join("testdata", "lint", "repo")
+
+	t.Run("root only", func(t *testing.T) {
+		report, err := LintBlueprints(repo, "", false)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{path.Join(repo, metadataFile)}, report.Files)
+		assert.Empty(t, report.Results)
+	})
+
+	t.Run("recursive", func(t *testing.T) {
+		report, err := LintBlueprints(repo, "", true)
+		assert.NoError(t, err)
+		var files []string
+		for _, f := range report.Files {
+			files = append(files, relPath(t, repo, f))
+		}
+		assert.Equal(t, []string{"metadata.yaml", "modules/broken/metadata.yaml", "modules/valid/metadata.yaml"}, files)
+
+		var got []string
+		for _, res := range report.Results {
+			got = append(got, fmt.Sprintf("%s:%d %s", relPath(t, repo, res.File), res.Line, res.Rule))
+		}
+		assert.Equal(t, []string{
+			"modules/broken/metadata.yaml:0 metadata_parse",
+			"modules/valid/metadata.yaml:11 blueprint_variable_type_rule",
+		}, got)
+	})
+}
+
+func relPath(t *testing.T, base, p string) string {
+	t.Helper()
+	abs, err := filepath.Abs(base)
+	assert.NoError(t, err)
+	rel, err := filepath.Rel(abs, p)
+	assert.NoError(t, err)
+	return filepath.ToSlash(rel)
+}
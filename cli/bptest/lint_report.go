@@ -0,0 +1,201 @@
+package bptest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	lintFormatText  = "text"
+	lintFormatJSON  = "json"
+	lintFormatSARIF = "sarif"
+
+	sarifSchema   = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion  = "2.1.0"
+	sarifToolName = "bptest lint"
+)
+
+// LintReport is the result of linting one or more metadata files.
+type LintReport struct {
+	Files   []string     `json:"files"` // Files are the metadata files linted.
+	Results []LintResult `json:"results"`
+}
+
+// merge appends files and results of o into the report.
+func (r *LintReport) merge(o *LintReport) {
+	r.Files = append(r.Files, o.Files...)
+	r.Results = append(r.Results, o.Results...)
+}
+
+// HasErrors returns true if any result has error severity.
+func (r *LintReport) HasErrors() bool {
+	for _, res := range r.Results {
+		if res.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Write outputs the report into w in text, json or sarif format.
+func (r *LintReport) Write(w io.Writer, format string) error {
+	switch strings.ToLower(format) {
+	case lintFormatText, "":
+		return r.writeText(w)
+	case lintFormatJSON:
+		out := LintReport{Files: []string{}, Results: []LintResult{}}
+		out.merge(r)
+		return writeJSON(w, out)
+	case lintFormatSARIF:
+		return writeJSON(w, r.sarif())
+	default:
+		return fmt.Errorf("unsupported lint format %q, must be one of %s, %s or %s", format, lintFormatText, lintFormatJSON, lintFormatSARIF)
+	}
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// writeText outputs the report in a human readable format.
+//
+// A summary is appended if more than one file was linted.
+func (r *LintReport) writeText(w io.Writer) error {
+	var lines []string
+	if len(r.Results) == 0 {
+		lines = append(lines, "All lint checks passed!")
+	}
+	for _, res := range r.Results {
+		loc := displayPath(res.File)
+		if res.Line > 0 {
+			loc = fmt.Sprintf("%s:%d", loc, res.Line)
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s: %s [%s]", loc, res.Severity, res.Message, res.Rule))
+	}
+	if len(r.Files) > 1 {
+		counts := make(map[Severity]int)
+		for _, res := range r.Results {
+			counts[res.Severity]++
+		}
+		lines = append(lines, fmt.Sprintf("%d files linted: %d errors, %d warnings, %d info.",
+			len(r.Files), counts[SeverityError], counts[SeverityWarning], counts[SeverityInfo]))
+	}
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
+
+// displayPath returns p relative to the current working directory if possible.
+func displayPath(p string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return p
+	}
+	rel, err := filepath.Rel(cwd, p)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return p
+	}
+	return rel
+}
+
+// SARIF 2.1.0 subset used for reporting lint results.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	Id string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleId    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// sarifLevel maps severity to SARIF result level.
+func sarifLevel(s Severity) string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "note"
+	default:
+		return "error"
+	}
+}
+
+// sarif converts the report into a SARIF log.
+func (r *LintReport) sarif() sarifLog {
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: sarifToolName, Rules: []sarifRule{}}},
+		Results: []sarifResult{},
+	}
+	seen := make(map[string]bool)
+	for _, res := range r.Results {
+		if !seen[res.Rule] {
+			seen[res.Rule] = true
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{Id: res.Rule})
+		}
+		loc := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(displayPath(res.File))},
+		}}
+		if res.Line > 0 {
+			loc.PhysicalLocation.Region = &sarifRegion{StartLine: res.Line}
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleId:    res.Rule,
+			Level:     sarifLevel(res.Severity),
+			Message:   sarifMessage{Text: res.Message},
+			Locations: []sarifLocation{loc},
+		})
+	}
+	sort.SliceStable(run.Tool.Driver.Rules, func(i, j int) bool {
+		return run.Tool.Driver.Rules[i].Id < run.Tool.Driver.Rules[j].Id
+	})
+	return sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}}
+}
@@ -0,0 +1,80 @@
+package bptest
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintReportWrite(t *testing.T) {
+	report := &LintReport{Files: []string{"metadata.yaml"}, Results: []LintResult{
+		{Rule: "rule_b", Severity: SeverityError, Message: "bad version", File: "metadata.yaml", Line: 12},
+		{Rule: "rule_a", Severity: SeverityInfo, Message: "consider this", File: "metadata.yaml"},
+	}}
+
+	t.Run("text", func(t *testing.T) {
+		var buff bytes.Buffer
+		assert.NoError(t, report.Write(&buff, "text"))
+		assert.Equal(t, "metadata.yaml:12: error: bad version [rule_b]\nmetadata.yaml: info: consider this [rule_a]\n", buff.String())
+	})
+
+	t.Run("text without results", func(t *testing.T) {
+		var buff bytes.Buffer
+		assert.NoError(t, (&LintReport{}).Write(&buff, ""))
+		assert.Equal(t, "All lint checks passed!\n", buff.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buff bytes.Buffer
+		assert.NoError(t, report.Write(&buff, "json"))
+		var got LintReport
+		assert.NoError(t, json.Unmarshal(buff.Bytes(), &got))
+		assert.Equal(t, *report, got)
+	})
+
+	t.Run("json without results", func(t *testing.T) {
+		var buff bytes.Buffer
+		assert.NoError(t, (&LintReport{}).Write(&buff, "json"))
+		assert.JSONEq(t, `{"files": [], "results": []}`, buff.String())
+	})
+
+	t.Run("sarif", func(t *testing.T) {
+		var buff bytes.Buffer
+		assert.NoError(t, report.Write(&buff, "sarif"))
+		assert.JSONEq(t, `{
+			"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+			"version": "2.1.0",
+			"runs": [{
+				"tool": {"driver": {"name": "bptest lint", "rules": [{"id": "rule_a"}, {"id": "rule_b"}]}},
+				"results": [
+					{
+						"ruleId": "rule_b",
+						"level": "error",
+						"message": {"text": "bad version"},
+						"locations": [{"physicalLocation": {"artifactLocation": {"uri": "metadata.yaml"}, "region": {"startLine": 12}}}]
+					},
+					{
+						"ruleId": "rule_a",
+						"level": "note",
+						"message": {"text": "consider this"},
+						"locations": [{"physicalLocation": {"artifactLocation": {"uri": "metadata.yaml"}}}]
+					}
+				]
+			}]
+		}`, buff.String())
+	})
+
+	t.Run("text with multiple files", func(t *testing.T) {
+		var buff bytes.Buffer
+		multi := &LintReport{Files: []string{"modules/a/metadata.yaml"}}
+		multi.merge(report)
+		assert.NoError(t, multi.Write(&buff, "text"))
+		assert.Equal(t, "metadata.yaml:12: error: bad version [rule_b]\nmetadata.yaml: info: consider this [rule_a]\n2 files linted: 1 errors, 0 warnings, 1 info.\n", buff.String())
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		assert.Error(t, report.Write(&bytes.Buffer{}, "xml"))
+	})
+}
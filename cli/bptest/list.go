@@ -3,26 +3,76 @@ package bptest
 import (
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"sort"
 	"strings"
 
 	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/discovery"
+	"github.com/jedib0t/go-pretty/v6/table"
 	testing "github.com/mitchellh/go-testing-interface"
+	"gopkg.in/yaml.v3"
 )
 
 const (
 	discoverTestFilename = "discover_test.go"
+
+	listFormatTable = "table"
+	listFormatJSON  = "json"
+	listFormatYAML  = "yaml"
+
+	testTypeDiscovered = "discovered"
+	testTypeExplicit   = "explicit"
 )
 
 type bpTest struct {
-	name      string
-	config    string
-	location  string
-	bptestCfg discovery.BlueprintTestConfig
+	name         string
+	config       string
+	location     string
+	bptestCfg    discovery.BlueprintTestConfig
+	labels       []string
+	discovered   bool     // discovered is true for tests auto discovered from examples and fixtures
+	customStages []string // customStages are stages defined by an explicit test instead of the defaults
+}
+
+// getTestLabels returns spec.labels of the BlueprintTest config in cfgDir, if any.
+//
+// Labels are read directly as the released discovery package may not support them yet.
+func getTestLabels(cfgDir string) ([]string, error) {
+	b, err := os.ReadFile(path.Join(cfgDir, discovery.DefaultTestConfigFilename))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cfg struct {
+		Spec struct {
+			Labels []string `yaml:"labels"`
+		} `yaml:"spec"`
+	}
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return nil, fmt.Errorf("error parsing labels of %s: %w", cfgDir, err)
+	}
+	return cfg.Spec.Labels, nil
+}
+
+// hasAnyLabel returns true if labels is empty or test has one of labels.
+func (t bpTest) hasAnyLabel(labels []string) bool {
+	if len(labels) == 0 {
+		return true
+	}
+	for _, l := range labels {
+		if slices.Contains(t.labels, l) {
+			return true
+		}
+	}
+	return false
 }
 
 // getTests returns slice of all blueprint tests
@@ -75,7 +125,11 @@ func getDiscoveredTests(intTestDir string) ([]bpTest, error) {
 			if err != nil {
 				Log.Warn(fmt.Sprintf("error discovering BlueprintTest config: %v", err))
 			}
-			tests = append(tests, bpTest{name: fmt.Sprintf("%s/%s", discoverTestName, testName), config: testCfg, location: discoverTestFile, bptestCfg: bptestCfg})
+			labels, err := getTestLabels(testCfg)
+			if err != nil {
+				Log.Warn(fmt.Sprintf("error discovering BlueprintTest labels: %v", err))
+			}
+			tests = append(tests, bpTest{name: fmt.Sprintf("%s/%s", discoverTestName, testName), config: testCfg, location: discoverTestFile, bptestCfg: bptestCfg, labels: labels, discovered: true})
 		}
 	}
 	sort.SliceStable(tests, func(i, j int) bool { return tests[i].name < tests[j].name })
@@ -109,12 +163,21 @@ func getExplicitTests(intTestDir string) ([]bpTest, error) {
 			Log.Warn(fmt.Sprintf("error discovering BlueprintTest config: %v", err))
 		}
 
+		labels, err := getTestLabels(testCfg)
+		if err != nil {
+			Log.Warn(fmt.Sprintf("error discovering BlueprintTest labels: %v", err))
+		}
+
 		testFns, err := getTestFuncsFromFile(testFile)
 		if err != nil {
 			return nil, err
 		}
+		customStages, err := getCustomStagesFromFile(testFile)
+		if err != nil {
+			return nil, err
+		}
 		for _, fnName := range testFns {
-			eTests = append(eTests, bpTest{name: fnName, location: testFile, config: testCfg, bptestCfg: bptestCfg})
+			eTests = append(eTests, bpTest{name: fnName, location: testFile, config: testCfg, bptestCfg: bptestCfg, labels: labels, customStages: customStages[fnName]})
 		}
 	}
 	sort.SliceStable(eTests, func(i, j int) bool { return eTests[i].name < eTests[j].name })
@@ -201,3 +264,79 @@ func findFiles(dir string, matchFn func(d fs.DirEntry) bool) ([]string, error) {
 
 	return files, nil
 }
+
+// testListEntry is a test as output by bptest list.
+type testListEntry struct {
+	Name         string   `json:"name" yaml:"name"`
+	Type         string   `json:"type" yaml:"type"`
+	Skip         bool     `json:"skip" yaml:"skip"`
+	Labels       []string `json:"labels" yaml:"labels"`
+	CustomStages []string `json:"customStages" yaml:"customStages"`
+	Config       string   `json:"config" yaml:"config"`
+	Location     string   `json:"location" yaml:"location"`
+}
+
+// filterTests returns tests with names matching the filter regex and one of labels.
+// An empty filter matches all tests.
+func filterTests(tests []bpTest, filter string, labels []string) ([]bpTest, error) {
+	re, err := regexp.Compile(filter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter %s: %w", filter, err)
+	}
+	filtered := []bpTest{}
+	for _, t := range tests {
+		if re.MatchString(t.name) && t.hasAnyLabel(labels) {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered, nil
+}
+
+// newTestListEntry returns the list entry of a test.
+func newTestListEntry(t bpTest) testListEntry {
+	e := testListEntry{
+		Name:         t.name,
+		Type:         testTypeExplicit,
+		Skip:         t.bptestCfg.Spec.Skip,
+		Labels:       []string{},
+		CustomStages: []string{},
+		Config:       t.config,
+		Location:     t.location,
+	}
+	if t.discovered {
+		e.Type = testTypeDiscovered
+	}
+	e.Labels = append(e.Labels, t.labels...)
+	e.CustomStages = append(e.CustomStages, t.customStages...)
+	return e
+}
+
+// writeTestList writes tests to w as a table, json or yaml.
+func writeTestList(w io.Writer, tests []bpTest, format string) error {
+	entries := []testListEntry{}
+	for _, t := range tests {
+		entries = append(entries, newTestListEntry(t))
+	}
+	switch format {
+	case listFormatTable:
+		tbl := newTable()
+		tbl.SetOutputMirror(w)
+		tbl.AppendHeader(table.Row{"Name", "Type", "Skip", "Labels", "Custom Stages", "Config", "Location"})
+		for _, e := range entries {
+			tbl.AppendRow(table.Row{e.Name, e.Type, e.Skip, strings.Join(e.Labels, ","), strings.Join(e.CustomStages, ","), e.Config, e.Location})
+		}
+		tbl.Render()
+		return nil
+	case listFormatJSON:
+		return writeJSON(w, entries)
+	case listFormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(entries); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return fmt.Errorf("invalid format %s - one of %+q expected", format, []string{listFormatTable, listFormatJSON, listFormatYAML})
+	}
+}
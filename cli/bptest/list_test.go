@@ -1,6 +1,7 @@
 package bptest
 
 import (
+	"bytes"
 	"os"
 	"path"
 	"testing"
@@ -25,8 +26,8 @@ func TestGetDiscoveredTests(t *testing.T) {
 			name:    "simple",
 			testDir: path.Join(testDirWithDiscovery, intTestDir),
 			want: []bpTest{
-				getBPTest("TestAll/examples/baz", path.Join(testDirWithDiscovery, "examples/baz"), path.Join(testDirWithDiscovery, intTestDir, discoverTestFilename), false),
-				getBPTest("TestAll/fixtures/qux", path.Join(testDirWithDiscovery, "test/fixtures/qux"), path.Join(testDirWithDiscovery, intTestDir, discoverTestFilename), false),
+				getBPTest("TestAll/examples/baz", path.Join(testDirWithDiscovery, "examples/baz"), path.Join(testDirWithDiscovery, intTestDir, discoverTestFilename), false, "smoke"),
+				getBPTest("TestAll/fixtures/qux", path.Join(testDirWithDiscovery, "test/fixtures/qux"), path.Join(testDirWithDiscovery, intTestDir, discoverTestFilename), false, "smoke", "slow"),
 				getBPTest("TestAll/examples/quux", path.Join(testDirWithDiscovery, "examples/quux"), path.Join(testDirWithDiscovery, intTestDir, discoverTestFilename), true),
 			},
 		},
@@ -94,8 +95,8 @@ func TestGetTests(t *testing.T) {
 			name:    "simple",
 			testDir: path.Join(testDirWithDiscovery, intTestDir),
 			want: []bpTest{
-				getBPTest("TestAll/examples/baz", path.Join(testDirWithDiscovery, "examples/baz"), path.Join(testDirWithDiscovery, intTestDir, discoverTestFilename), false),
-				getBPTest("TestAll/fixtures/qux", path.Join(testDirWithDiscovery, "test/fixtures/qux"), path.Join(testDirWithDiscovery, intTestDir, discoverTestFilename), false),
+				getBPTest("TestAll/examples/baz", path.Join(testDirWithDiscovery, "examples/baz"), path.Join(testDirWithDiscovery, intTestDir, discoverTestFilename), false, "smoke"),
+				getBPTest("TestAll/fixtures/qux", path.Join(testDirWithDiscovery, "test/fixtures/qux"), path.Join(testDirWithDiscovery, intTestDir, discoverTestFilename), false, "smoke", "slow"),
 				getBPTest("TestAll/examples/quux", path.Join(testDirWithDiscovery, "examples/quux"), path.Join(testDirWithDiscovery, intTestDir, discoverTestFilename), true),
 				getBPTest("TestBar", path.Join(testDirWithDiscovery, "examples/bar"), path.Join(testDirWithDiscovery, intTestDir, "bar/bar_test.go"), false),
 				getBPTest("TestFoo", path.Join(testDirWithDiscovery, "test/fixtures/foo"), path.Join(testDirWithDiscovery, intTestDir, "foo/foo_test.go"), false),
@@ -118,16 +119,20 @@ func TestGetTests(t *testing.T) {
 	}
 }
 
-func getBPTest(n string, c string, l string, s bool) bpTest {
+func getBPTest(n string, c string, l string, s bool, labels ...string) bpTest {
 	b := discovery.BlueprintTestConfig{}
 	b.Spec.Skip = s
-	if s {
+	if s || len(labels) > 0 {
 		b.APIVersion = "blueprints.cloud.google.com/v1alpha1"
 		b.Kind = "BlueprintTest"
 		b.Name = path.Base(c)
 		b.Path = path.Join(c, discovery.DefaultTestConfigFilename)
 	}
-	return bpTest{name: n, config: c, location: l, bptestCfg: b}
+	t := bpTest{name: n, config: c, location: l, bptestCfg: b, labels: labels, discovered: path.Base(l) == discoverTestFilename}
+	if !t.discovered {
+		t.customStages = []string{}
+	}
+	return t
 }
 
 func TestGetDiscoverTestName(t *testing.T) {
@@ -262,3 +267,144 @@ func createFilesInTmpDir(t *testing.T, files []string) (string, func()) {
 	}
 	return tempDir, cleanup
 }
+
+func TestFilterTests(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		labels []string
+		want   []string
+		errMsg string
+	}{
+		{
+			name: "all",
+			want: []string{"TestAll/examples/baz", "TestAll/fixtures/qux", "TestAll/examples/quux", "TestBar", "TestFoo", "TestQuuz"},
+		},
+		{
+			name:   "filter",
+			filter: "^TestAll/examples/",
+			want:   []string{"TestAll/examples/baz", "TestAll/examples/quux"},
+		},
+		{
+			name:   "label",
+			labels: []string{"slow"},
+			want:   []string{"TestAll/fixtures/qux"},
+		},
+		{
+			name:   "filter and label",
+			filter: "baz",
+			labels: []string{"smoke"},
+			want:   []string{"TestAll/examples/baz"},
+		},
+		{
+			name:   "invalid filter",
+			filter: "(",
+			errMsg: "invalid filter (",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			all, err := getTests(path.Join(testDirWithDiscovery, intTestDir))
+			assert.NoError(err)
+			got, err := filterTests(all, tt.filter, tt.labels)
+			if tt.errMsg != "" {
+				assert.ErrorContains(err, tt.errMsg)
+				return
+			}
+			assert.NoError(err)
+			assert.ElementsMatch(tt.want, testNames(got))
+		})
+	}
+}
+
+func TestWriteTestList(t *testing.T) {
+	tests := []bpTest{
+		getBPTest("TestAll/fixtures/qux", "test/fixtures/qux", "test/integration/discover_test.go", false, "smoke", "slow"),
+		getBPTest("TestQuuz", "test/fixtures/quuz", "test/integration/quuz/quuz_test.go", true),
+	}
+	tests[1].customStages = []string{"apply", "verify"}
+
+	for _, tt := range []struct {
+		format string
+		want   string
+		errMsg string
+	}{
+		{
+			format: listFormatJSON,
+			want: `[
+  {
+    "name": "TestAll/fixtures/qux",
+    "type": "discovered",
+    "skip": false,
+    "labels": [
+      "smoke",
+      "slow"
+    ],
+    "customStages": [],
+    "config": "test/fixtures/qux",
+    "location": "test/integration/discover_test.go"
+  },
+  {
+    "name": "TestQuuz",
+    "type": "explicit",
+    "skip": true,
+    "labels": [],
+    "customStages": [
+      "apply",
+      "verify"
+    ],
+    "config": "test/fixtures/quuz",
+    "location": "test/integration/quuz/quuz_test.go"
+  }
+]
+`,
+		},
+		{
+			format: listFormatYAML,
+			want: `- name: TestAll/fixtures/qux
+  type: discovered
+  skip: false
+  labels:
+    - smoke
+    - slow
+  customStages: []
+  config: test/fixtures/qux
+  location: test/integration/discover_test.go
+- name: TestQuuz
+  type: explicit
+  skip: true
+  labels: []
+  customStages:
+    - apply
+    - verify
+  config: test/fixtures/quuz
+  location: test/integration/quuz/quuz_test.go
+`,
+		},
+		{
+			format: "xml",
+			errMsg: "invalid format xml",
+		},
+	} {
+		t.Run(tt.format, func(t *testing.T) {
+			assert := assert.New(t)
+			var buff bytes.Buffer
+			err := writeTestList(&buff, tests, tt.format)
+			if tt.errMsg != "" {
+				assert.ErrorContains(err, tt.errMsg)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(tt.want, buff.String())
+		})
+	}
+
+	t.Run(listFormatTable, func(t *testing.T) {
+		var buff bytes.Buffer
+		assert.NoError(t, writeTestList(&buff, tests, listFormatTable))
+		for _, want := range []string{"CUSTOM STAGES", "TestQuuz", "explicit", "apply,verify", "smoke,slow"} {
+			assert.Contains(t, buff.String(), want)
+		}
+	})
+}
@@ -0,0 +1,232 @@
+package bptest
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+const (
+	testStatusPass = "PASS"
+	testStatusFail = "FAIL"
+	testStatusSkip = "SKIP"
+)
+
+// testResult is the outcome of running a single blueprint test.
+type testResult struct {
+	name     string
+	status   string
+	duration time.Duration
+	err      error
+	report   *testReport // report is only set if the test was run with a report.
+}
+
+// selectTests returns tests matching name which can be an exact test name, a regex or all.
+// If labels are specified, only tests with one of the labels are selected.
+// Tests skipped by BlueprintTest config are returned separately.
+func selectTests(intTestDir string, name string, labels []string) (selected []bpTest, skipped []bpTest, err error) {
+	tests, err := getTests(intTestDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	// an exact test name is not necessarily a valid regex
+	re, reErr := regexp.Compile(name)
+	testNames := []string{}
+	for _, test := range tests {
+		testNames = append(testNames, test.name)
+		matched := reErr == nil && re.MatchString(test.name)
+		if name != allTests && test.name != name && !matched || !test.hasAnyLabel(labels) {
+			continue
+		}
+		if test.bptestCfg.Spec.Skip {
+			skipped = append(skipped, test)
+			continue
+		}
+		// exact match takes precedence over loose matches
+		if test.name == name {
+			return []bpTest{test}, nil, nil
+		}
+		selected = append(selected, test)
+	}
+	if len(selected)+len(skipped) == 0 {
+		if reErr != nil {
+			return nil, nil, fmt.Errorf("invalid test name pattern %s: %w", name, reErr)
+		}
+		if len(labels) > 0 {
+			return nil, nil, fmt.Errorf("unable to find %s with labels %+q", name, labels)
+		}
+		return nil, nil, fmt.Errorf("unable to find %s- one of %+q expected", name, append(testNames, allTests))
+	}
+	return selected, skipped, nil
+}
+
+// exactRunPattern returns a go test -run pattern matching only the given test name.
+// Each slash separated element is anchored as go test matches subtests per element.
+func exactRunPattern(name string) string {
+	elems := strings.Split(name, "/")
+	for i, e := range elems {
+		elems[i] = fmt.Sprintf("^%s$", regexp.QuoteMeta(e))
+	}
+	return strings.Join(elems, "/")
+}
+
+// getParallelTestCmd returns a prepared cmd for running a single test using tmpDir
+// for temporary files and its Terraform plugin cache.
+//
+// The blueprint test framework guards the plugin cache with a lock file in the temp dir,
+// so each test gets its own plugin cache as the lock is isolated along with TMPDIR.
+func getParallelTestCmd(intTestDir string, testStage string, test bpTest, setupVars map[string]string, tmpDir string, jsonOutput bool) (*exec.Cmd, error) {
+	relPkg, err := filepath.Rel(intTestDir, path.Dir(test.location))
+	if err != nil {
+		return nil, err
+	}
+	cmd, err := getTestCmd(intTestDir, testStage, exactRunPattern(test.name), fmt.Sprintf("./%s", relPkg), setupVars, jsonOutput)
+	if err != nil {
+		return nil, err
+	}
+	// isolate temporary files such as copied TF configs between concurrent tests
+	cmd.Env = append(cmd.Env, fmt.Sprintf("TMPDIR=%s", tmpDir))
+	// Terraform plugin cache is not concurrent safe, Terraform requires the dir to exist
+	pluginCacheDir := filepath.Join(tmpDir, "plugin-cache")
+	if err := os.MkdirAll(pluginCacheDir, 0755); err != nil {
+		return nil, err
+	}
+	cmd.Env = append(cmd.Env, fmt.Sprintf("TF_PLUGIN_CACHE_DIR=%s", pluginCacheDir))
+	return cmd, nil
+}
+
+// runTestsParallel runs tests concurrently with at most parallel tests at a time.
+//
+// Results are returned in the order of tests.
+func runTestsParallel(tests []bpTest, parallel int, run func(bpTest) testResult) []testResult {
+	if parallel < 1 {
+		parallel = 1
+	}
+	results := make([]testResult, len(tests))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, test := range tests {
+		wg.Add(1)
+		go func(i int, test bpTest) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = run(test)
+		}(i, test)
+	}
+	wg.Wait()
+	return results
+}
+
+// runParallelTest executes cmd for test while streaming logs prefixed by test name into w.
+//
+// If withReport is set, cmd is expected to produce go test -json output.
+func runParallelTest(test bpTest, cmd *exec.Cmd, w io.Writer, withReport bool) testResult {
+	start := time.Now()
+	prefix := fmt.Sprintf("[%s] ", test.name)
+	skipped := false
+	logLine := func(line string) {
+		if strings.Contains(line, fmt.Sprintf("--- SKIP: %s ", test.name)) {
+			skipped = true
+		}
+		fmt.Fprintln(w, prefix+line)
+	}
+	var report *testReport
+	var err error
+	if withReport {
+		report, err = execWithReport(cmd, logLine)
+	} else {
+		err = streamExecFn(cmd, logLine)
+	}
+	r := testResult{name: test.name, status: testStatusPass, duration: time.Since(start), err: err, report: report}
+	switch {
+	case err != nil:
+		r.status = testStatusFail
+	case skipped:
+		r.status = testStatusSkip
+	}
+	return r
+}
+
+// syncWriter serializes writes from concurrent tests.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
+// runParallel runs tests matching name concurrently and renders a summary table.
+//
+// runParallel returns an error if tests could not be scheduled, test failures are
+// reported in the returned results.
+func runParallel(intTestDir, testStage, name string, labels []string, setupVars map[string]string, parallel int, withReport bool) ([]testResult, error) {
+	tests, skipped, err := selectTests(intTestDir, name, labels)
+	if err != nil {
+		return nil, err
+	}
+	Log.Info(fmt.Sprintf("running %d tests with parallelism %d", len(tests), parallel))
+	out := &syncWriter{w: os.Stdout}
+	results := runTestsParallel(tests, parallel, func(test bpTest) testResult {
+		tmpDir, err := os.MkdirTemp("", "bptest-")
+		if err != nil {
+			return testResult{name: test.name, status: testStatusFail, err: err}
+		}
+		defer os.RemoveAll(tmpDir)
+		cmd, err := getParallelTestCmd(intTestDir, testStage, test, setupVars, tmpDir, withReport)
+		if err != nil {
+			return testResult{name: test.name, status: testStatusFail, err: err}
+		}
+		return runParallelTest(test, cmd, out, withReport)
+	})
+	for _, test := range skipped {
+		Log.Info(fmt.Sprintf("skipping %s due to BlueprintTest config %s", test.name, test.bptestCfg.Name))
+		results = append(results, testResult{name: test.name, status: testStatusSkip})
+	}
+	renderTestResults(results)
+	return results, nil
+}
+
+// renderTestResults renders a summary table of test results.
+func renderTestResults(results []testResult) {
+	tbl := newTable()
+	tbl.AppendHeader(table.Row{"Name", "Status", "Duration"})
+	for _, r := range results {
+		tbl.AppendRow(table.Row{r.name, r.status, r.duration.Round(time.Second)})
+	}
+	tbl.Render()
+}
+
+// mergeReports combines reports of all tests run with a report.
+func mergeReports(results []testResult) *testReport {
+	report := &testReport{Tests: []*testCaseReport{}}
+	for _, r := range results {
+		if r.report != nil {
+			report.merge(r.report)
+		}
+	}
+	return report
+}
+
+// hasFailures returns true if any test failed.
+func hasFailures(results []testResult) bool {
+	for _, r := range results {
+		if r.status == testStatusFail {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package bptest
+
+import (
+	"bytes"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectTests(t *testing.T) {
+	tests := []struct {
+		name        string
+		testName    string
+		labels      []string
+		wantTests   []string
+		wantSkipped []string
+		errMsg      string
+	}{
+		{
+			name:        "all",
+			testName:    "all",
+			wantTests:   []string{"TestAll/examples/baz", "TestAll/fixtures/qux", "TestBar", "TestFoo"},
+			wantSkipped: []string{"TestAll/examples/quux", "TestQuuz"},
+		},
+		{
+			name:      "exact",
+			testName:  "TestBar",
+			wantTests: []string{"TestBar"},
+		},
+		{
+			name:        "regex",
+			testName:    "TestAll/.*",
+			wantTests:   []string{"TestAll/examples/baz", "TestAll/fixtures/qux"},
+			wantSkipped: []string{"TestAll/examples/quux"},
+		},
+		{
+			name:      "label",
+			testName:  "all",
+			labels:    []string{"smoke"},
+			wantTests: []string{"TestAll/examples/baz", "TestAll/fixtures/qux"},
+		},
+		{
+			name:      "any label",
+			testName:  "TestAll/.*",
+			labels:    []string{"slow", "other"},
+			wantTests: []string{"TestAll/fixtures/qux"},
+		},
+		{
+			name:     "invalid",
+			testName: "TestBaz",
+			errMsg:   "unable to find TestBaz",
+		},
+		{
+			name:     "invalid pattern",
+			testName: "TestAll/(",
+			errMsg:   "invalid test name pattern TestAll/(: error parsing regexp",
+		},
+		{
+			name:     "invalid label",
+			testName: "all",
+			labels:   []string{"other"},
+			errMsg:   "unable to find all with labels [\"other\"]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, skipped, err := selectTests(path.Join(testDirWithDiscovery, intTestDir), tt.testName, tt.labels)
+			if tt.errMsg != "" {
+				assert.ErrorContains(t, err, tt.errMsg)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantTests, testNames(selected))
+			assert.Equal(t, tt.wantSkipped, testNames(skipped))
+		})
+	}
+}
+
+func testNames(tests []bpTest) []string {
+	var names []string
+	for _, t := range tests {
+		names = append(names, t.name)
+	}
+	return names
+}
+
+func TestExactRunPattern(t *testing.T) {
+	assert.Equal(t, "^TestFoo$", exactRunPattern("TestFoo"))
+	assert.Equal(t, `^TestAll$/^examples$/^foo\.bar$`, exactRunPattern("TestAll/examples/foo.bar"))
+}
+
+func TestGetParallelTestCmd(t *testing.T) {
+	intDir := path.Join(testDirWithDiscovery, intTestDir)
+	test := bpTest{name: "TestAll/examples/baz", location: path.Join(intDir, discoverTestFilename)}
+	tmpDir := t.TempDir()
+	cmd, err := getParallelTestCmd(intDir, "verify", test, map[string]string{"k": "v"}, tmpDir, false)
+	assert.NoError(t, err)
+	assert.Subset(t, cmd.Args, []string{"./.", "-run", "^TestAll$/^examples$/^baz$"})
+	pluginCacheDir := filepath.Join(tmpDir, "plugin-cache")
+	assert.Subset(t, cmd.Env, []string{"RUN_STAGE=verify", "CFT_SETUP_k=v", "TMPDIR=" + tmpDir, "TF_PLUGIN_CACHE_DIR=" + pluginCacheDir})
+	assert.DirExists(t, pluginCacheDir)
+}
+
+func TestRunTestsParallel(t *testing.T) {
+	tests := []bpTest{{name: "a"}, {name: "b"}, {name: "c"}, {name: "d"}, {name: "e"}}
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	results := runTestsParallel(tests, 2, func(test bpTest) testResult {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return testResult{name: test.name, status: testStatusPass}
+	})
+	assert.Equal(t, 2, maxRunning, "at most parallel tests should run concurrently")
+	var names []string
+	for _, r := range results {
+		names = append(names, r.name)
+	}
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, names, "results should be in order of tests")
+}
+
+func TestRunParallelTest(t *testing.T) {
+	tests := []struct {
+		name       string
+		script     string
+		wantStatus string
+		wantOutput string
+	}{
+		{
+			name:       "pass",
+			script:     "echo ok",
+			wantStatus: testStatusPass,
+			wantOutput: "[TestFoo] ok\n",
+		},
+		{
+			name:       "fail",
+			script:     "echo failed; exit 1",
+			wantStatus: testStatusFail,
+			wantOutput: "[TestFoo] failed\n",
+		},
+		{
+			name:       "skip",
+			script:     "echo '--- SKIP: TestFoo (0.00s)'",
+			wantStatus: testStatusSkip,
+			wantOutput: "[TestFoo] --- SKIP: TestFoo (0.00s)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buff bytes.Buffer
+			r := runParallelTest(bpTest{name: "TestFoo"}, exec.Command("sh", "-c", tt.script), &buff, false)
+			assert.Equal(t, tt.wantStatus, r.status)
+			assert.Equal(t, tt.wantOutput, buff.String())
+		})
+	}
+}
+
+func TestHasFailures(t *testing.T) {
+	assert.False(t, hasFailures([]testResult{{status: testStatusPass}, {status: testStatusSkip}}))
+	assert.True(t, hasFailures([]testResult{{status: testStatusPass}, {status: testStatusFail}}))
+}
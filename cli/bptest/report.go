@@ -0,0 +1,359 @@
+package bptest
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	reportFormatJUnit = "junit"
+	reportFormatJSON  = "json"
+
+	// maxFailureLines is the number of trailing output lines used as failure message
+	// if no assertion failure could be identified.
+	maxFailureLines = 20
+)
+
+var (
+	// stage markers logged by github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/utils.RunStage
+	// and RunStageWithTimeout. go test -json attributes output which is not logged with testing.T, like
+	// markers of RunStage, to the last test that started or continued, so stages of tests calling
+	// t.Parallel() are only reported correctly if the markers are logged with t.Logf as by RunStageWithTimeout.
+	runStageRegex  = regexp.MustCompile(`Running stage (\w+)`)
+	skipStageRegex = regexp.MustCompile(`Skipping stage (\w+)`)
+	// file:line output of testing.T, including assertion failures reported by testify
+	failureRegex = regexp.MustCompile(`^\s+\S+\.go:\d+: `)
+)
+
+// validateReportFormat returns an error if format is not a supported report format.
+func validateReportFormat(format string) error {
+	switch format {
+	case "", reportFormatJUnit, reportFormatJSON:
+		return nil
+	}
+	return fmt.Errorf("invalid report format %s - one of %+q expected", format, []string{reportFormatJUnit, reportFormatJSON})
+}
+
+// testReport is the machine readable result of a test run.
+type testReport struct {
+	Tests []*testCaseReport `json:"tests"`
+}
+
+// testCaseReport is the result of a single test and its stages.
+type testCaseReport struct {
+	Name     string         `json:"name"`
+	Package  string         `json:"package"`
+	Status   string         `json:"status"`
+	Duration float64        `json:"durationSeconds"`
+	Stages   []*stageReport `json:"stages"`
+	Failure  string         `json:"failure,omitempty"`
+
+	start    time.Time
+	last     time.Time    // last is the time of the last event of the test.
+	current  *stageReport // current is the running stage, if any.
+	output   []string     // output is all lines logged by the test.
+	subTests bool         // subTests is true if the test is a parent of other tests.
+}
+
+// stageReport is the result of a single stage of a test.
+type stageReport struct {
+	Name     string  `json:"name"`
+	Status   string  `json:"status"`
+	Duration float64 `json:"durationSeconds"`
+	Failure  string  `json:"failure,omitempty"`
+
+	start   time.Time
+	failure []string // failure are file:line output blocks logged during the stage.
+}
+
+// goTestEvent is an event emitted by go test -json.
+type goTestEvent struct {
+	Time    time.Time
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64
+	Output  string
+}
+
+// testEventCollector builds a test report from go test -json output.
+type testEventCollector struct {
+	tests map[string]*testCaseReport
+	order []*testCaseReport
+}
+
+func newTestEventCollector() *testEventCollector {
+	return &testEventCollector{tests: make(map[string]*testCaseReport)}
+}
+
+// processLine records a line of go test -json output and returns the human readable
+// output it represents. Lines that are not test events are returned as is.
+func (c *testEventCollector) processLine(line string) (string, bool) {
+	var e goTestEvent
+	if !strings.HasPrefix(line, "{") || json.Unmarshal([]byte(line), &e) != nil {
+		return line, true
+	}
+	if e.Test == "" {
+		if e.Action == "output" {
+			return strings.TrimSuffix(e.Output, "\n"), true
+		}
+		return "", false
+	}
+
+	tc := c.getTest(e.Package, e.Test, e.Time)
+	tc.last = e.Time
+	switch e.Action {
+	case "output":
+		out := strings.TrimSuffix(e.Output, "\n")
+		tc.processOutput(out, e.Time)
+		return out, true
+	case "pass", "fail", "skip":
+		tc.finish(strings.ToUpper(e.Action), e.Elapsed, e.Time)
+	}
+	return "", false
+}
+
+// getTest returns the test report for a test, creating it if required.
+func (c *testEventCollector) getTest(pkg, name string, t time.Time) *testCaseReport {
+	key := pkg + " " + name
+	if tc, ok := c.tests[key]; ok {
+		return tc
+	}
+	tc := &testCaseReport{Name: name, Package: pkg, Stages: []*stageReport{}, start: t}
+	c.tests[key] = tc
+	c.order = append(c.order, tc)
+	// mark parents to only report leaf tests
+	for i := strings.LastIndex(name, "/"); i > 0; i = strings.LastIndex(name[:i], "/") {
+		if parent, ok := c.tests[pkg+" "+name[:i]]; ok {
+			parent.subTests = true
+		}
+	}
+	return tc
+}
+
+// processOutput records a line of test output, tracking stage markers and failures.
+func (tc *testCaseReport) processOutput(line string, t time.Time) {
+	tc.output = append(tc.output, line)
+	if m := runStageRegex.FindStringSubmatch(line); m != nil {
+		tc.endStage(t)
+		tc.current = &stageReport{Name: m[1], Status: testStatusPass, start: t}
+		tc.Stages = append(tc.Stages, tc.current)
+		return
+	}
+	if m := skipStageRegex.FindStringSubmatch(line); m != nil {
+		tc.endStage(t)
+		tc.Stages = append(tc.Stages, &stageReport{Name: m[1], Status: testStatusSkip})
+		return
+	}
+	if tc.current != nil && (failureRegex.MatchString(line) || len(tc.current.failure) > 0 && strings.HasPrefix(line, " ")) {
+		tc.current.failure = append(tc.current.failure, line)
+	}
+}
+
+// endStage ends the running stage, if any.
+func (tc *testCaseReport) endStage(t time.Time) {
+	if tc.current == nil {
+		return
+	}
+	tc.current.Duration = t.Sub(tc.current.start).Seconds()
+	tc.current = nil
+}
+
+// finish records the test result.
+func (tc *testCaseReport) finish(status string, elapsed float64, t time.Time) {
+	tc.endStage(t)
+	tc.Status = status
+	tc.Duration = elapsed
+	if status != testStatusFail {
+		return
+	}
+	// attribute failures to the stages they were reported in, preferring testify
+	// assertions over other file:line output such as t.Log
+	var failed []*stageReport
+	for _, s := range tc.Stages {
+		if strings.Contains(strings.Join(s.failure, "\n"), "Error Trace:") {
+			failed = append(failed, s)
+		}
+	}
+	if len(failed) == 0 {
+		for i := len(tc.Stages) - 1; i >= 0; i-- {
+			if len(tc.Stages[i].failure) > 0 {
+				failed = append(failed, tc.Stages[i])
+				break
+			}
+		}
+	}
+	for _, s := range failed {
+		s.Status = testStatusFail
+		s.Failure = strings.Join(s.failure, "\n")
+		if tc.Failure == "" {
+			tc.Failure = s.Failure
+		}
+	}
+	if tc.Failure != "" {
+		return
+	}
+	// fallback to trailing output of the test, failing the last stage that ran
+	start := len(tc.output) - maxFailureLines
+	if start < 0 {
+		start = 0
+	}
+	tc.Failure = strings.Join(tc.output[start:], "\n")
+	for i := len(tc.Stages) - 1; i >= 0; i-- {
+		if tc.Stages[i].Status == testStatusPass {
+			tc.Stages[i].Status = testStatusFail
+			tc.Stages[i].Failure = tc.Failure
+			break
+		}
+	}
+}
+
+// report returns the test report of all leaf tests in the order they were started.
+func (c *testEventCollector) report() *testReport {
+	r := &testReport{Tests: []*testCaseReport{}}
+	for _, tc := range c.order {
+		if tc.subTests {
+			continue
+		}
+		// tests interrupted without a result such as on timeout
+		if tc.Status == "" {
+			tc.finish(testStatusFail, tc.last.Sub(tc.start).Seconds(), tc.last)
+		}
+		r.Tests = append(r.Tests, tc)
+	}
+	return r
+}
+
+// execWithReport runs cmd producing go test -json output while passing human readable
+// output lines to fn and returns the test report.
+func execWithReport(cmd *exec.Cmd, fn func(string)) (*testReport, error) {
+	c := newTestEventCollector()
+	err := streamExecFn(cmd, func(line string) {
+		if out, ok := c.processLine(line); ok {
+			fn(out)
+		}
+	})
+	return c.report(), err
+}
+
+// merge appends tests of o into the report.
+func (r *testReport) merge(o *testReport) {
+	r.Tests = append(r.Tests, o.Tests...)
+}
+
+// write outputs the report into w in junit or json format.
+func (r *testReport) write(w io.Writer, format string) error {
+	switch format {
+	case reportFormatJSON:
+		return writeJSON(w, r)
+	case reportFormatJUnit:
+		if _, err := io.WriteString(w, xml.Header); err != nil {
+			return err
+		}
+		enc := xml.NewEncoder(w)
+		enc.Indent("", "  ")
+		if err := enc.Encode(r.junit()); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "\n")
+		return err
+	default:
+		return validateReportFormat(format)
+	}
+}
+
+// writeFile writes the report to path, or stdout if path is empty.
+func (r *testReport) writeFile(path, format string) error {
+	if path == "" {
+		return r.write(os.Stdout, format)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating report file: %w", err)
+	}
+	defer f.Close()
+	if err := r.write(f, format); err != nil {
+		return fmt.Errorf("error writing report: %w", err)
+	}
+	Log.Info(fmt.Sprintf("test report written to %s", path))
+	return nil
+}
+
+// JUnit XML representation where each test is a suite and each stage a test case.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Package  string          `xml:"package,attr,omitempty"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junit converts the report into JUnit XML test suites.
+//
+// Tests without stage markers are reported as a single test case named after the test.
+func (r *testReport) junit() junitTestSuites {
+	suites := junitTestSuites{}
+	var total float64
+	for _, tc := range r.Tests {
+		suite := junitTestSuite{Name: tc.Name, Package: tc.Package, Time: junitTime(tc.Duration)}
+		stages := tc.Stages
+		if len(stages) == 0 {
+			stages = []*stageReport{{Name: tc.Name, Status: tc.Status, Duration: tc.Duration, Failure: tc.Failure}}
+		}
+		for _, s := range stages {
+			c := junitTestCase{Name: s.Name, Classname: tc.Name, Time: junitTime(s.Duration)}
+			switch s.Status {
+			case testStatusFail:
+				c.Failure = &junitFailure{Message: fmt.Sprintf("stage %s failed", s.Name), Text: s.Failure}
+				suite.Failures++
+			case testStatusSkip:
+				c.Skipped = &struct{}{}
+				suite.Skipped++
+			}
+			suite.Cases = append(suite.Cases, c)
+		}
+		suite.Tests = len(suite.Cases)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Skipped += suite.Skipped
+		total += tc.Duration
+		suites.Suites = append(suites.Suites, suite)
+	}
+	suites.Time = junitTime(total)
+	return suites
+}
+
+func junitTime(seconds float64) string {
+	return fmt.Sprintf("%.3f", seconds)
+}
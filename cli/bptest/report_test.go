@@ -0,0 +1,252 @@
+package bptest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// goTestJSON returns go test -json output lines for the given test, with one event per second.
+func goTestJSON(test string, events ...[2]string) []string {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var lines []string
+	for i, e := range events {
+		ev := map[string]interface{}{
+			"Time":    start.Add(time.Duration(i) * time.Second),
+			"Action":  e[0],
+			"Package": "example.com/test/integration",
+			"Test":    test,
+		}
+		switch e[0] {
+		case "output":
+			ev["Output"] = e[1] + "\n"
+		case "pass", "fail", "skip":
+			ev["Elapsed"] = float64(i)
+		}
+		b, _ := json.Marshal(ev)
+		lines = append(lines, string(b))
+	}
+	return lines
+}
+
+func TestTestEventCollector(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  []*testCaseReport
+	}{
+		{
+			name: "passing stages",
+			lines: goTestJSON("TestFoo",
+				[2]string{"run", ""},
+				[2]string{"output", "    stages.go:30: Running stage init"},
+				[2]string{"output", "    stages.go:30: Skipping stage plan"},
+				[2]string{"output", "    stages.go:30: Running stage apply"},
+				[2]string{"output", "    stages.go:30: Running stage verify"},
+				[2]string{"output", "--- PASS: TestFoo (4.00s)"},
+				[2]string{"pass", ""},
+			),
+			want: []*testCaseReport{{
+				Name:     "TestFoo",
+				Status:   testStatusPass,
+				Duration: 6,
+				Stages: []*stageReport{
+					{Name: "init", Status: testStatusPass, Duration: 1},
+					{Name: "plan", Status: testStatusSkip},
+					{Name: "apply", Status: testStatusPass, Duration: 1},
+					{Name: "verify", Status: testStatusPass, Duration: 2},
+				},
+			}},
+		},
+		{
+			name: "assertion failure",
+			lines: goTestJSON("TestFoo",
+				[2]string{"run", ""},
+				[2]string{"output", "    stages.go:30: Running stage apply"},
+				[2]string{"output", "    cmd.go:12: Running command terraform"},
+				[2]string{"output", "    stages.go:30: Running stage verify"},
+				[2]string{"output", "    foo_test.go:20: "},
+				[2]string{"output", "        \tError Trace:\tfoo_test.go:20"},
+				[2]string{"output", "        \tError:      \tNot equal"},
+				[2]string{"output", "    stages.go:30: Running stage teardown"},
+				[2]string{"output", "--- FAIL: TestFoo (8.00s)"},
+				[2]string{"fail", ""},
+			),
+			want: []*testCaseReport{{
+				Name:     "TestFoo",
+				Status:   testStatusFail,
+				Duration: 9,
+				Failure:  "    foo_test.go:20: \n        \tError Trace:\tfoo_test.go:20\n        \tError:      \tNot equal",
+				Stages: []*stageReport{
+					{Name: "apply", Status: testStatusPass, Duration: 2},
+					{Name: "verify", Status: testStatusFail, Duration: 4, Failure: "    foo_test.go:20: \n        \tError Trace:\tfoo_test.go:20\n        \tError:      \tNot equal"},
+					{Name: "teardown", Status: testStatusPass, Duration: 2},
+				},
+			}},
+		},
+		{
+			name: "failure without assertion",
+			lines: goTestJSON("TestFoo",
+				[2]string{"run", ""},
+				[2]string{"output", "    stages.go:30: Running stage init"},
+				[2]string{"output", "panic: boom"},
+				[2]string{"fail", ""},
+			),
+			want: []*testCaseReport{{
+				Name:     "TestFoo",
+				Status:   testStatusFail,
+				Duration: 3,
+				Failure:  "    stages.go:30: Running stage init\npanic: boom",
+				Stages: []*stageReport{
+					{Name: "init", Status: testStatusFail, Duration: 2, Failure: "    stages.go:30: Running stage init\npanic: boom"},
+				},
+			}},
+		},
+		{
+			name: "only leaf tests",
+			lines: append(append(
+				goTestJSON("TestAll", [2]string{"run", ""}),
+				goTestJSON("TestAll/examples/foo", [2]string{"run", ""}, [2]string{"pass", ""})...),
+				goTestJSON("TestAll", [2]string{"pass", ""})...),
+			want: []*testCaseReport{{
+				Name:     "TestAll/examples/foo",
+				Status:   testStatusPass,
+				Duration: 1,
+				Stages:   []*stageReport{},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			c := newTestEventCollector()
+			for _, line := range tt.lines {
+				c.processLine(line)
+			}
+			got := c.report().Tests
+			assert.Len(got, len(tt.want))
+			for i, want := range tt.want {
+				if i >= len(got) {
+					break
+				}
+				assert.Equal(want.Name, got[i].Name)
+				assert.Equal(want.Status, got[i].Status)
+				assert.Equal(want.Duration, got[i].Duration)
+				assert.Equal(want.Failure, got[i].Failure)
+				assert.Len(got[i].Stages, len(want.Stages))
+				for j, s := range want.Stages {
+					if j >= len(got[i].Stages) {
+						break
+					}
+					assert.Equal(s.Name, got[i].Stages[j].Name)
+					assert.Equal(s.Status, got[i].Stages[j].Status)
+					assert.Equal(s.Duration, got[i].Stages[j].Duration)
+					assert.Equal(s.Failure, got[i].Stages[j].Failure)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessLine(t *testing.T) {
+	c := newTestEventCollector()
+	out, ok := c.processLine("not json")
+	assert.True(t, ok)
+	assert.Equal(t, "not json", out)
+
+	lines := goTestJSON("TestFoo", [2]string{"run", ""}, [2]string{"output", "hello"}, [2]string{"pass", ""})
+	var printed []string
+	for _, line := range lines {
+		if out, ok := c.processLine(line); ok {
+			printed = append(printed, out)
+		}
+	}
+	assert.Equal(t, []string{"hello"}, printed)
+}
+
+func TestTestReportWrite(t *testing.T) {
+	report := &testReport{Tests: []*testCaseReport{
+		{
+			Name:     "TestFoo",
+			Package:  "example.com/test/integration",
+			Status:   testStatusFail,
+			Duration: 3,
+			Failure:  "foo_test.go:20: Not equal",
+			Stages: []*stageReport{
+				{Name: "init", Status: testStatusPass, Duration: 1},
+				{Name: "plan", Status: testStatusSkip},
+				{Name: "verify", Status: testStatusFail, Duration: 2, Failure: "foo_test.go:20: Not equal"},
+			},
+		},
+		{
+			Name:     "TestBar",
+			Package:  "example.com/test/integration",
+			Status:   testStatusPass,
+			Duration: 1,
+			Stages:   []*stageReport{},
+		},
+	}}
+
+	tests := []struct {
+		name   string
+		format string
+		want   []string
+		errMsg string
+	}{
+		{
+			name:   "junit",
+			format: reportFormatJUnit,
+			want: []string{
+				`<?xml version="1.0" encoding="UTF-8"?>`,
+				`<testsuites tests="4" failures="1" skipped="1" time="4.000">`,
+				`<testsuite name="TestFoo" package="example.com/test/integration" tests="3" failures="1" skipped="1" time="3.000">`,
+				`<testcase name="init" classname="TestFoo" time="1.000"></testcase>`,
+				`<testcase name="plan" classname="TestFoo" time="0.000">`,
+				`<skipped></skipped>`,
+				`<failure message="stage verify failed">foo_test.go:20: Not equal</failure>`,
+				`<testcase name="TestBar" classname="TestBar" time="1.000"></testcase>`,
+			},
+		},
+		{
+			name:   "json",
+			format: reportFormatJSON,
+			want: []string{
+				`"name": "TestFoo"`,
+				`"status": "FAIL"`,
+				`"durationSeconds": 3`,
+				`"failure": "foo_test.go:20: Not equal"`,
+				`"stages": []`,
+			},
+		},
+		{
+			name:   "invalid",
+			format: "xml",
+			errMsg: "invalid report format xml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			var buff bytes.Buffer
+			err := report.write(&buff, tt.format)
+			if tt.errMsg != "" {
+				assert.ErrorContains(err, tt.errMsg)
+				return
+			}
+			assert.NoError(err)
+			for _, w := range tt.want {
+				assert.Contains(buff.String(), w, fmt.Sprintf("missing %s in:\n%s", w, buff.String()))
+			}
+			if tt.format == reportFormatJSON {
+				var got testReport
+				assert.NoError(json.NewDecoder(strings.NewReader(buff.String())).Decode(&got))
+				assert.Len(got.Tests, 2)
+			}
+		})
+	}
+}
@@ -70,6 +70,11 @@ func validateAndGetRelativeTestPkg(intTestDir string, name string) (string, erro
 
 // streamExec runs a given cmd while streaming logs
 func streamExec(cmd *exec.Cmd) error {
+	return streamExecFn(cmd, func(line string) { fmt.Println(line) })
+}
+
+// streamExecFn runs a given cmd while passing each line of output to fn.
+func streamExecFn(cmd *exec.Cmd, fn func(string)) error {
 	op, err := cmd.StdoutPipe()
 	if err != nil {
 		return err
@@ -80,13 +85,12 @@ func streamExec(cmd *exec.Cmd) error {
 	// waitgroup to block while processing exec op
 	var wg sync.WaitGroup
 	wg.Add(1)
-	defer wg.Wait()
 	go func() {
 		defer wg.Done()
 		scanner := bufio.NewScanner(op)
 		scanner.Buffer(make([]byte, startBufSize), maxScanTokenSize)
 		for scanner.Scan() {
-			fmt.Println(scanner.Text())
+			fn(scanner.Text())
 		}
 		if err := scanner.Err(); err != nil {
 			Log.Error(fmt.Sprintf("error reading output: %s", err))
@@ -94,14 +98,20 @@ func streamExec(cmd *exec.Cmd) error {
 	}()
 
 	// run command
-	if err := cmd.Run(); err != nil {
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("error running command: %w", err)
+	}
+	wg.Wait()
+	if err := cmd.Wait(); err != nil {
 		return fmt.Errorf("error running command: %w", err)
 	}
 	return nil
 }
 
 // getTestCmd returns a prepared cmd for running the specified tests(s)
-func getTestCmd(intTestDir string, testStage string, testName string, relTestPkg string, setupVars map[string]string) (*exec.Cmd, error) {
+//
+// If jsonOutput is set, go test is used to produce machine readable output.
+func getTestCmd(intTestDir string, testStage string, testName string, relTestPkg string, setupVars map[string]string, jsonOutput bool) (*exec.Cmd, error) {
 
 	// pass all current env vars to test command
 	env := os.Environ()
@@ -120,7 +130,10 @@ func getTestCmd(intTestDir string, testStage string, testName string, relTestPkg
 		testArgs = append([]string{relTestPkg, "-run", testName}, allTestArgs...)
 	}
 	cmdBin := goBin
-	if utils.BinaryInPath(gotestBin) != nil {
+	if jsonOutput {
+		testArgs = append([]string{"test"}, testArgs...)
+		testArgs = append(testArgs, "-json")
+	} else if utils.BinaryInPath(gotestBin) != nil {
 		testArgs = append([]string{"test"}, testArgs...)
 	} else {
 		cmdBin = gotestBin
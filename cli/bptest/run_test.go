@@ -69,6 +69,7 @@ func TestGetTestCmd(t *testing.T) {
 		testName   string
 		relTestPkg string
 		setupVars  map[string]string
+		jsonOutput bool
 		wantArgs   []string
 		wantEnv    []string
 		errMsg     string
@@ -99,6 +100,12 @@ func TestGetTestCmd(t *testing.T) {
 			wantArgs:  []string{"./...", "-run", "TestFoo", "-p", "1", "-count", "1", "-timeout", "0"},
 			wantEnv:   []string{"RUN_STAGE=verify", "CFT_SETUP_my-key=my-value"},
 		},
+		{
+			name:       "json output",
+			testName:   "TestFoo",
+			jsonOutput: true,
+			wantArgs:   []string{"go", "test", "./...", "-run", "TestFoo", "-p", "1", "-count", "1", "-timeout", "0", "-json"},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -109,7 +116,7 @@ func TestGetTestCmd(t *testing.T) {
 			if tt.relTestPkg == "" {
 				tt.relTestPkg = "./..."
 			}
-			gotCmd, err := getTestCmd(tt.intTestDir, tt.testStage, tt.testName, tt.relTestPkg, tt.setupVars)
+			gotCmd, err := getTestCmd(tt.intTestDir, tt.testStage, tt.testName, tt.relTestPkg, tt.setupVars, tt.jsonOutput)
 			if tt.errMsg != "" {
 				assert.NotNil(err)
 				assert.Contains(err.Error(), tt.errMsg)
@@ -2,14 +2,18 @@ package bptest
 
 import "fmt"
 
-var stages = []string{"init", "plan", "apply", "verify", "teardown"}
+// stages are the blueprint test stages in the order run by the test framework.
+// guardrail and upgrade run after init and are no-ops unless enabled by the test.
+var stages = []string{"init", "guardrail", "upgrade", "plan", "apply", "verify", "teardown"}
 
 var stagesWithAlias = map[string][]string{
-	stages[0]: {"create"},
-	stages[1]: {},
-	stages[2]: {"converge"},
-	stages[3]: {},
-	stages[4]: {"destroy"},
+	"init":      {"create"},
+	"guardrail": {},
+	"upgrade":   {},
+	"plan":      {},
+	"apply":     {"converge"},
+	"verify":    {},
+	"teardown":  {"destroy"},
 }
 
 // validateAndGetStage validates given stage and resolves to stage name if an alias is provided
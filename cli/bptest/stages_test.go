@@ -29,6 +29,16 @@ func TestValidateAndGetStage(t *testing.T) {
 			stage: "verify",
 			want:  "verify",
 		},
+		{
+			name:  "guardrail",
+			stage: "guardrail",
+			want:  "guardrail",
+		},
+		{
+			name:  "upgrade",
+			stage: "upgrade",
+			want:  "upgrade",
+		},
 		{
 			name:   "invalid name",
 			stage:  "foo",
@@ -0,0 +1,266 @@
+package bptest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// stateDir is the directory in the integration test dir containing persisted test state.
+	stateDir      = ".bptest"
+	stateFilename = "state.json"
+	// testStateFilename is the state written by the test itself.
+	testStateFilename = "test.json"
+	// This must be kept in sync with what github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/tft parses.
+	stateFileEnvVar = "BPTEST_STATE_FILE"
+	teardownStage   = "teardown"
+)
+
+// runState is the persisted state of a test run one stage at a time.
+type runState struct {
+	Test string `json:"test"`
+	// CompletedStages are stages whose effects are still in place.
+	CompletedStages []string `json:"completedStages"`
+	FailedStage     string   `json:"failedStage,omitempty"`
+	// TFDir, Workspace and SetupOutputs are reported by the test.
+	TFDir        string            `json:"tfDir,omitempty"`
+	Workspace    string            `json:"workspace,omitempty"`
+	SetupOutputs map[string]string `json:"setupOutputs,omitempty"`
+	UpdatedAt    time.Time         `json:"updatedAt"`
+
+	dir string // dir is the directory the state is persisted in.
+}
+
+// testStateDir returns the state directory for test in intTestDir.
+func testStateDir(intTestDir, test string) string {
+	return filepath.Join(intTestDir, stateDir, strings.ReplaceAll(test, "/", "_"))
+}
+
+// newRunState returns an empty state for test persisted in dir.
+func newRunState(dir, test string) *runState {
+	return &runState{Test: test, CompletedStages: []string{}, dir: dir}
+}
+
+// loadRunState reads the state persisted in dir.
+func loadRunState(dir string) (*runState, error) {
+	b, err := os.ReadFile(filepath.Join(dir, stateFilename))
+	if err != nil {
+		return nil, err
+	}
+	s := &runState{dir: dir}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("error parsing state %s: %w", filepath.Join(dir, stateFilename), err)
+	}
+	return s, nil
+}
+
+// save persists the state including state reported by the test.
+func (s *runState) save() error {
+	if err := s.loadTestState(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("error creating state dir: %w", err)
+	}
+	s.UpdatedAt = time.Now()
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.dir, stateFilename), b, 0644)
+}
+
+// remove deletes the persisted state.
+func (s *runState) remove() error {
+	return os.RemoveAll(s.dir)
+}
+
+// testStateFile returns the path the test writes its state to.
+func (s *runState) testStateFile() string {
+	return filepath.Join(s.dir, testStateFilename)
+}
+
+// loadTestState merges state written by the test, if any.
+func (s *runState) loadTestState() error {
+	b, err := os.ReadFile(s.testStateFile())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	ts := struct {
+		TFDir        string            `json:"tfDir"`
+		Workspace    string            `json:"workspace"`
+		SetupOutputs map[string]string `json:"setupOutputs"`
+	}{}
+	if err := json.Unmarshal(b, &ts); err != nil {
+		return fmt.Errorf("error parsing test state %s: %w", s.testStateFile(), err)
+	}
+	s.TFDir, s.Workspace, s.SetupOutputs = ts.TFDir, ts.Workspace, ts.SetupOutputs
+	return nil
+}
+
+// resumeStages returns stages to run to resume from the first stage not completed.
+func (s *runState) resumeStages() []string {
+	for i, stage := range stages {
+		if !slices.Contains(s.CompletedStages, stage) {
+			return stages[i:]
+		}
+	}
+	return []string{}
+}
+
+// stageSetupVars returns setup outputs persisted in state overridden by setupVars.
+func (s *runState) stageSetupVars(setupVars map[string]string) map[string]string {
+	vars := make(map[string]string)
+	for k, v := range s.SetupOutputs {
+		vars[k] = v
+	}
+	for k, v := range setupVars {
+		vars[k] = v
+	}
+	return vars
+}
+
+// stageRunner runs a single stage of a test with the given setup vars.
+type stageRunner func(stage string, setupVars map[string]string) error
+
+// runStages runs stagesToRun in order, persisting state after each stage.
+//
+// If a stage fails, remaining stages are skipped and teardown is run unless keep is set
+// or teardown is not part of stagesToRun. The state is removed once teardown succeeds.
+func runStages(s *runState, stagesToRun []string, setupVars map[string]string, keep bool, run stageRunner) error {
+	runStage := func(stage string) error {
+		Log.Info(fmt.Sprintf("running stage %s of %s", stage, s.Test))
+		err := run(stage, s.stageSetupVars(setupVars))
+		if err != nil {
+			s.FailedStage = stage
+		} else {
+			s.CompletedStages = append(s.CompletedStages, stage)
+			if s.FailedStage == stage {
+				s.FailedStage = ""
+			}
+		}
+		if serr := s.save(); serr != nil {
+			return errors.Join(err, serr)
+		}
+		return err
+	}
+	teardown := func() error {
+		if err := runStage(teardownStage); err != nil {
+			return err
+		}
+		return s.remove()
+	}
+
+	for _, stage := range stagesToRun {
+		if stage != teardownStage {
+			if err := runStage(stage); err != nil {
+				if !keep && slices.Contains(stagesToRun, teardownStage) {
+					if terr := teardown(); terr != nil {
+						err = errors.Join(err, terr)
+					}
+				} else {
+					Log.Info(fmt.Sprintf("keeping resources, resume with --resume or clean up with --stage %s --from-state", teardownStage))
+				}
+				return fmt.Errorf("stage %s of %s failed: %w", stage, s.Test, err)
+			}
+			continue
+		}
+		if keep {
+			Log.Info(fmt.Sprintf("skipping stage %s, clean up with --stage %s --from-state", teardownStage, teardownStage))
+			continue
+		}
+		if err := teardown(); err != nil {
+			return fmt.Errorf("stage %s of %s failed: %w", teardownStage, s.Test, err)
+		}
+	}
+	return nil
+}
+
+// validateStateFlags validates flags for running a test with persisted state.
+func validateStateFlags(testStage string, resume, keep, fromState bool, parallel int, withReport bool) error {
+	switch {
+	case parallel > 1 || withReport:
+		return fmt.Errorf("--resume, --keep and --from-state can not be used with --parallel or --report")
+	case fromState && testStage == "":
+		return fmt.Errorf("--from-state requires --stage")
+	case fromState && (resume || keep):
+		return fmt.Errorf("--from-state can not be used with --resume or --keep")
+	case !fromState && testStage != "":
+		return fmt.Errorf("--resume and --keep run all stages and can not be used with --stage")
+	}
+	return nil
+}
+
+// runWithState runs the test name one stage at a time persisting state under intTestDir.
+//
+// If resume is set, stages continue from the first stage not completed in a previous run.
+// If fromState is set, only testStage is run using the persisted setup outputs.
+func runWithState(intTestDir, testStage, name string, labels []string, setupVars map[string]string, resume, keep, fromState bool) error {
+	selected, _, err := selectTests(intTestDir, name, labels)
+	if err != nil {
+		return err
+	}
+	if len(selected) != 1 {
+		return fmt.Errorf("running with state requires a single test, %d tests match %s", len(selected), name)
+	}
+	test := selected[0]
+	dir := testStateDir(intTestDir, test.name)
+
+	var s *runState
+	stagesToRun := stages
+	switch {
+	case resume || fromState:
+		s, err = loadRunState(dir)
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("no state found for %s in %s", test.name, dir)
+		}
+		if err != nil {
+			return err
+		}
+		if fromState {
+			stagesToRun = []string{testStage}
+		} else {
+			stagesToRun = s.resumeStages()
+			if len(stagesToRun) == 0 {
+				return fmt.Errorf("all stages of %s completed, nothing to resume", test.name)
+			}
+			Log.Info(fmt.Sprintf("resuming %s from stage %s", test.name, stagesToRun[0]))
+		}
+	default:
+		s = newRunState(dir, test.name)
+		// state from the test is stale until the test writes it again
+		if err := s.remove(); err != nil {
+			return err
+		}
+	}
+
+	relPkg, err := filepath.Rel(intTestDir, path.Dir(test.location))
+	if err != nil {
+		return err
+	}
+	stateFile, err := filepath.Abs(s.testStateFile())
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating state dir: %w", err)
+	}
+	return runStages(s, stagesToRun, setupVars, keep, func(stage string, vars map[string]string) error {
+		cmd, err := getTestCmd(intTestDir, stage, exactRunPattern(test.name), fmt.Sprintf("./%s", relPkg), vars, false)
+		if err != nil {
+			return err
+		}
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", stateFileEnvVar, stateFile))
+		return streamExec(cmd)
+	})
+}
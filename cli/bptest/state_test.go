@@ -0,0 +1,183 @@
+package bptest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTestStateDir(t *testing.T) {
+	assert.Equal(t, filepath.Join("int", ".bptest", "TestAll_examples_foo"), testStateDir("int", "TestAll/examples/foo"))
+}
+
+func TestResumeStages(t *testing.T) {
+	tests := []struct {
+		name      string
+		completed []string
+		want      []string
+	}{
+		{name: "none completed", want: stages},
+		{name: "failed upgrade", completed: []string{"init", "guardrail"}, want: []string{"upgrade", "plan", "apply", "verify", "teardown"}},
+		{name: "failed verify", completed: []string{"init", "guardrail", "upgrade", "plan", "apply"}, want: []string{"verify", "teardown"}},
+		{name: "kept after success", completed: []string{"init", "guardrail", "upgrade", "plan", "apply", "verify"}, want: []string{"teardown"}},
+		{name: "all completed", completed: stages, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &runState{CompletedStages: tt.completed}
+			assert.Equal(t, tt.want, s.resumeStages())
+		})
+	}
+}
+
+func TestRunStages(t *testing.T) {
+	tests := []struct {
+		name          string
+		completed     []string
+		stagesToRun   []string
+		keep          bool
+		failStage     string
+		wantRun       []string
+		wantCompleted []string
+		wantFailed    string
+		wantRemoved   bool
+		errMsg        string
+	}{
+		{
+			name:        "all stages",
+			stagesToRun: stages,
+			wantRun:     stages,
+			wantRemoved: true,
+		},
+		{
+			name:          "keep",
+			stagesToRun:   stages,
+			keep:          true,
+			wantRun:       []string{"init", "guardrail", "upgrade", "plan", "apply", "verify"},
+			wantCompleted: []string{"init", "guardrail", "upgrade", "plan", "apply", "verify"},
+		},
+		{
+			name:        "failure runs teardown",
+			stagesToRun: stages,
+			failStage:   "apply",
+			wantRun:     []string{"init", "guardrail", "upgrade", "plan", "apply", "teardown"},
+			wantRemoved: true,
+			errMsg:      "stage apply of TestFoo failed: failed",
+		},
+		{
+			name:          "failure with keep",
+			stagesToRun:   stages,
+			keep:          true,
+			failStage:     "verify",
+			wantRun:       []string{"init", "guardrail", "upgrade", "plan", "apply", "verify"},
+			wantCompleted: []string{"init", "guardrail", "upgrade", "plan", "apply"},
+			wantFailed:    "verify",
+			errMsg:        "stage verify of TestFoo failed: failed",
+		},
+		{
+			name:          "failed teardown",
+			stagesToRun:   stages,
+			failStage:     "teardown",
+			wantRun:       stages,
+			wantCompleted: []string{"init", "guardrail", "upgrade", "plan", "apply", "verify"},
+			wantFailed:    "teardown",
+			errMsg:        "stage teardown of TestFoo failed: failed",
+		},
+		{
+			name:        "resume",
+			completed:   []string{"init", "plan", "apply"},
+			stagesToRun: []string{"verify", "teardown"},
+			wantRun:     []string{"verify", "teardown"},
+			wantRemoved: true,
+		},
+		{
+			name:          "single stage failure keeps state",
+			completed:     []string{"init", "plan", "apply"},
+			stagesToRun:   []string{"verify"},
+			failStage:     "verify",
+			wantRun:       []string{"verify"},
+			wantCompleted: []string{"init", "plan", "apply"},
+			wantFailed:    "verify",
+			errMsg:        "stage verify of TestFoo failed: failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			dir := filepath.Join(t.TempDir(), "TestFoo")
+			assert.NoError(os.MkdirAll(dir, 0755))
+			s := newRunState(dir, "TestFoo")
+			s.SetupOutputs = map[string]string{"setup": "output"}
+			if tt.completed != nil {
+				s.CompletedStages = tt.completed
+			}
+			var gotRun []string
+			err := runStages(s, tt.stagesToRun, map[string]string{"user": "value"}, tt.keep, func(stage string, vars map[string]string) error {
+				gotRun = append(gotRun, stage)
+				assert.Equal(map[string]string{"user": "value", "setup": "output"}, vars)
+				// the test reports its state on every stage
+				if err := os.WriteFile(s.testStateFile(), []byte(`{"tfDir": "/tf", "workspace": "default", "setupOutputs": {"setup": "output"}}`), 0644); err != nil {
+					return err
+				}
+				if stage == tt.failStage {
+					return fmt.Errorf("failed")
+				}
+				return nil
+			})
+			if tt.errMsg != "" {
+				assert.EqualError(err, tt.errMsg)
+			} else {
+				assert.NoError(err)
+			}
+			assert.Equal(tt.wantRun, gotRun)
+
+			got, err := loadRunState(dir)
+			if tt.wantRemoved {
+				assert.ErrorIs(err, os.ErrNotExist)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal("TestFoo", got.Test)
+			assert.Equal(tt.wantCompleted, got.CompletedStages)
+			assert.Equal(tt.wantFailed, got.FailedStage)
+			assert.Equal("/tf", got.TFDir)
+			assert.Equal("default", got.Workspace)
+			assert.Equal(map[string]string{"setup": "output"}, got.SetupOutputs)
+		})
+	}
+}
+
+func TestValidateStateFlags(t *testing.T) {
+	tests := []struct {
+		name      string
+		testStage string
+		resume    bool
+		keep      bool
+		fromState bool
+		parallel  int
+		report    bool
+		errMsg    string
+	}{
+		{name: "resume", resume: true},
+		{name: "resume and keep", resume: true, keep: true},
+		{name: "teardown from state", testStage: "teardown", fromState: true},
+		{name: "from state without stage", fromState: true, errMsg: "--from-state requires --stage"},
+		{name: "from state with keep", testStage: "teardown", fromState: true, keep: true, errMsg: "--from-state can not be used with --resume or --keep"},
+		{name: "keep with stage", testStage: "apply", keep: true, errMsg: "can not be used with --stage"},
+		{name: "parallel", keep: true, parallel: 2, errMsg: "can not be used with --parallel or --report"},
+		{name: "report", resume: true, report: true, errMsg: "can not be used with --parallel or --report"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateStateFlags(tt.testStage, tt.resume, tt.keep, tt.fromState, tt.parallel, tt.report)
+			if tt.errMsg != "" {
+				assert.ErrorContains(t, err, tt.errMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
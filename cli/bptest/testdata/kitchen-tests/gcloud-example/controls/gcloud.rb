@@ -0,0 +1,76 @@
+# Copyright 2021 Google LLC
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+project_id = attribute('project_id')
+cluster_name = attribute('cluster_name')
+
+control "gcloud" do
+  title "GKE cluster configuration"
+
+  describe command("gcloud --project=#{project_id} container clusters describe #{cluster_name} --zone=us-central1-a --format=json") do
+    its(:exit_status) { should eq 0 }
+    its(:stderr) { should eq '' }
+
+    let!(:data) do
+      if subject.exit_status == 0
+        JSON.parse(subject.stdout)
+      else
+        {}
+      end
+    end
+
+    describe "cluster" do
+      it "is running" do
+        expect(data['status']).to eq 'RUNNING'
+      end
+
+      it "has the expected name" do
+        expect(data['name']).to eq cluster_name
+      end
+
+      it "has the expected addon settings" do
+        expect(data['addonsConfig']).to include(
+          "networkPolicyConfig" => {
+            "disabled" => true,
+          },
+        )
+      end
+
+      it "has the expected node count" do
+        expect(data['currentNodeCount']).to eq 3
+        expect(data['legacyAbac']['enabled']).to eq false
+      end
+    end
+  end
+
+  describe command("gcloud --project=#{project_id} container node-pools list --cluster #{cluster_name} --zone=us-central1-a --format=json") do
+    its(:exit_status) { should eq 0 }
+
+    let(:data) do
+      JSON.parse(subject.stdout)
+    end
+
+    it { expect(data.length).to eq 2 }
+    it { expect(data[0]['config']).to include('machineType' => 'n1-standard-2', 'diskType' => 'pd-standard') }
+    it { expect(data[0]['name']).to include "pool" }
+  end
+
+  describe command("gcloud services list --project #{project_id}") do
+    its(:stdout) { should match(/container/) }
+  end
+
+  describe google_compute_network(project: project_id, name: "default") do
+    it { should exist }
+  end
+end
@@ -0,0 +1,9 @@
+output "network_name" {
+  description = "The name of the network"
+  value       = var.network_name
+}
+
+output "subnets" {
+  description = "The created subnets"
+  value       = var.subnets
+}
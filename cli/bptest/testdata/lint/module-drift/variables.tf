@@ -0,0 +1,24 @@
+variable "project_id" {
+  description = "The project ID to deploy to"
+  type        = string
+}
+
+variable "network_name" {
+  description = "The name of the network"
+  type        = string
+  default     = "default"
+}
+
+variable "labels" {
+  description = "Labels to apply to resources"
+  type        = map(string)
+  default     = {}
+}
+
+variable "subnets" {
+  description = "Subnets to create"
+  type = list(object({
+    name   = string
+    region = string
+  }))
+}
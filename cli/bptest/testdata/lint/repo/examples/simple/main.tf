@@ -0,0 +1,5 @@
+module "repo" {
+  source = "../.."
+
+  project_id = "my-project"
+}
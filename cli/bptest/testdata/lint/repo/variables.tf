@@ -0,0 +1,4 @@
+variable "project_id" {
+  description = "The project ID to deploy to"
+  type        = string
+}
@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"errors"
+	"path/filepath"
+
 	"github.com/spf13/cobra"
 
 	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/cli/launchpad"
@@ -9,13 +12,26 @@ import (
 func init() {
 	launchpadGenerateCmd.Flags().StringVarP(&launchpadOutputFlavor, "flavor", "f", "tf", "Output Flavor: [tf]/dm")
 	launchpadGenerateCmd.Flags().StringVarP(&launchpadOutputDirectory, "directory", "d", "config", "Output Directory: [config]")
+	launchpadGenerateCmd.Flags().BoolVar(&launchpadStrict, "strict", false, "Report all YAML errors and fail instead of skipping invalid documents")
+	launchpadImportCmd.Flags().StringVar(&launchpadImportDirPath, "dir-path", "", "Local directory path containing Cloud Asset Inventory export")
+	launchpadImportCmd.Flags().StringVarP(&launchpadImportOutput, "output", "o", "", "Output YAML file, output to console if not specified")
+	_ = launchpadImportCmd.MarkFlagRequired("dir-path")
+	initLaunchpadDiffFlags()
 
 	rootCmd.AddCommand(launchpadCmd)
 	launchpadCmd.AddCommand(launchpadGenerateCmd)
+	launchpadCmd.AddCommand(launchpadImportCmd)
+	launchpadCmd.AddCommand(launchpadDiffCmd)
 }
 
 var launchpadOutputFlavor string
 var launchpadOutputDirectory string
+var launchpadStrict bool
+var launchpadImportDirPath string
+var launchpadImportOutput string
+var launchpadDiffFormat string
+var launchpadDiffOld []string
+var launchpadDiffNew []string
 var launchpadCmd = &cobra.Command{
 	Use:     "launchpad",
 	Aliases: []string{"lp"},
@@ -41,7 +57,89 @@ var launchpadGenerateCmd = &cobra.Command{
 		if len(args) == 0 {
 			cmd.HelpFunc()(cmd, args)
 		} else {
-			launchpad.NewGenerate(args, launchpad.NewOutputFlavor(launchpadOutputFlavor), launchpadOutputDirectory)
+			launchpad.NewGenerate(args, launchpad.NewOutputFlavor(launchpadOutputFlavor), launchpadOutputDirectory, launchpadStrict)
 		}
 	},
 }
+
+var launchpadImportCmd = &cobra.Command{
+	Use:     "import",
+	Aliases: []string{"i"},
+	Short:   "import (i)",
+	Long:    `Import an existing organization hierarchy from Cloud Asset Inventory export into YAML`,
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		launchpad.NewImport(launchpadImportDirPath, launchpadImportOutput)
+	},
+}
+
+var launchpadDiffCmd = &cobra.Command{
+	Use:     "diff [old YAML directory or pattern] [new YAML directory or pattern] | [old YAML files] [--] [new YAML files]",
+	Aliases: []string{"d"},
+	Short:   "diff (d)",
+	Long: `Compare the resolved organization hierarchy, projects, networks and IAM policies between two sets of YAML.
+	Each set is either a directory or a path pattern, a list of files with the old and new files
+	separated by --, or given by the repeatable --old and --new flags. Unquoted patterns expanded by
+	the shell are split into old and new files by their directory, which must differ`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		_, _, err := launchpadDiffPaths(cmd, args)
+		return err
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		oldPaths, newPaths, _ := launchpadDiffPaths(cmd, args)
+		launchpad.NewDiff(cmd.OutOrStdout(), oldPaths, newPaths, launchpadDiffFormat)
+	},
+}
+
+func initLaunchpadDiffFlags() {
+	launchpadDiffCmd.Flags().StringVar(&launchpadDiffFormat, "format", "text", "Output Format: [text]/json")
+	launchpadDiffCmd.Flags().StringArrayVar(&launchpadDiffOld, "old", nil, "Old YAML file, directory or pattern, can be repeated")
+	launchpadDiffCmd.Flags().StringArrayVar(&launchpadDiffNew, "new", nil, "New YAML file, directory or pattern, can be repeated")
+}
+
+// launchpadDiffPaths returns the old and new paths to diff from the --old and --new flags,
+// the args before and after a -- separator, exactly two args or args in two parent directories.
+//
+// Unquoted patterns are expanded by the shell into files, these are split into old and new
+// files by their parent directory.
+func launchpadDiffPaths(cmd *cobra.Command, args []string) ([]string, []string, error) {
+	if len(launchpadDiffOld) > 0 || len(launchpadDiffNew) > 0 {
+		if len(launchpadDiffOld) == 0 || len(launchpadDiffNew) == 0 {
+			return nil, nil, errors.New("both --old and --new are required")
+		}
+		if len(args) > 0 {
+			return nil, nil, errors.New("args are not allowed with --old and --new")
+		}
+		return launchpadDiffOld, launchpadDiffNew, nil
+	}
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		if dash == 0 || dash == len(args) {
+			return nil, nil, errors.New("old and new YAML files are required on both sides of --")
+		}
+		return args[:dash], args[dash:], nil
+	}
+	if len(args) == 2 {
+		return args[:1], args[1:], nil
+	}
+	if split := launchpadDiffSplitByDir(args); split > 0 {
+		return args[:split], args[split:], nil
+	}
+	return nil, nil, errors.New("expected an old and a new YAML directory, quoted pattern or files in two directories, separate other lists of files with -- or use --old and --new")
+}
+
+// launchpadDiffSplitByDir returns the index of the first arg in a second parent directory
+// if args are files in exactly two directories with all files of the first directory first.
+// It returns 0 if args can not be split.
+func launchpadDiffSplitByDir(args []string) int {
+	split := 0
+	for i := 1; i < len(args); i++ {
+		if filepath.Dir(args[i]) == filepath.Dir(args[i-1]) {
+			continue
+		}
+		if split > 0 {
+			return 0
+		}
+		split = i
+	}
+	return split
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const launchpadDiffTestDataDir = "../testdata/launchpad/diff"
+
+func TestLaunchpadDiffCommand(t *testing.T) {
+	oldDir := filepath.Join(launchpadDiffTestDataDir, "old")
+	newDir := filepath.Join(launchpadDiffTestDataDir, "new")
+	files := func(dir string) []string {
+		return []string{filepath.Join(dir, "folder.yaml"), filepath.Join(dir, "org.yaml"), filepath.Join(dir, "project.yaml")}
+	}
+
+	var testCases = []struct {
+		name   string
+		args   []string
+		errMsg string
+	}{
+		{"directories", []string{oldDir, newDir}, ""},
+		{"patterns", []string{filepath.Join(oldDir, "*.yaml"), filepath.Join(newDir, "*.yaml")}, ""},
+		{"expanded_files", append(append(files(oldDir), "--"), files(newDir)...), ""},
+		{"flags", []string{"--old", files(oldDir)[0], "--old", filepath.Join(oldDir, "[op]*.yaml"), "--new", newDir}, ""},
+		{"expanded_files_without_dash", append(files(oldDir), files(newDir)...), ""},
+		{"expanded_files_in_one_dir", append(files(oldDir), files(oldDir)[0]), "expected an old and a new YAML directory"},
+		{"expanded_files_in_three_dirs", append(append(files(oldDir), files(newDir)...), "other.yaml"), "expected an old and a new YAML directory"},
+		{"expanded_files_interleaved", append(append(files(oldDir), files(newDir)...), files(oldDir)[0]), "expected an old and a new YAML directory"},
+		{"no_new_files", append(files(oldDir), "--"), "required on both sides of --"},
+		{"old_flag_only", []string{"--old", oldDir}, "both --old and --new are required"},
+		{"flags_and_args", []string{"--old", oldDir, "--new", newDir, oldDir}, "args are not allowed with --old and --new"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			launchpadDiffCmd.ResetFlags()
+			initLaunchpadDiffFlags()
+			output, err := ExecuteCommand(rootCmd, append([]string{"launchpad", "diff"}, tc.args...)...)
+			if tc.errMsg != "" {
+				assert.ErrorContains(t, err, tc.errMsg)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, strings.HasPrefix(output, `~ Organization displayName: "example.com" -> "example.org"`), "unexpected diff:\n%s", output)
+			assert.Contains(t, output, "Folders: 1 added, 1 removed, 1 renamed, 1 re-parented.")
+			assert.Contains(t, output, "Projects, networks and IAM policies: 1 added, 1 removed, 3 changed.")
+		})
+	}
+}
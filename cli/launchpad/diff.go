@@ -0,0 +1,357 @@
+// Package launchpad file diff.go contains support for comparing two assembled
+// organizations to review changes of the resolved hierarchy, projects, networks
+// and IAM policies.
+package launchpad
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NewDiff assembles YAMLs from old and new paths and outputs the structural
+// difference of the resolved organizations into buff in the given format.
+//
+// Each path can either be a YAML file, a directory containing YAMLs or a quoted path pattern.
+//
+// NewDiff can be triggered by
+//
+//	$ cft launchpad diff old/ new/
+//	$ cft lp d "old/*.yaml" "new/*.yaml" --format json
+//	$ cft lp d old/*.yaml -- new/*.yaml
+//	$ cft lp d --old old/ --old "shared/*.yaml" --new new/ --new "shared/*.yaml"
+func NewDiff(buff io.Writer, oldPaths, newPaths []string, format string) {
+	oldOrg, err := assembleForDiff(oldPaths)
+	if err != nil {
+		log.Fatalf("Unable to assemble %s:\n%s", strings.Join(oldPaths, " "), err.Error())
+	}
+	newOrg, err := assembleForDiff(newPaths)
+	if err != nil {
+		log.Fatalf("Unable to assemble %s:\n%s", strings.Join(newPaths, " "), err.Error())
+	}
+	if err = diffOrgs(oldOrg, newOrg).write(buff, format); err != nil {
+		log.Fatalln("Unable to output diff:", err.Error())
+	}
+}
+
+// assembleForDiff loads and assembles YAMLs from files, directories or path patterns.
+//
+// assembleForDiff loads in strict mode as a partial organization would produce misleading diff.
+func assembleForDiff(paths []string) (*assembledOrg, error) {
+	patterns := make([]string, len(paths))
+	for i, p := range paths {
+		if info, err := os.Stat(p); err == nil && info.IsDir() {
+			p = filepath.Join(p, "*.yaml")
+		}
+		patterns[i] = p
+	}
+	resources, errs := collectResources(patterns, true)
+	ao, assembleErrs := assembleResources(resources)
+	if errs = append(errs, assembleErrs...); len(errs) > 0 {
+		return nil, errs
+	}
+	return ao, nil
+}
+
+// fieldChange represents a changed field value.
+type fieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// folderChange represents a folder that is added, removed, renamed or re-parented.
+//
+// Old values are only populated for renamed and re-parented folders.
+type folderChange struct {
+	Id             string `json:"id"`
+	DisplayName    string `json:"displayName"`
+	Parent         string `json:"parent"`
+	OldDisplayName string `json:"oldDisplayName,omitempty"`
+	OldParent      string `json:"oldParent,omitempty"`
+}
+
+// resourceChange represents a project, network or IAM policy that is added, removed or changed.
+//
+// Changes are only populated for changed resources.
+type resourceChange struct {
+	Kind    string        `json:"kind"`
+	Id      string        `json:"id"`
+	Changes []fieldChange `json:"changes,omitempty"`
+}
+
+// orgDiff is the structural difference between two assembled organizations.
+type orgDiff struct {
+	OrgChanges       []fieldChange     `json:"orgChanges"`
+	Added            []*folderChange   `json:"addedFolders"`
+	Removed          []*folderChange   `json:"removedFolders"`
+	Renamed          []*folderChange   `json:"renamedFolders"`
+	Reparented       []*folderChange   `json:"reparentedFolders"`
+	AddedResources   []*resourceChange `json:"addedResources"`
+	RemovedResources []*resourceChange `json:"removedResources"`
+	ChangedResources []*resourceChange `json:"changedResources"`
+}
+
+// empty returns true if there is no difference.
+func (d *orgDiff) empty() bool {
+	return len(d.OrgChanges)+len(d.Added)+len(d.Removed)+len(d.Renamed)+len(d.Reparented)+
+		len(d.AddedResources)+len(d.RemovedResources)+len(d.ChangedResources) == 0
+}
+
+// diffOrgs compares organization fields, folders, projects, networks and IAM policies
+// between two assembled organizations.
+//
+// Folders are identified by id, a folder with the same id but different display name is
+// renamed and a folder with the same id but different parent is re-parented.
+// Projects, networks and IAM policies are identified by kind and id and compared field by field.
+func diffOrgs(oldOrg, newOrg *assembledOrg) *orgDiff {
+	d := &orgDiff{
+		OrgChanges:       []fieldChange{},
+		Added:            []*folderChange{},
+		Removed:          []*folderChange{},
+		Renamed:          []*folderChange{},
+		Reparented:       []*folderChange{},
+		AddedResources:   []*resourceChange{},
+		RemovedResources: []*resourceChange{},
+		ChangedResources: []*resourceChange{},
+	}
+	for _, c := range []fieldChange{
+		{"id", oldOrg.org.Spec.Id, newOrg.org.Spec.Id},
+		{"displayName", oldOrg.org.Spec.DisplayName, newOrg.org.Spec.DisplayName},
+	} {
+		if c.Old != c.New {
+			d.OrgChanges = append(d.OrgChanges, c)
+		}
+	}
+
+	oldFolders, newFolders := oldOrg.folderIndex(), newOrg.folderIndex()
+	for _, id := range sortedKeys(newFolders) {
+		nf := newFolders[id]
+		of, found := oldFolders[id]
+		if !found {
+			d.Added = append(d.Added, newFolderChange(nf))
+			continue
+		}
+		if of.Spec.DisplayName != nf.Spec.DisplayName {
+			c := newFolderChange(nf)
+			c.OldDisplayName = of.Spec.DisplayName
+			d.Renamed = append(d.Renamed, c)
+		}
+		if of.Spec.ParentRef.resId() != nf.Spec.ParentRef.resId() {
+			c := newFolderChange(nf)
+			c.OldParent = of.Spec.ParentRef.resId()
+			d.Reparented = append(d.Reparented, c)
+		}
+	}
+	for _, id := range sortedKeys(oldFolders) {
+		if _, found := newFolders[id]; !found {
+			d.Removed = append(d.Removed, newFolderChange(oldFolders[id]))
+		}
+	}
+
+	oldResources, newResources := oldOrg.diffResourceIndex(), newOrg.diffResourceIndex()
+	for _, rId := range sortedKeys(newResources) {
+		nr := newResources[rId]
+		or, found := oldResources[rId]
+		if !found {
+			d.AddedResources = append(d.AddedResources, newResourceChange(nr))
+			continue
+		}
+		if changes := diffFields(resourceFields(or), resourceFields(nr)); len(changes) > 0 {
+			c := newResourceChange(nr)
+			c.Changes = changes
+			d.ChangedResources = append(d.ChangedResources, c)
+		}
+	}
+	for _, rId := range sortedKeys(oldResources) {
+		if _, found := newResources[rId]; !found {
+			d.RemovedResources = append(d.RemovedResources, newResourceChange(oldResources[rId]))
+		}
+	}
+	return d
+}
+
+func newFolderChange(f *folderYAML) *folderChange {
+	return &folderChange{Id: f.Spec.Id, DisplayName: f.Spec.DisplayName, Parent: f.Spec.ParentRef.resId()}
+}
+
+// folderIndex returns all folders in the assembled organization keyed by folder id.
+func (ao *assembledOrg) folderIndex() map[string]*folderYAML {
+	idx := make(map[string]*folderYAML)
+	for _, res := range ao.resourceMap {
+		if f, ok := res.yaml.(*folderYAML); ok {
+			idx[f.Spec.Id] = f
+		}
+	}
+	return idx
+}
+
+// diffResourceIndex returns all projects, networks and IAM policies in the assembled
+// organization keyed by resource id.
+func (ao *assembledOrg) diffResourceIndex() map[string]resourceHandler {
+	idx := make(map[string]resourceHandler)
+	for rId, res := range ao.resourceMap {
+		switch res.yaml.(type) {
+		case *projectYAML, *networkYAML, *iamPolicyYAML:
+			idx[rId] = res.yaml
+		}
+	}
+	return idx
+}
+
+func newResourceChange(r resourceHandler) *resourceChange {
+	kind, id, _ := strings.Cut(r.resId(), ".")
+	return &resourceChange{Kind: kind, Id: id}
+}
+
+// diffField is a named field value compared between resources.
+type diffField struct {
+	name  string
+	value string
+}
+
+// resourceFields returns the compared fields of a project, network or IAM policy.
+//
+// List values are sorted so that reordering alone is not reported as a change.
+func resourceFields(r resourceHandler) []diffField {
+	switch r := r.(type) {
+	case *projectYAML:
+		sharedNetwork := ""
+		if r.Spec.SharedNetworkRef != nil {
+			sharedNetwork = r.Spec.SharedNetworkRef.resId()
+		}
+		return []diffField{
+			{"displayName", r.Spec.DisplayName},
+			{"billingAccount", r.Spec.BillingAccount},
+			{"parent", r.Spec.ParentRef.resId()},
+			{"sharedNetwork", sharedNetwork},
+			{"services", sortedJoin(r.Spec.Services)},
+		}
+	case *networkYAML:
+		subnets := make([]string, 0, len(r.Spec.SubnetSpecs))
+		for _, s := range r.Spec.SubnetSpecs {
+			subnets = append(subnets, fmt.Sprintf("%s %s %s", s.Id, s.Region, s.IpCidrRange))
+		}
+		return []diffField{
+			{"project", r.Spec.ProjectRef.resId()},
+			{"shared", strconv.FormatBool(r.Spec.Shared)},
+			{"subnets", sortedJoin(subnets)},
+		}
+	case *iamPolicyYAML:
+		fields := []diffField{{"resource", r.Spec.ResourceRef.resId()}}
+		members := make(map[string][]string)
+		var roles []string
+		for _, b := range r.Spec.Bindings {
+			if _, found := members[b.Role]; !found {
+				roles = append(roles, b.Role)
+			}
+			members[b.Role] = append(members[b.Role], b.Members...)
+		}
+		for _, role := range roles {
+			fields = append(fields, diffField{"bindings." + role, sortedJoin(members[role])})
+		}
+		return fields
+	}
+	return nil
+}
+
+// diffFields returns the changes between old and new fields in order of old fields
+// followed by fields only in new.
+func diffFields(oldFields, newFields []diffField) []fieldChange {
+	newValues := make(map[string]string, len(newFields))
+	for _, f := range newFields {
+		newValues[f.name] = f.value
+	}
+	var changes []fieldChange
+	seen := make(map[string]bool, len(oldFields))
+	for _, f := range oldFields {
+		seen[f.name] = true
+		if f.value != newValues[f.name] {
+			changes = append(changes, fieldChange{f.name, f.value, newValues[f.name]})
+		}
+	}
+	for _, f := range newFields {
+		if !seen[f.name] && f.value != "" {
+			changes = append(changes, fieldChange{f.name, "", f.value})
+		}
+	}
+	return changes
+}
+
+func sortedJoin(values []string) string {
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ", ")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// write outputs the diff into buff in text or json format.
+func (d *orgDiff) write(buff io.Writer, format string) error {
+	switch strings.ToLower(format) {
+	case "json":
+		enc := json.NewEncoder(buff)
+		enc.SetIndent("", strings.Repeat(" ", defaultIndentSize))
+		return enc.Encode(d)
+	case "text", "":
+		return d.writeText(buff)
+	default:
+		return fmt.Errorf("unsupported diff format %q", format)
+	}
+}
+
+// writeText outputs the diff in a human readable format.
+//
+// Lines are prefixed by + for added, - for removed and ~ for changed resources.
+func (d *orgDiff) writeText(buff io.Writer) error {
+	if d.empty() {
+		_, err := fmt.Fprintln(buff, "No changes.")
+		return err
+	}
+	var lines []string
+	for _, c := range d.OrgChanges {
+		lines = append(lines, fmt.Sprintf("~ %s %s: %q -> %q", Organization, c.Field, c.Old, c.New))
+	}
+	for _, c := range d.Added {
+		lines = append(lines, fmt.Sprintf("+ %s.%s (%q) < %s", Folder, c.Id, c.DisplayName, c.Parent))
+	}
+	for _, c := range d.Removed {
+		lines = append(lines, fmt.Sprintf("- %s.%s (%q) < %s", Folder, c.Id, c.DisplayName, c.Parent))
+	}
+	for _, c := range d.Renamed {
+		lines = append(lines, fmt.Sprintf("~ %s.%s renamed: %q -> %q", Folder, c.Id, c.OldDisplayName, c.DisplayName))
+	}
+	for _, c := range d.Reparented {
+		lines = append(lines, fmt.Sprintf("~ %s.%s re-parented: %s -> %s", Folder, c.Id, c.OldParent, c.Parent))
+	}
+	for _, c := range d.AddedResources {
+		lines = append(lines, fmt.Sprintf("+ %s.%s", c.Kind, c.Id))
+	}
+	for _, c := range d.RemovedResources {
+		lines = append(lines, fmt.Sprintf("- %s.%s", c.Kind, c.Id))
+	}
+	for _, c := range d.ChangedResources {
+		for _, f := range c.Changes {
+			lines = append(lines, fmt.Sprintf("~ %s.%s %s: %q -> %q", c.Kind, c.Id, f.Field, f.Old, f.New))
+		}
+	}
+	lines = append(lines, fmt.Sprintf("Folders: %d added, %d removed, %d renamed, %d re-parented.",
+		len(d.Added), len(d.Removed), len(d.Renamed), len(d.Reparented)))
+	lines = append(lines, fmt.Sprintf("Projects, networks and IAM policies: %d added, %d removed, %d changed.",
+		len(d.AddedResources), len(d.RemovedResources), len(d.ChangedResources)))
+	_, err := fmt.Fprintln(buff, strings.Join(lines, "\n"))
+	return err
+}
@@ -0,0 +1,216 @@
+package launchpad
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const diffTestDataDir = "../testdata/launchpad/diff"
+
+func TestDiffOrgs(t *testing.T) {
+	oldOrg, err := assembleForDiff([]string{filepath.Join(diffTestDataDir, "old")})
+	assert.Nil(t, err, "old YAMLs should be assembled")
+	newOrg, err := assembleForDiff([]string{filepath.Join(diffTestDataDir, "new", "org.yaml"), filepath.Join(diffTestDataDir, "new", "f*.yaml"), filepath.Join(diffTestDataDir, "new", "project.yaml")})
+	assert.Nil(t, err, "new YAMLs should be assembled")
+
+	d := diffOrgs(oldOrg, newOrg)
+	assert.Equal(t, []fieldChange{{"displayName", "example.com", "example.org"}}, d.OrgChanges)
+	assert.Equal(t, []*folderChange{{Id: "group3", DisplayName: "group3", Parent: "Folder.group1"}}, d.Added)
+	assert.Equal(t, []*folderChange{{Id: "group12", DisplayName: "group12", Parent: "Folder.group1"}}, d.Removed)
+	assert.Equal(t, []*folderChange{{
+		Id: "group1", DisplayName: "group one", Parent: "Organization.12345678", OldDisplayName: "group1",
+	}}, d.Renamed)
+	assert.Equal(t, []*folderChange{{
+		Id: "group11", DisplayName: "group11", Parent: "Folder.group2", OldParent: "Folder.group1",
+	}}, d.Reparented)
+	assert.Equal(t, []*resourceChange{{Kind: "Project", Id: "app-project"}}, d.AddedResources)
+	assert.Equal(t, []*resourceChange{{Kind: "Project", Id: "legacy-project"}}, d.RemovedResources)
+	assert.Equal(t, []*resourceChange{{
+		Kind: "IAMPolicy", Id: "group1_viewers", Changes: []fieldChange{
+			{"bindings.roles/resourcemanager.folderViewer", "group:viewers@example.com", "group:viewers@example.com, user:jane@example.com"},
+			{"bindings.roles/resourcemanager.folderEditor", "", "group:editors@example.com"},
+		},
+	}, {
+		Kind: "Network", Id: "shared-vpc", Changes: []fieldChange{
+			{"subnets", "subnet1 us-central1 10.0.0.0/24", "subnet1 us-central1 10.0.0.0/24, subnet2 us-east1 10.0.1.0/24"},
+		},
+	}, {
+		Kind: "Project", Id: "host-project-1", Changes: []fieldChange{
+			{"billingAccount", "000000-000000-000000", "111111-111111-111111"},
+			{"services", "compute.googleapis.com", "compute.googleapis.com, container.googleapis.com"},
+		},
+	}}, d.ChangedResources)
+
+	assert.True(t, diffOrgs(newOrg, newOrg).empty(), "same org should have no diff")
+}
+
+func TestOrgDiff_write(t *testing.T) {
+	oldOrg, err := assembleForDiff([]string{filepath.Join(diffTestDataDir, "old")})
+	assert.Nil(t, err, "old YAMLs should be assembled")
+	newOrg, err := assembleForDiff([]string{filepath.Join(diffTestDataDir, "new")})
+	assert.Nil(t, err, "new YAMLs should be assembled")
+
+	var testCases = []struct {
+		name     string
+		diff     *orgDiff
+		format   string
+		expected string
+	}{{
+		"text",
+		diffOrgs(oldOrg, newOrg),
+		"text",
+		`~ Organization displayName: "example.com" -> "example.org"
++ Folder.group3 ("group3") < Folder.group1
+- Folder.group12 ("group12") < Folder.group1
+~ Folder.group1 renamed: "group1" -> "group one"
+~ Folder.group11 re-parented: Folder.group1 -> Folder.group2
++ Project.app-project
+- Project.legacy-project
+~ IAMPolicy.group1_viewers bindings.roles/resourcemanager.folderViewer: "group:viewers@example.com" -> "group:viewers@example.com, user:jane@example.com"
+~ IAMPolicy.group1_viewers bindings.roles/resourcemanager.folderEditor: "" -> "group:editors@example.com"
+~ Network.shared-vpc subnets: "subnet1 us-central1 10.0.0.0/24" -> "subnet1 us-central1 10.0.0.0/24, subnet2 us-east1 10.0.1.0/24"
+~ Project.host-project-1 billingAccount: "000000-000000-000000" -> "111111-111111-111111"
+~ Project.host-project-1 services: "compute.googleapis.com" -> "compute.googleapis.com, container.googleapis.com"
+Folders: 1 added, 1 removed, 1 renamed, 1 re-parented.
+Projects, networks and IAM policies: 1 added, 1 removed, 3 changed.
+`,
+	}, {
+		"text_no_changes",
+		diffOrgs(oldOrg, oldOrg),
+		"",
+		"No changes.\n",
+	}, {
+		"json_no_changes",
+		diffOrgs(oldOrg, oldOrg),
+		"json",
+		`{
+  "orgChanges": [],
+  "addedFolders": [],
+  "removedFolders": [],
+  "renamedFolders": [],
+  "reparentedFolders": [],
+  "addedResources": [],
+  "removedResources": [],
+  "changedResources": []
+}
+`,
+	}, {
+		"json",
+		diffOrgs(oldOrg, newOrg),
+		"json",
+		`{
+  "orgChanges": [
+    {
+      "field": "displayName",
+      "old": "example.com",
+      "new": "example.org"
+    }
+  ],
+  "addedFolders": [
+    {
+      "id": "group3",
+      "displayName": "group3",
+      "parent": "Folder.group1"
+    }
+  ],
+  "removedFolders": [
+    {
+      "id": "group12",
+      "displayName": "group12",
+      "parent": "Folder.group1"
+    }
+  ],
+  "renamedFolders": [
+    {
+      "id": "group1",
+      "displayName": "group one",
+      "parent": "Organization.12345678",
+      "oldDisplayName": "group1"
+    }
+  ],
+  "reparentedFolders": [
+    {
+      "id": "group11",
+      "displayName": "group11",
+      "parent": "Folder.group2",
+      "oldParent": "Folder.group1"
+    }
+  ],
+  "addedResources": [
+    {
+      "kind": "Project",
+      "id": "app-project"
+    }
+  ],
+  "removedResources": [
+    {
+      "kind": "Project",
+      "id": "legacy-project"
+    }
+  ],
+  "changedResources": [
+    {
+      "kind": "IAMPolicy",
+      "id": "group1_viewers",
+      "changes": [
+        {
+          "field": "bindings.roles/resourcemanager.folderViewer",
+          "old": "group:viewers@example.com",
+          "new": "group:viewers@example.com, user:jane@example.com"
+        },
+        {
+          "field": "bindings.roles/resourcemanager.folderEditor",
+          "old": "",
+          "new": "group:editors@example.com"
+        }
+      ]
+    },
+    {
+      "kind": "Network",
+      "id": "shared-vpc",
+      "changes": [
+        {
+          "field": "subnets",
+          "old": "subnet1 us-central1 10.0.0.0/24",
+          "new": "subnet1 us-central1 10.0.0.0/24, subnet2 us-east1 10.0.1.0/24"
+        }
+      ]
+    },
+    {
+      "kind": "Project",
+      "id": "host-project-1",
+      "changes": [
+        {
+          "field": "billingAccount",
+          "old": "000000-000000-000000",
+          "new": "111111-111111-111111"
+        },
+        {
+          "field": "services",
+          "old": "compute.googleapis.com",
+          "new": "compute.googleapis.com, container.googleapis.com"
+        }
+      ]
+    }
+  ]
+}
+`,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buff := &bytes.Buffer{}
+			assert.Nil(t, tc.diff.write(buff, tc.format))
+			assert.Equal(t, tc.expected, buff.String())
+		})
+	}
+	assert.NotNil(t, diffOrgs(oldOrg, newOrg).write(&bytes.Buffer{}, "xml"), "unsupported format should fail")
+}
+
+func TestAssembleForDiffInvalid(t *testing.T) {
+	_, err := assembleForDiff([]string{"../testdata/launchpad/strict"})
+	assert.NotNil(t, err, "invalid YAMLs should fail")
+}
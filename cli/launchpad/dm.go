@@ -0,0 +1,78 @@
+// Package launchpad file dm.go contains Deployment Manager output generation
+// from an assembled organization.
+package launchpad
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+)
+
+const (
+	dmTmplDir     = "static/tmpl/dm"
+	dmLicenseTmpl = "license.yaml.tmpl"
+)
+
+// dmFiles are generated for a Deployment Manager deployment of the organization.
+var dmFiles = []outputFile{
+	{name: "config.yaml", tmpl: "config.yaml.tmpl"},
+	{name: "templates/folder.jinja", tmpl: "folder.jinja", raw: true},
+	{name: "templates/folder.jinja.schema", tmpl: "folder.jinja.schema", raw: true},
+}
+
+// dmConfigData is the template input for Deployment Manager configuration.
+type dmConfigData struct {
+	Org     *orgYAML
+	Folders []dmFolderData // Folders is ordered such that parents are defined before children.
+}
+
+// dmFolderData is the template input for a folder resource.
+type dmFolderData struct {
+	Id          string
+	DisplayName string
+	Parent      string // Parent is organization name or reference to parent folder's output.
+}
+
+// dmRenderer renders an assembled organization as Deployment Manager configuration.
+type dmRenderer struct{}
+
+// render writes the assembled organization as a Deployment Manager configuration.
+//
+// All folders are written into a single config.yaml as resources of folder.jinja
+// template, nested folders reference their parent folder's output.
+//
+// Deployment Manager flavor only supports organization and folders, other resources
+// are skipped with a warning.
+func (r *dmRenderer) render(ao *assembledOrg, outputDir string) error {
+	if ao.org.Spec.Id == "" {
+		return errUndefinedOrg
+	}
+	data := dmConfigData{Org: &ao.org}
+	orgName := fmt.Sprintf("organizations/%s", ao.org.Spec.Id)
+	skipped := len(ao.org.projects) + len(ao.org.iamPolicies)
+	for _, f := range ao.org.subFolders.sortedCopy() {
+		skipped += data.addFolder(f, orgName)
+	}
+	if skipped > 0 {
+		log.Printf("warning: %d project and IAM policy resources not supported by %s flavor were skipped\n",
+			skipped, DeploymentManager)
+	}
+	return writeOutputFiles(outputDir, dmTmplDir, dmLicenseTmpl, dmFiles, data)
+}
+
+// addFolder recursively appends the folder and its sub folders to the configuration.
+//
+// addFolder returns the number of unsupported resources under the folder.
+func (d *dmConfigData) addFolder(f *folderYAML, parent string) int {
+	d.Folders = append(d.Folders, dmFolderData{Id: f.Spec.Id, DisplayName: f.Spec.DisplayName, Parent: parent})
+	skipped := len(f.projects) + len(f.iamPolicies)
+	for _, sf := range f.subFolders.sortedCopy() {
+		skipped += d.addFolder(sf, fmt.Sprintf("$(ref.%s.name)", f.Spec.Id))
+	}
+	return skipped
+}
+
+// yamlString returns s as a double quoted YAML string.
+func yamlString(s string) string {
+	return strconv.Quote(s)
+}
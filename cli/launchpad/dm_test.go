@@ -0,0 +1,62 @@
+package launchpad
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const dmExpectedDir = "../testdata/launchpad/dm"
+
+func TestDmRenderer_render(t *testing.T) {
+	var testCases = []struct {
+		name       string
+		inputYAMLs []string
+	}{{
+		"org_crd_subfolder",
+		[]string{"folder/org_1.yaml"},
+	}, {
+		"nested_folder_out_of_order",
+		[]string{"folder/folder_1.yaml", "folder/folder_12.yaml"},
+	}, {
+		"deep_nested",
+		[]string{"folder/folder_1_nested.yaml"},
+	}, {
+		"projects_networks_iam",
+		[]string{"project/org_1_projects.yaml", "project/iam_1.yaml"},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var inputs []string
+			for _, fp := range tc.inputYAMLs {
+				inputs = append(inputs, filepath.Join(testDataDir, fp))
+			}
+			outDir := t.TempDir()
+			err := (&dmRenderer{}).render(assembleResourcesToOrg(loadResources(inputs)), outDir)
+			assert.Nil(t, err, "generation should succeed")
+
+			expectedDir := filepath.Join(dmExpectedDir, tc.name)
+			updateExpected(t, expectedDir, outDir)
+			assert.Equal(t, readDir(t, expectedDir), readDir(t, outDir), "generated files should match expected")
+		})
+	}
+}
+
+func TestDmRenderer_renderUndefinedOrg(t *testing.T) {
+	err := (&dmRenderer{}).render(newAssembledOrg(), t.TempDir())
+	assert.Equal(t, errUndefinedOrg, err)
+}
+
+func TestGenerateOutput(t *testing.T) {
+	ao := assembleResourcesToOrg(loadResources([]string{filepath.Join(testDataDir, "folder/org_1.yaml")}))
+	for flavor := range supportedFlavor {
+		t.Run(flavor.String(), func(t *testing.T) {
+			outDir := t.TempDir()
+			assert.Nil(t, generateOutput(ao, flavor, outDir), "generation should succeed")
+			assert.NotEmpty(t, readDir(t, outDir), "files should be generated")
+		})
+	}
+	assert.NotNil(t, generateOutput(ao, OutputFlavor(-1), t.TempDir()), "unsupported flavor should fail")
+}
@@ -17,8 +17,8 @@ const (
 type folderSpecYAML struct { // Inner mappings
 	Id             string                 `yaml:"id"`
 	DisplayName    string                 `yaml:"displayName"`
-	ParentRef      referenceYAML          `yaml:"parentRef"`
-	SubFolderSpecs []*folderSpecYAML      `yaml:"folders"`
+	ParentRef      referenceYAML          `yaml:"parentRef,omitempty"`
+	SubFolderSpecs []*folderSpecYAML      `yaml:"folders,omitempty"`
 	Undefined      map[string]interface{} `yaml:",inline"` // Catch-all for unintended behavior
 }
 
@@ -58,9 +58,11 @@ func (fs folders) sortedCopy() folders {
 
 // folderYAML is a GCP Folder YAML representation.
 type folderYAML struct {
-	headerYAML `yaml:",inline"`
-	Spec       folderSpecYAML `yaml:"spec"`
-	subFolders folders        // subFolders is a validated sub directories.
+	headerYAML  `yaml:",inline"`
+	Spec        folderSpecYAML `yaml:"spec"`
+	subFolders  folders        // subFolders is a validated sub directories.
+	projects    projects       // projects is validated projects under this folder.
+	iamPolicies iamPolicies    // iamPolicies is validated IAM policies applied on this folder.
 }
 
 // resId returns an internal referencable id.
@@ -91,8 +93,9 @@ func (f *folderYAML) validate() error {
 	}
 
 	f.subFolders = newSubFoldersBySpecs(f.Spec.SubFolderSpecs, Folder, f.Spec.Id)
-	for _, f := range f.subFolders { // triggers subfolder validation to validate and add nested folders
-		if err := f.validate(); err != nil {
+	for _, sf := range f.subFolders { // triggers subfolder validation to validate and add nested folders
+		sf.pos = f.pos // nested folders are defined in the same document
+		if err := sf.validate(); err != nil {
 			return err
 		}
 	}
@@ -117,7 +120,9 @@ func (f *folderYAML) addToOrg(ao *assembledOrg) error {
 
 // resolveReferences processes references to folder.
 //
-// resolveReferences takes reference from folder as a subFolder of this folder.
+// resolveReferences takes reference from folder as a subFolder of this folder, reference
+// from project as a project under this folder, and reference from IAM policy as a policy
+// applied on this folder.
 func (f *folderYAML) resolveReferences(refs []resourceHandler) error {
 	for _, ref := range refs {
 		switch r := ref.(type) {
@@ -127,6 +132,10 @@ func (f *folderYAML) resolveReferences(refs []resourceHandler) error {
 				return errInvalidParent
 			}
 			_ = f.subFolders.add(r) // silently ignore existing resource
+		case *projectYAML:
+			_ = f.projects.add(r) // silently ignore existing resource
+		case *iamPolicyYAML:
+			_ = f.iamPolicies.add(r) // silently ignore existing resource
 		default:
 			log.Printf("fatal: invalid %s parent for %s\n", f.resId(), r.resId())
 			return errInvalidInput
@@ -149,7 +158,12 @@ func (f *folderYAML) dump(ind int, buff io.Writer) error {
 			return err
 		}
 	}
-	return nil
+	for _, p := range f.projects.sortedCopy() {
+		if err = p.dump(ind+defaultIndentSize, buff); err != nil {
+			return err
+		}
+	}
+	return f.iamPolicies.dump(ind+defaultIndentSize, buff)
 }
 
 // newSubFoldersBySpecs initializes folderSpecYAMLs and turn it into a folderYAMLs.
@@ -0,0 +1,61 @@
+//go:build ignore
+
+// gen_statics.go embeds all files under static directory into statics.go.
+//
+// It is triggered by `$ go generate` within the launchpad package.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	staticDir  = "static"
+	outputFile = "statics.go"
+)
+
+func main() {
+	var paths []string
+	err := filepath.Walk(staticDir, func(fp string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			paths = append(paths, fp)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatalln("unable to list static files:", err)
+	}
+	sort.Strings(paths)
+
+	buff := &bytes.Buffer{}
+	fmt.Fprint(buff, "package launchpad\n\n// WARNING: Generated file, do not modify directly!\n\nvar statics = map[string]string{\n")
+	for _, fp := range paths {
+		content, err := os.ReadFile(fp)
+		if err != nil {
+			log.Fatalln("unable to read static file:", err)
+		}
+		if strings.Contains(string(content), "`") {
+			log.Fatalf("static file %s cannot contain backtick", fp)
+		}
+		fmt.Fprintf(buff, "%q: `%s`,\n", filepath.ToSlash(fp), content)
+	}
+	fmt.Fprint(buff, "}\n")
+
+	out, err := format.Source(buff.Bytes())
+	if err != nil {
+		log.Fatalln("unable to format generated code:", err)
+	}
+	if err = os.WriteFile(outputFile, out, 0644); err != nil {
+		log.Fatalln("unable to write generated code:", err)
+	}
+}
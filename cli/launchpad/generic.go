@@ -3,8 +3,11 @@ package launchpad
 import (
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -23,6 +26,9 @@ var supportedVersion = map[string]map[crdKind]func() resourceHandler{
 var v1alpha1SupportedKind = map[crdKind]func() resourceHandler{
 	Folder:       func() resourceHandler { return &folderYAML{} },
 	Organization: func() resourceHandler { return &orgYAML{} },
+	Project:      func() resourceHandler { return &projectYAML{} },
+	Network:      func() resourceHandler { return &networkYAML{} },
+	IAMPolicy:    func() resourceHandler { return &iamPolicyYAML{} },
 }
 
 var (
@@ -30,9 +36,21 @@ var (
 	errMissingRequiredField = errors.New("missing required field")
 	errInvalidParent        = errors.New("invalid parent reference")
 	errInvalidInput         = errors.New("invalid input")
+	errUnknownField         = errors.New("unknown field")
 	tfNameRegex             = regexp.MustCompile(`^[a-zA-Z][a-zA-Z\d\-\_]*$`)
+	gcpNameRegex            = regexp.MustCompile(`^[a-z]([-a-z0-9]*[a-z0-9])?$`) // RFC1035 names of GCP resources.
 )
 
+// gcpNameMaxLen is the maximum length of an RFC1035 GCP resource name.
+const gcpNameMaxLen = 63
+
+// validGCPName returns true if name can be used as an RFC1035 GCP resource name.
+//
+// Valid GCP names are also valid Terraform names.
+func validGCPName(name string) bool {
+	return len(name) <= gcpNameMaxLen && gcpNameRegex.MatchString(name)
+}
+
 // resourceHandler represents a resource that can be processed by launchpad.
 type resourceHandler interface {
 	// resId defines the internal referencable id.
@@ -45,6 +63,10 @@ type resourceHandler interface {
 	addToOrg(ao *assembledOrg) error
 	// resolveReferences takes action on resources referencing the current resource.
 	resolveReferences(refs []resourceHandler) error
+	// dump writes resource's string representation into provided buffer.
+	dump(ind int, buff io.Writer) error
+	// source returns where the resource is defined.
+	source() *sourcePos
 }
 
 // crdKind is the CustomResourceDefinition (CRD) which is indicated by YAML Kind value.
@@ -54,10 +76,13 @@ const (
 	CloudFoundation crdKind = iota
 	Folder
 	Organization
+	Project
+	Network
+	IAMPolicy
 )
 
 func (k crdKind) String() string {
-	return []string{"CloudFoundation", "Folder", "Organization"}[k]
+	return []string{"CloudFoundation", "Folder", "Organization", "Project", "Network", "IAMPolicy"}[k]
 }
 
 // newCRDKind parses string formatted crdKind and convert to internal format.
@@ -71,6 +96,12 @@ func newCRDKind(crdKindStr string) crdKind {
 		return Folder
 	case "organization":
 		return Organization
+	case "project":
+		return Project
+	case "network":
+		return Network
+	case "iampolicy":
+		return IAMPolicy
 	default:
 		log.Printf("warning: unsupported CustomResourceDefinition %q", crdKindStr)
 		return -1
@@ -79,11 +110,13 @@ func newCRDKind(crdKindStr string) crdKind {
 
 // headerYAML defines the common fields all CRD is required to have.
 type headerYAML struct {
-	APIVersion string `yaml:"apiVersion"`
-	KindStr    string `yaml:"kind"`
+	APIVersion string    `yaml:"apiVersion"`
+	KindStr    string    `yaml:"kind"`
+	pos        sourcePos // pos tracks where the resource is defined.
 }
 
-func (h *headerYAML) kind() crdKind { return newCRDKind(h.KindStr) }
+func (h *headerYAML) kind() crdKind      { return newCRDKind(h.KindStr) }
+func (h *headerYAML) source() *sourcePos { return &h.pos }
 
 // referenceYAML represents an explicit reference to another resource.
 //
@@ -97,3 +130,102 @@ type referenceYAML struct {
 
 func (r *referenceYAML) TargetType() crdKind { return newCRDKind(r.TargetTypeStr) }
 func (r *referenceYAML) resId() string       { return fmt.Sprintf("%s.%s", r.TargetType(), r.TargetId) }
+
+// sourcePos identifies where a resource is defined.
+type sourcePos struct {
+	path   string
+	docIdx int // docIdx is the 1-based index of YAML document within the file.
+	line   int // line is the 1-based line number within the file.
+}
+
+var yamlErrLineRegex = regexp.MustCompile(`line (\d+):`)
+
+// withYAMLErrLine returns a position pointing to the line reported by a YAML error.
+//
+// YAML errors report line relative to the document, which is translated into the file line.
+func (p sourcePos) withYAMLErrLine(err error) sourcePos {
+	m := yamlErrLineRegex.FindStringSubmatch(err.Error())
+	if m == nil {
+		return p
+	}
+	if l, convErr := strconv.Atoi(m[1]); convErr == nil {
+		p.line += l - 1
+	}
+	return p
+}
+
+func (p sourcePos) String() string {
+	switch {
+	case p.path == "":
+		return "<unknown>"
+	case p.docIdx == 0:
+		return p.path
+	default:
+		return fmt.Sprintf("%s:%d (document %d)", p.path, p.line, p.docIdx)
+	}
+}
+
+// loadError is an error encountered while loading or assembling a resource.
+type loadError struct {
+	pos    sourcePos
+	err    error  // err is the underlying error.
+	detail string // detail optionally describes the error.
+}
+
+func (e *loadError) Error() string {
+	msg := e.err.Error()
+	if e.detail != "" {
+		msg = fmt.Sprintf("%s: %s", e.detail, msg)
+	}
+	return fmt.Sprintf("%s: %s", e.pos, msg)
+}
+
+func (e *loadError) Unwrap() error { return e.err }
+
+// loadErrors represents a list of errors collected across resources.
+type loadErrors []*loadError
+
+func (es loadErrors) Error() string {
+	var msgs []string
+	for _, e := range es {
+		msgs = append(msgs, e.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// unknownFields returns YAML fields not recognized by the resource, including its nested specs.
+func unknownFields(r resourceHandler) []string {
+	var fields []string
+	addFields := func(prefix string, undefined map[string]interface{}) {
+		for k := range undefined {
+			fields = append(fields, prefix+k)
+		}
+	}
+	var addFolderFields func(prefix string, fs []*folderSpecYAML)
+	addFolderFields = func(prefix string, fs []*folderSpecYAML) {
+		for _, f := range fs {
+			addFields(fmt.Sprintf("%sfolders[%s].", prefix, f.Id), f.Undefined)
+			addFolderFields(fmt.Sprintf("%sfolders[%s].", prefix, f.Id), f.SubFolderSpecs)
+		}
+	}
+
+	switch res := r.(type) {
+	case *orgYAML:
+		addFields("spec.", res.Spec.Undefined)
+		addFolderFields("spec.", res.Spec.SubFolderSpecs)
+	case *folderYAML:
+		addFields("spec.", res.Spec.Undefined)
+		addFolderFields("spec.", res.Spec.SubFolderSpecs)
+	case *projectYAML:
+		addFields("spec.", res.Spec.Undefined)
+	case *networkYAML:
+		addFields("spec.", res.Spec.Undefined)
+		for _, sn := range res.Spec.SubnetSpecs {
+			addFields(fmt.Sprintf("spec.subnets[%s].", sn.Id), sn.Undefined)
+		}
+	case *iamPolicyYAML:
+		addFields("spec.", res.Spec.Undefined)
+	}
+	sort.Strings(fields)
+	return fields
+}
@@ -0,0 +1,130 @@
+package launchpad
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+)
+
+// iamBindingYAML defines a role granted to a list of members.
+type iamBindingYAML struct {
+	Role    string   `yaml:"role"`
+	Members []string `yaml:"members"`
+}
+
+// iamPolicySpecYAML defines IAM Policy's spec.
+type iamPolicySpecYAML struct {
+	Id          string                 `yaml:"id"`
+	ResourceRef referenceYAML          `yaml:"resourceRef"`
+	Bindings    []*iamBindingYAML      `yaml:"bindings"`
+	Undefined   map[string]interface{} `yaml:",inline"` // Catch-all for unintended behavior
+}
+
+// iamPolicies represents a list of IAM policies.
+type iamPolicies []*iamPolicyYAML
+
+// add appends an IAM policy into the policy list if it does not exist already.
+//
+// add returns existing policy's reference if attempted to add policy of the same ID.
+func (ips *iamPolicies) add(newIp *iamPolicyYAML) *iamPolicyYAML {
+	for _, ip := range *ips {
+		if ip.Spec.Id == newIp.Spec.Id {
+			return ip
+		}
+	}
+	*ips = append(*ips, newIp)
+	return nil
+}
+
+func (ips iamPolicies) sortedCopy() iamPolicies {
+	buff := make(iamPolicies, len(ips))
+	copy(buff, ips)
+	sort.SliceStable(buff, func(i, j int) bool { return buff[i].Spec.Id < buff[j].Spec.Id })
+	return buff
+}
+
+// dump writes string representation of all policies into provided buffer.
+func (ips iamPolicies) dump(ind int, buff io.Writer) error {
+	for _, ip := range ips.sortedCopy() {
+		if err := ip.dump(ind, buff); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// iamPolicyYAML is an IAM Policy YAML representation.
+//
+// An IAM Policy grants roles on the referenced organization, folder or project.
+type iamPolicyYAML struct {
+	headerYAML `yaml:",inline"`
+	Spec       iamPolicySpecYAML `yaml:"spec"`
+}
+
+// resId returns an internal referencable id.
+func (ip *iamPolicyYAML) resId() string { return fmt.Sprintf("%s.%s", IAMPolicy, ip.Spec.Id) }
+
+// validate ensures input YAML fields are correct.
+func (ip *iamPolicyYAML) validate() error {
+	if ip.Spec.Id == "" || len(ip.Spec.Bindings) == 0 {
+		return errMissingRequiredField
+	}
+	switch ip.Spec.ResourceRef.TargetTypeStr { // Validate Supported Resources
+	case Organization.String(), Folder.String(), Project.String():
+	default:
+		log.Printf("fatal: unsupported resource '%s' type for IAMPolicy\n", ip.Spec.ResourceRef.TargetTypeStr)
+		return errInvalidParent
+	}
+	if !tfNameRegex.MatchString(ip.Spec.Id) {
+		log.Printf("IAM Policy [%s] ID does not conform to Terraform standard", ip.Spec.Id)
+		return errValidationFailed
+	}
+	seen := make(map[string]bool)
+	for _, b := range ip.Spec.Bindings {
+		if b.Role == "" || len(b.Members) == 0 {
+			log.Printf("IAM Policy [%s] requires role and members for each binding", ip.Spec.Id)
+			return errMissingRequiredField
+		}
+		if seen[b.Role] {
+			log.Printf("IAM Policy [%s] binds role %s more than once", ip.Spec.Id, b.Role)
+			return errConflictDefinition
+		}
+		seen[b.Role] = true
+	}
+	return nil
+}
+
+// addToOrg adds the IAM policy into the assembled organization.
+func (ip *iamPolicyYAML) addToOrg(ao *assembledOrg) error {
+	return ao.registerResource(ip, &ip.Spec.ResourceRef)
+}
+
+// resolveReferences processes references to IAM policy.
+//
+// IAM policy cannot be referenced by any resource.
+func (ip *iamPolicyYAML) resolveReferences(refs []resourceHandler) error {
+	for _, ref := range refs {
+		log.Printf("fatal: invalid %s reference for %s\n", ip.resId(), ref.resId())
+		return errInvalidInput
+	}
+	return nil
+}
+
+// dump writes resource's string representation into provided buffer.
+func (ip *iamPolicyYAML) dump(ind int, buff io.Writer) error {
+	indent := strings.Repeat(" ", ind)
+	_, err := fmt.Fprintf(buff, "%s+ %s.%s < %s.%s\n", indent, IAMPolicy, ip.Spec.Id,
+		ip.Spec.ResourceRef.TargetTypeStr, ip.Spec.ResourceRef.TargetId)
+	if err != nil {
+		return err
+	}
+	for _, b := range ip.Spec.Bindings {
+		_, err = fmt.Fprintf(buff, "%s  - %s: [%s]\n", indent, b.Role, strings.Join(b.Members, ", "))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package launchpad
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIAMPolicyYAML_validate(t *testing.T) {
+	folderRef := referenceYAML{Folder.String(), "f1"}
+	viewer := &iamBindingYAML{Role: "roles/viewer", Members: []string{"user:jane@example.com"}}
+	var testCases = []struct {
+		name   string
+		input  iamPolicySpecYAML
+		output error
+	}{
+		{"missing_id", iamPolicySpecYAML{ResourceRef: folderRef, Bindings: []*iamBindingYAML{viewer}}, errMissingRequiredField},
+		{"missing_bindings", iamPolicySpecYAML{Id: "p1", ResourceRef: folderRef}, errMissingRequiredField},
+		{
+			"invalid_resource",
+			iamPolicySpecYAML{Id: "p1", ResourceRef: referenceYAML{Network.String(), "n1"}, Bindings: []*iamBindingYAML{viewer}},
+			errInvalidParent,
+		},
+		{
+			"missing_members",
+			iamPolicySpecYAML{Id: "p1", ResourceRef: folderRef, Bindings: []*iamBindingYAML{{Role: "roles/viewer"}}},
+			errMissingRequiredField,
+		},
+		{
+			"duplicated_role",
+			iamPolicySpecYAML{Id: "p1", ResourceRef: folderRef, Bindings: []*iamBindingYAML{viewer, viewer}},
+			errConflictDefinition,
+		},
+		{"valid", iamPolicySpecYAML{Id: "p1", ResourceRef: folderRef, Bindings: []*iamBindingYAML{viewer}}, nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ip := &iamPolicyYAML{Spec: tc.input}
+			assert.Equal(t, tc.output, ip.validate())
+		})
+	}
+}
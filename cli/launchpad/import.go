@@ -0,0 +1,218 @@
+// Package launchpad file import.go contains support for importing an existing
+// GCP organization hierarchy from a Cloud Asset Inventory export.
+package launchpad
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/config-validator/pkg/api/validator"
+	"google.golang.org/protobuf/types/known/structpb"
+	"gopkg.in/yaml.v3"
+
+	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/cli/scorecard"
+)
+
+const (
+	caiOrgType       = "cloudresourcemanager.googleapis.com/Organization"
+	caiFolderType    = "cloudresourcemanager.googleapis.com/Folder"
+	caiNamePrefix    = "//cloudresourcemanager.googleapis.com/"
+	caiOrgPrefix     = "organizations/"
+	caiActiveState   = "ACTIVE"
+	importedIdPrefix = "folder_"
+)
+
+var (
+	errOrgNotFound     = errors.New("no organization found in inventory")
+	errMultipleOrgs    = errors.New("multiple organizations found in inventory")
+	importIdInvalidRun = regexp.MustCompile(`[^a-zA-Z\d\-\_]+`)
+)
+
+// NewImport reads a Cloud Asset Inventory export directory and outputs launchpad YAML
+// reproducing the live organization and folder hierarchy.
+//
+// The export directory is expected in the same format as read by scorecard. Output is
+// written to stdout if outputFile is empty.
+//
+// NewImport can be triggered by
+//
+//	$ cft launchpad import --dir-path ./cai-dir
+//	$ cft lp i --dir-path ./cai-dir --output org.yaml
+func NewImport(caiDir string, outputFile string) {
+	assets, err := scorecard.GetAssetsFromDir(caiDir)
+	if err != nil {
+		log.Fatalln("Unable to read Cloud Asset Inventory:", err.Error())
+	}
+	o, fs, err := importOrg(assets)
+	if err != nil {
+		log.Fatalln("Unable to import organization:", err.Error())
+	}
+	rs := []resourceHandler{o}
+	for _, f := range fs {
+		rs = append(rs, f)
+	}
+	content, err := marshalYAML(rs...)
+	if err != nil {
+		log.Fatalln("Unable to output YAML:", err.Error())
+	}
+	if outputFile == "" {
+		fmt.Print(string(content))
+		return
+	}
+	if err = os.WriteFile(outputFile, content, 0644); err != nil {
+		log.Fatalln("Unable to write output:", err.Error())
+	}
+	log.Printf("Organization %s imported to %s\n", o.Spec.Id, outputFile)
+}
+
+// caiFolder is a folder discovered from Cloud Asset Inventory.
+type caiFolder struct {
+	name        string // name is the folder resource name in the form of folders/{folder_id}.
+	parent      string // parent is the resource name of the parent organization or folder.
+	displayName string
+	id          string // id is the launchpad folder id derived from the display name.
+}
+
+// importOrg converts Cloud Asset Inventory assets into an Organization resource and
+// Folder resources referencing their parent, as written by hand.
+//
+// Folders are ordered parents first and folder ids are derived from display names to conform
+// to Terraform naming. Display names are kept as is since applying a changed name would rename
+// the live folder, folders with display names launchpad does not accept are skipped along with
+// their sub folders. Folders not in ACTIVE state, folders with a parent not in the inventory and
+// assets other than organization and folders are ignored.
+func importOrg(assets []*validator.Asset) (*orgYAML, folders, error) {
+	o := &orgYAML{headerYAML: headerYAML{APIVersion: apiCFTv1alpha1, KindStr: Organization.String()}}
+	var fs []*caiFolder
+	for _, a := range assets {
+		fields := a.GetResource().GetData().GetFields()
+		switch a.GetAssetType() {
+		case caiOrgType:
+			orgId := strings.TrimPrefix(strings.TrimPrefix(a.GetName(), caiNamePrefix), caiOrgPrefix)
+			if o.Spec.Id != "" && o.Spec.Id != orgId {
+				return nil, nil, errMultipleOrgs
+			}
+			o.Spec.Id = orgId
+			o.Spec.DisplayName = fields["displayName"].GetStringValue()
+		case caiFolderType:
+			if state := caiFolderState(fields); state != "" && state != caiActiveState {
+				continue
+			}
+			parent := fields["parent"].GetStringValue()
+			if parent == "" {
+				parent = strings.TrimPrefix(a.GetResource().GetParent(), caiNamePrefix)
+			}
+			fs = append(fs, &caiFolder{
+				name:        strings.TrimPrefix(a.GetName(), caiNamePrefix),
+				parent:      parent,
+				displayName: fields["displayName"].GetStringValue(),
+			})
+		}
+	}
+	if o.Spec.Id == "" {
+		return nil, nil, errOrgNotFound
+	}
+
+	// assign ids and attach children in a deterministic order
+	sort.SliceStable(fs, func(i, j int) bool {
+		if fs[i].displayName != fs[j].displayName {
+			return fs[i].displayName < fs[j].displayName
+		}
+		return fs[i].name < fs[j].name
+	})
+	byName := make(map[string]*caiFolder)
+	seenIds := make(map[string]bool)
+	for _, f := range fs {
+		id := importFolderId(f.displayName)
+		if seenIds[id] { // display names are only unique within the same parent
+			id = fmt.Sprintf("%s_%s", id, strings.TrimPrefix(f.name, "folders/"))
+		}
+		seenIds[id] = true
+		f.id = id
+		byName[f.name] = f
+	}
+	children := make(map[string][]*caiFolder)
+	orgName := caiOrgPrefix + o.Spec.Id
+	for _, f := range fs {
+		if _, ok := byName[f.parent]; !ok && f.parent != orgName {
+			log.Printf("warning: parent %s of %s not found in inventory, skipped\n", f.parent, f.name)
+			continue
+		}
+		children[f.parent] = append(children[f.parent], f)
+	}
+
+	// emit folders parents first so that references read in order
+	var imported folders
+	var importChildren func(parent string, ref referenceYAML)
+	importChildren = func(parent string, ref referenceYAML) {
+		for _, f := range children[parent] {
+			if !validImportedFolderDisplayName(f) {
+				continue
+			}
+			imported = append(imported, &folderYAML{
+				headerYAML: headerYAML{APIVersion: apiCFTv1alpha1, KindStr: Folder.String()},
+				Spec: folderSpecYAML{
+					Id:          f.id,
+					DisplayName: f.displayName,
+					ParentRef:   ref,
+				},
+			})
+			importChildren(f.name, referenceYAML{TargetTypeStr: Folder.String(), TargetId: f.id})
+		}
+	}
+	importChildren(orgName, referenceYAML{TargetTypeStr: Organization.String(), TargetId: o.Spec.Id})
+	return o, imported, nil
+}
+
+// validImportedFolderDisplayName returns true if the display name of the folder has a length
+// launchpad accepts, otherwise logs a warning that the folder and its sub folders are skipped.
+func validImportedFolderDisplayName(f *caiFolder) bool {
+	if len(f.displayName) >= folderNameMin && len(f.displayName) <= folderNameMax {
+		return true
+	}
+	log.Printf("warning: display name %q of %s needs to be between %d and %d characters, skipped %s and its sub folders\n",
+		f.displayName, f.name, folderNameMin, folderNameMax, f.name)
+	return false
+}
+
+// caiFolderState returns the folder state, supporting both v2 and v3 Resource Manager formats.
+func caiFolderState(fields map[string]*structpb.Value) string {
+	if state := fields["lifecycleState"].GetStringValue(); state != "" {
+		return state
+	}
+	return fields["state"].GetStringValue()
+}
+
+// importFolderId converts a folder display name into a launchpad folder id.
+func importFolderId(displayName string) string {
+	id := strings.Trim(importIdInvalidRun.ReplaceAllString(displayName, "_"), "_")
+	if !tfNameRegex.MatchString(id) {
+		id = importedIdPrefix + id
+	}
+	return id
+}
+
+// marshalYAML writes resources as launchpad YAML documents.
+func marshalYAML(rs ...resourceHandler) ([]byte, error) {
+	buff := &bytes.Buffer{}
+	for i, r := range rs {
+		if i > 0 {
+			buff.WriteString(yamlDelimiter)
+		}
+		enc := yaml.NewEncoder(buff)
+		enc.SetIndent(defaultIndentSize)
+		if err := enc.Encode(r); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return buff.Bytes(), nil
+}
@@ -0,0 +1,93 @@
+package launchpad
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/cli/scorecard"
+)
+
+const importTestDataDir = "../testdata/launchpad/import"
+
+func TestImportOrg(t *testing.T) {
+	assets, err := scorecard.GetAssetsFromDir(filepath.Join(importTestDataDir, "cai-dir"))
+	assert.Nil(t, err, "inventory should be readable")
+
+	o, fs, err := importOrg(assets)
+	assert.Nil(t, err, "import should succeed")
+	rs := []resourceHandler{o}
+	for _, f := range fs {
+		rs = append(rs, f)
+	}
+	content, err := marshalYAML(rs...)
+	assert.Nil(t, err, "marshal should succeed")
+
+	outDir := t.TempDir()
+	outFile := filepath.Join(outDir, "org.yaml")
+	assert.Nil(t, os.WriteFile(outFile, content, 0644))
+	expectedDir := filepath.Join(importTestDataDir, "expected")
+	updateExpected(t, expectedDir, outDir)
+	assert.Equal(t, readDir(t, expectedDir), readDir(t, outDir), "imported YAML should match expected")
+
+	// imported YAML should be loadable by launchpad as is
+	resources, errs := collectResources([]string{outFile}, true)
+	assert.Empty(t, errs, "imported YAML should be valid")
+	ao, errs := assembleResources(resources)
+	assert.Empty(t, errs, "imported YAML should be assembled")
+	assert.Equal(t, "56789", ao.org.Spec.Id)
+	assert.Len(t, ao.org.subFolders, 3, "expected top level folders without QA")
+	assert.Len(t, ao.folderIndex(), 5, "expected folders without invalid display names and their sub folders")
+
+	// imported YAML should generate output
+	for _, flavor := range []OutputFlavor{Terraform, DeploymentManager} {
+		t.Run(flavor.String(), func(t *testing.T) {
+			assert.Nil(t, generateOutput(ao, flavor, t.TempDir()), "generation should succeed")
+		})
+	}
+}
+
+func TestImportOrgNotFound(t *testing.T) {
+	assets, err := scorecard.GetAssetsFromDir(filepath.Join(importTestDataDir, "no-org"))
+	assert.Nil(t, err, "inventory should be readable")
+	_, _, err = importOrg(assets)
+	assert.Equal(t, errOrgNotFound, err)
+}
+
+func TestImportFolderId(t *testing.T) {
+	var testCases = []struct {
+		input  string
+		output string
+	}{
+		{"Production", "Production"},
+		{"Non Production", "Non_Production"},
+		{"  Team (A) ", "Team_A"},
+		{"1st Folder", "folder_1st_Folder"},
+		{"***", "folder_"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			assert.Equal(t, tc.output, importFolderId(tc.input))
+		})
+	}
+}
+
+func TestValidImportedFolderDisplayName(t *testing.T) {
+	var testCases = []struct {
+		input  string
+		output bool
+	}{
+		{"Production", true},
+		{"QA", false},
+		{"", false},
+		{"Shared Services for Production Workloads", false},
+		{"Services partagés de production", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			assert.Equal(t, tc.output, validImportedFolderDisplayName(&caiFolder{name: "folders/100", displayName: tc.input}))
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package launchpad
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sort"
+	"strings"
+)
+
+// subnetSpecYAML defines a subnet within a GCP VPC Network.
+type subnetSpecYAML struct {
+	Id          string                 `yaml:"id"`
+	Region      string                 `yaml:"region"`
+	IpCidrRange string                 `yaml:"ipCidrRange"`
+	Undefined   map[string]interface{} `yaml:",inline"` // Catch-all for unintended behavior
+}
+
+// networkSpecYAML defines GCP VPC Network's spec.
+type networkSpecYAML struct {
+	Id          string                 `yaml:"id"`
+	ProjectRef  referenceYAML          `yaml:"projectRef"`
+	Shared      bool                   `yaml:"shared"` // Shared denotes hosting project is a shared VPC host.
+	SubnetSpecs []*subnetSpecYAML      `yaml:"subnets"`
+	Undefined   map[string]interface{} `yaml:",inline"` // Catch-all for unintended behavior
+}
+
+// networks represents a list of networks.
+type networks []*networkYAML
+
+// add appends a network into the network list if it does not exist already.
+//
+// add returns existing network's reference if attempted to add network of the same ID.
+func (ns *networks) add(newN *networkYAML) *networkYAML {
+	for _, n := range *ns {
+		if n.Spec.Id == newN.Spec.Id {
+			return n
+		}
+	}
+	*ns = append(*ns, newN)
+	return nil
+}
+
+func (ns networks) sortedCopy() networks {
+	buff := make(networks, len(ns))
+	copy(buff, ns)
+	sort.SliceStable(buff, func(i, j int) bool { return buff[i].Spec.Id < buff[j].Spec.Id })
+	return buff
+}
+
+// networkYAML is a GCP VPC Network YAML representation.
+type networkYAML struct {
+	headerYAML      `yaml:",inline"`
+	Spec            networkSpecYAML `yaml:"spec"`
+	serviceProjects projects        // serviceProjects is validated projects attached to a shared network.
+}
+
+// resId returns an internal referencable id.
+func (n *networkYAML) resId() string { return fmt.Sprintf("%s.%s", Network, n.Spec.Id) }
+
+// validate ensures input YAML fields are correct.
+func (n *networkYAML) validate() error {
+	if n.Spec.Id == "" {
+		return errMissingRequiredField
+	}
+	if n.Spec.ProjectRef.TargetTypeStr != Project.String() {
+		log.Printf("fatal: unsupported parent '%s' type for Network\n", n.Spec.ProjectRef.TargetTypeStr)
+		return errInvalidParent
+	}
+	if !validGCPName(n.Spec.Id) {
+		log.Printf("GCP Network [%s] ID does not conform to RFC1035 GCP naming standard of at most %d characters", n.Spec.Id, gcpNameMaxLen)
+		return errValidationFailed
+	}
+
+	seen := make(map[string]bool)
+	for _, s := range n.Spec.SubnetSpecs {
+		if s.Id == "" || s.Region == "" || s.IpCidrRange == "" {
+			return errMissingRequiredField
+		}
+		if !validGCPName(s.Id) {
+			log.Printf("GCP Subnet [%s] ID does not conform to RFC1035 GCP naming standard of at most %d characters", s.Id, gcpNameMaxLen)
+			return errValidationFailed
+		}
+		if seen[s.Id] {
+			log.Printf("GCP Subnet [%s] is defined more than once in Network [%s]", s.Id, n.Spec.Id)
+			return errConflictDefinition
+		}
+		seen[s.Id] = true
+		if _, _, err := net.ParseCIDR(s.IpCidrRange); err != nil {
+			log.Printf("GCP Subnet [%s] has invalid CIDR range %s", s.Id, s.IpCidrRange)
+			return errValidationFailed
+		}
+	}
+	return nil
+}
+
+// addToOrg adds the network into the assembled organization.
+func (n *networkYAML) addToOrg(ao *assembledOrg) error {
+	return ao.registerResource(n, &n.Spec.ProjectRef)
+}
+
+// resolveReferences processes references to network.
+//
+// resolveReferences takes reference from project as a service project of this shared network.
+func (n *networkYAML) resolveReferences(refs []resourceHandler) error {
+	for _, ref := range refs {
+		switch r := ref.(type) {
+		case *projectYAML:
+			if !n.Spec.Shared {
+				log.Printf("fatal: %s is not shared, cannot attach %s\n", n.resId(), r.resId())
+				return errInvalidInput
+			}
+			_ = n.serviceProjects.add(r) // silently ignore existing resource
+		default:
+			log.Printf("fatal: invalid %s reference for %s\n", n.resId(), r.resId())
+			return errInvalidInput
+		}
+	}
+	return nil
+}
+
+// dump writes resource's string representation into provided buffer.
+func (n *networkYAML) dump(ind int, buff io.Writer) error {
+	indent := strings.Repeat(" ", ind)
+	shared := ""
+	if n.Spec.Shared {
+		shared = " (shared)"
+	}
+	_, err := fmt.Fprintf(buff, "%s+ %s.%s%s < %s.%s\n", indent, Network, n.Spec.Id, shared,
+		n.Spec.ProjectRef.TargetTypeStr, n.Spec.ProjectRef.TargetId)
+	if err != nil {
+		return err
+	}
+	for _, s := range n.Spec.SubnetSpecs {
+		_, err = fmt.Fprintf(buff, "%s  - subnet %s %s %s\n", indent, s.Id, s.Region, s.IpCidrRange)
+		if err != nil {
+			return err
+		}
+	}
+	for _, p := range n.serviceProjects.sortedCopy() {
+		_, err = fmt.Fprintf(buff, "%s  - service %s\n", indent, p.resId())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package launchpad
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetworkYAML_validate(t *testing.T) {
+	projectRef := referenceYAML{Project.String(), "project-1"}
+	var testCases = []struct {
+		name    string
+		subnets []*subnetSpecYAML
+		output  error
+	}{
+		{"no_subnet", nil, nil},
+		{"valid_subnets", []*subnetSpecYAML{
+			{Id: "s1", Region: "us-central1", IpCidrRange: "10.0.0.0/24"},
+			{Id: "s2", Region: "us-east1", IpCidrRange: "10.0.1.0/24"},
+		}, nil},
+		{"missing_region", []*subnetSpecYAML{{Id: "s1", IpCidrRange: "10.0.0.0/24"}}, errMissingRequiredField},
+		{"invalid_cidr", []*subnetSpecYAML{{Id: "s1", Region: "us-central1", IpCidrRange: "10.0.0.0"}}, errValidationFailed},
+		{"invalid_subnet_name", []*subnetSpecYAML{{Id: "subnet_1", Region: "us-central1", IpCidrRange: "10.0.0.0/24"}}, errValidationFailed},
+		{"duplicated_subnet", []*subnetSpecYAML{
+			{Id: "s1", Region: "us-central1", IpCidrRange: "10.0.0.0/24"},
+			{Id: "s1", Region: "us-east1", IpCidrRange: "10.0.1.0/24"},
+		}, errConflictDefinition},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			n := &networkYAML{Spec: networkSpecYAML{Id: "net1", ProjectRef: projectRef, SubnetSpecs: tc.subnets}}
+			assert.Equal(t, tc.output, n.validate())
+		})
+	}
+
+	missingId := &networkYAML{Spec: networkSpecYAML{ProjectRef: projectRef}}
+	assert.Equal(t, errMissingRequiredField, missingId.validate())
+	for _, id := range []string{"shared_vpc", "Vpc", "vpc-", "1vpc", strings.Repeat("v", 64)} {
+		invalidName := &networkYAML{Spec: networkSpecYAML{Id: id, ProjectRef: projectRef}}
+		assert.Equal(t, errValidationFailed, invalidName.validate(), id)
+	}
+	maxLenName := &networkYAML{Spec: networkSpecYAML{Id: strings.Repeat("v", 63), ProjectRef: projectRef}}
+	assert.Nil(t, maxLenName.validate())
+	invalidParent := &networkYAML{Spec: networkSpecYAML{Id: "net1", ProjectRef: referenceYAML{Folder.String(), "f1"}}}
+	assert.Equal(t, errInvalidParent, invalidParent.validate())
+}
+
+func TestNetworkYAML_resolveReferences(t *testing.T) {
+	p := &projectYAML{Spec: projectSpecYAML{Id: "project-1"}}
+
+	shared := &networkYAML{Spec: networkSpecYAML{Id: "net1", Shared: true}}
+	assert.Nil(t, shared.resolveReferences([]resourceHandler{p, p}))
+	assert.Equal(t, projects{p}, shared.serviceProjects, "service project should be added once")
+
+	notShared := &networkYAML{Spec: networkSpecYAML{Id: "net2"}}
+	assert.Equal(t, errInvalidInput, notShared.resolveReferences([]resourceHandler{p}))
+}
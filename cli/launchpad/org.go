@@ -9,16 +9,19 @@ import (
 
 // orgSpecYAML defines an Organization's Spec.
 type orgSpecYAML struct {
-	Id             string            `yaml:"id"`          // GCP organization id.
-	DisplayName    string            `yaml:"displayName"` // Optional field to denote GCP organization name.
-	SubFolderSpecs []*folderSpecYAML `yaml:"folders"`
+	Id             string                 `yaml:"id"`                    // GCP organization id.
+	DisplayName    string                 `yaml:"displayName,omitempty"` // Optional field to denote GCP organization name.
+	SubFolderSpecs []*folderSpecYAML      `yaml:"folders,omitempty"`
+	Undefined      map[string]interface{} `yaml:",inline"` // Catch-all for unintended behavior
 }
 
 // orgYAML represents a GCP organization.
 type orgYAML struct {
-	headerYAML `yaml:",inline"`
-	Spec       orgSpecYAML `yaml:"spec"`
-	subFolders folders     // subFolder represents validated sub directories.
+	headerYAML  `yaml:",inline"`
+	Spec        orgSpecYAML `yaml:"spec"`
+	subFolders  folders     // subFolder represents validated sub directories.
+	projects    projects    // projects represents validated projects directly under the organization.
+	iamPolicies iamPolicies // iamPolicies represents validated IAM policies applied on the organization.
 }
 
 // resId returns an internal referencable id.
@@ -34,6 +37,7 @@ func (o *orgYAML) validate() error {
 
 	o.subFolders = newSubFoldersBySpecs(o.Spec.SubFolderSpecs, Organization, o.Spec.Id)
 	for _, f := range o.subFolders {
+		f.pos = o.pos // nested folders are defined in the same document
 		if err := f.validate(); err != nil {
 			return err
 		}
@@ -52,7 +56,8 @@ func (o *orgYAML) addToOrg(ao *assembledOrg) error {
 	}
 	ao.org = *o // replace finalized org as the current org.
 
-	if err := ao.registerResource(o, nil); err != nil {
+	// register finalized org so references are resolved onto it
+	if err := ao.registerResource(&ao.org, nil); err != nil {
 		return err
 	}
 
@@ -66,12 +71,18 @@ func (o *orgYAML) addToOrg(ao *assembledOrg) error {
 
 // resolveReferences processes references to organization.
 //
-// resolveReferences takes reference from folder as a subFolder of this organization.
+// resolveReferences takes reference from folder as a subFolder of this organization,
+// reference from project as a project under this organization, and reference from
+// IAM policy as a policy applied on this organization.
 func (o *orgYAML) resolveReferences(refs []resourceHandler) error {
 	for _, ref := range refs {
 		switch r := ref.(type) {
 		case *folderYAML:
 			_ = o.subFolders.add(r) // silently ignore existing resource
+		case *projectYAML:
+			_ = o.projects.add(r) // silently ignore existing resource
+		case *iamPolicyYAML:
+			_ = o.iamPolicies.add(r) // silently ignore existing resource
 		default:
 			return errors.New("unable to process reference from resource")
 		}
@@ -112,5 +123,10 @@ func (o *orgYAML) dump(ind int, buff io.Writer) error {
 			return err
 		}
 	}
-	return nil
+	for _, p := range o.projects.sortedCopy() {
+		if err = p.dump(ind+defaultIndentSize, buff); err != nil {
+			return err
+		}
+	}
+	return o.iamPolicies.dump(ind+defaultIndentSize, buff)
 }
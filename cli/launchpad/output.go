@@ -0,0 +1,99 @@
+// Package launchpad file output.go contains shared support for rendering an
+// assembled organization into output flavors.
+package launchpad
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// supportedFlavor defines renderer for each output flavor.
+var supportedFlavor = map[OutputFlavor]func() outputRenderer{
+	DeploymentManager: func() outputRenderer { return &dmRenderer{} },
+	Terraform:         func() outputRenderer { return &tfRenderer{} },
+}
+
+// outputRenderer represents an output flavor that can be generated by launchpad.
+//
+// All flavors are rendered from the same assembledOrg, a new flavor can be plugged
+// in by implementing outputRenderer and registering it in supportedFlavor.
+type outputRenderer interface {
+	// render writes the assembled organization into outputDir.
+	render(ao *assembledOrg, outputDir string) error
+}
+
+// outputFile maps a generated file name to the template rendering it.
+type outputFile struct {
+	name string
+	tmpl string
+	raw  bool // raw denotes the template is copied as is without rendering.
+}
+
+// tmplFuncs defines helper functions available to output templates.
+var tmplFuncs = template.FuncMap{
+	"hclString":  hclString,
+	"yamlString": yamlString,
+	"pad":        func(s string, n int) string { return fmt.Sprintf("%-*s", n, s) },
+}
+
+// writeOutputFiles renders given files under tmplDir with data into dir.
+//
+// Each file is prefixed by the license header rendered from licenseTmpl, files with
+// empty rendered content are skipped.
+func writeOutputFiles(dir, tmplDir, licenseTmpl string, files []outputFile, data interface{}) error {
+	license, err := renderTemplates(data, path.Join(tmplDir, licenseTmpl))
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		var content []byte
+		if f.raw {
+			c, err := loadFile(path.Join(tmplDir, f.tmpl))
+			if err != nil {
+				return err
+			}
+			content = []byte(c)
+		} else if content, err = renderTemplates(data, path.Join(tmplDir, f.tmpl)); err != nil {
+			return err
+		}
+		if len(strings.TrimSpace(string(content))) == 0 {
+			continue
+		}
+		fp := filepath.Join(dir, filepath.FromSlash(f.name))
+		if err = os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+			return err
+		}
+		content = append(append([]byte{}, license...), content...)
+		if err = os.WriteFile(fp, content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderTemplates executes templates loaded from tmplPaths with data in order and
+// returns the concatenated output.
+func renderTemplates(data interface{}, tmplPaths ...string) ([]byte, error) {
+	buff := &bytes.Buffer{}
+	for _, tp := range tmplPaths {
+		content, err := loadFile(tp)
+		if err != nil {
+			return nil, err
+		}
+		tmpl, err := template.New(path.Base(tp)).Funcs(tmplFuncs).Parse(content)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to parse template %s", tp)
+		}
+		if err = tmpl.Execute(buff, data); err != nil {
+			return nil, errors.Wrapf(err, "unable to render template %s", tp)
+		}
+	}
+	return buff.Bytes(), nil
+}
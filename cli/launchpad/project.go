@@ -0,0 +1,140 @@
+package launchpad
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	projectNameMin = 4
+	projectNameMax = 30
+)
+
+// projectIdRegex follows GCP project ID requirement of 6 to 30 lowercase letters, digits, or hyphens.
+var projectIdRegex = regexp.MustCompile(`^[a-z][a-z\d\-]{4,28}[a-z\d]$`)
+
+// projectSpecYAML defines GCP Project's spec.
+type projectSpecYAML struct {
+	Id               string                 `yaml:"id"`
+	DisplayName      string                 `yaml:"displayName"`
+	BillingAccount   string                 `yaml:"billingAccount"`
+	Services         []string               `yaml:"services"`
+	ParentRef        referenceYAML          `yaml:"parentRef"`
+	SharedNetworkRef *referenceYAML         `yaml:"sharedNetworkRef"` // Optional shared VPC to attach as service project.
+	Undefined        map[string]interface{} `yaml:",inline"`          // Catch-all for unintended behavior
+}
+
+// projects represents a list of projects.
+type projects []*projectYAML
+
+// add appends a project into the project list if it does not exist already.
+//
+// add returns existing project's reference if attempted to add project of the same ID.
+func (ps *projects) add(newP *projectYAML) *projectYAML {
+	for _, p := range *ps {
+		if p.Spec.Id == newP.Spec.Id {
+			return p
+		}
+	}
+	*ps = append(*ps, newP)
+	return nil
+}
+
+func (ps projects) sortedCopy() projects {
+	buff := make(projects, len(ps))
+	copy(buff, ps)
+	sort.SliceStable(buff, func(i, j int) bool { return buff[i].Spec.Id < buff[j].Spec.Id })
+	return buff
+}
+
+// projectYAML is a GCP Project YAML representation.
+type projectYAML struct {
+	headerYAML  `yaml:",inline"`
+	Spec        projectSpecYAML `yaml:"spec"`
+	networks    networks        // networks is validated networks hosted in this project.
+	iamPolicies iamPolicies     // iamPolicies is validated IAM policies applied on this project.
+}
+
+// resId returns an internal referencable id.
+func (p *projectYAML) resId() string { return fmt.Sprintf("%s.%s", Project, p.Spec.Id) }
+
+// validate ensures input YAML fields are correct.
+func (p *projectYAML) validate() error {
+	if p.Spec.Id == "" {
+		return errMissingRequiredField
+	}
+	switch p.Spec.ParentRef.TargetTypeStr { // Validate Supported Parents
+	case Organization.String(), Folder.String():
+	default:
+		log.Printf("fatal: unsupported parent '%s' type for Project\n", p.Spec.ParentRef.TargetTypeStr)
+		return errInvalidParent
+	}
+
+	if !projectIdRegex.MatchString(p.Spec.Id) {
+		log.Printf("GCP Project [%s] ID does not conform to GCP project ID standard", p.Spec.Id)
+		return errValidationFailed
+	}
+
+	if len(p.Spec.DisplayName) < projectNameMin || len(p.Spec.DisplayName) > projectNameMax {
+		log.Printf("GCP Project Name [%s] needs to be between %d and %d", p.Spec.DisplayName, projectNameMin, projectNameMax)
+		return errValidationFailed
+	}
+
+	if p.Spec.SharedNetworkRef != nil && p.Spec.SharedNetworkRef.TargetTypeStr != Network.String() {
+		log.Printf("fatal: unsupported shared network '%s' type for Project\n", p.Spec.SharedNetworkRef.TargetTypeStr)
+		return errInvalidInput
+	}
+	return nil
+}
+
+// addToOrg adds the project into the assembled organization.
+//
+// addToOrg also registers the project as a reference to its shared network if specified.
+func (p *projectYAML) addToOrg(ao *assembledOrg) error {
+	if err := ao.registerResource(p, &p.Spec.ParentRef); err != nil {
+		return err
+	}
+	if p.Spec.SharedNetworkRef == nil {
+		return nil
+	}
+	return ao.registerResource(p, p.Spec.SharedNetworkRef)
+}
+
+// resolveReferences processes references to project.
+//
+// resolveReferences takes reference from network as a hosted network, and reference from
+// IAM policy as a policy applied on this project.
+func (p *projectYAML) resolveReferences(refs []resourceHandler) error {
+	for _, ref := range refs {
+		switch r := ref.(type) {
+		case *networkYAML:
+			_ = p.networks.add(r) // silently ignore existing resource
+		case *iamPolicyYAML:
+			_ = p.iamPolicies.add(r) // silently ignore existing resource
+		default:
+			log.Printf("fatal: invalid %s reference for %s\n", p.resId(), r.resId())
+			return errInvalidInput
+		}
+	}
+	return nil
+}
+
+// dump writes resource's string representation into provided buffer.
+func (p *projectYAML) dump(ind int, buff io.Writer) error {
+	indent := strings.Repeat(" ", ind)
+	_, err := fmt.Fprintf(buff, "%s+ %s.%s (\"%s\") < %s.%s\n", indent, Project, p.Spec.Id,
+		p.Spec.DisplayName, p.Spec.ParentRef.TargetTypeStr, p.Spec.ParentRef.TargetId)
+	if err != nil {
+		return err
+	}
+	for _, n := range p.networks.sortedCopy() {
+		if err = n.dump(ind+defaultIndentSize, buff); err != nil {
+			return err
+		}
+	}
+	return p.iamPolicies.dump(ind+defaultIndentSize, buff)
+}
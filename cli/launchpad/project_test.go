@@ -0,0 +1,93 @@
+package launchpad
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectYAML_validate(t *testing.T) {
+	orgRef := referenceYAML{Organization.String(), "12345"}
+	var testCases = []struct {
+		name   string
+		input  *projectYAML
+		output error
+	}{
+		{"missing_id", &projectYAML{Spec: projectSpecYAML{Id: ""}}, errMissingRequiredField},
+		{"no_parents", &projectYAML{Spec: projectSpecYAML{Id: "project-1"}}, errInvalidParent},
+		{
+			"invalid_parent",
+			&projectYAML{Spec: projectSpecYAML{Id: "project-1", ParentRef: referenceYAML{Project.String(), "dummy"}}},
+			errInvalidParent,
+		},
+		{"invalid_id", &projectYAML{Spec: projectSpecYAML{Id: "Project_1", DisplayName: "project", ParentRef: orgRef}}, errValidationFailed},
+		{"projectName_too_short", &projectYAML{Spec: projectSpecYAML{Id: "project-1", DisplayName: "pro", ParentRef: orgRef}}, errValidationFailed},
+		{
+			"invalid_shared_network",
+			&projectYAML{Spec: projectSpecYAML{
+				Id: "project-1", DisplayName: "project", ParentRef: orgRef,
+				SharedNetworkRef: &referenceYAML{Folder.String(), "dummy"},
+			}},
+			errInvalidInput,
+		},
+		{"valid", &projectYAML{Spec: projectSpecYAML{Id: "project-1", DisplayName: "project", ParentRef: orgRef}}, nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.input.validate()
+			assert.Equal(t, tc.output, err)
+		})
+	}
+}
+
+func TestProjectYAML_resolveReferences(t *testing.T) {
+	p := &projectYAML{Spec: projectSpecYAML{Id: "project-1"}}
+	n := &networkYAML{Spec: networkSpecYAML{Id: "net1"}}
+	ip := &iamPolicyYAML{Spec: iamPolicySpecYAML{Id: "policy1"}}
+
+	err := p.resolveReferences([]resourceHandler{n, ip, n})
+	assert.Nil(t, err, "resolve reference should pass")
+	assert.Equal(t, networks{n}, p.networks, "network should be added once")
+	assert.Equal(t, iamPolicies{ip}, p.iamPolicies, "IAM policy should be added once")
+
+	f := &folderYAML{Spec: folderSpecYAML{Id: "f1"}}
+	err = p.resolveReferences([]resourceHandler{f})
+	assert.Equal(t, errInvalidInput, err, "impossible reference")
+}
+
+func TestProjectYAMLIntegration(t *testing.T) {
+	resources := loadResources([]string{
+		"../testdata/launchpad/project/org_1_projects.yaml",
+		"../testdata/launchpad/project/iam_1.yaml",
+	})
+	assert.Len(t, resources, 7, "all documents should be loaded")
+	ao := assembleResourcesToOrg(resources)
+
+	assert.Equal(t, []string{"service-project-1"}, projectIds(ao.org.projects))
+	assert.Len(t, ao.org.iamPolicies, 1, "org should have a policy")
+	assert.Len(t, ao.org.subFolders, 1, "org should have a folder")
+
+	group1 := ao.org.subFolders[0]
+	assert.Equal(t, []string{"host-project-1"}, projectIds(group1.projects))
+	assert.Len(t, group1.iamPolicies, 1, "folder should have a policy")
+
+	host := group1.projects[0]
+	assert.Len(t, host.iamPolicies, 1, "host project should have a policy")
+	assert.Len(t, host.networks, 1, "host project should have a network")
+	assert.Equal(t, []string{"service-project-1"}, projectIds(host.networks[0].serviceProjects))
+}
+
+func TestProjectYAMLIntegrationNetworkNotShared(t *testing.T) {
+	resources := loadResources([]string{"../testdata/launchpad/project/network_not_shared.yaml"})
+	assert.Panics(t, func() {
+		assembleResourcesToOrg(resources)
+	}, "panic was expected for attaching to non shared network")
+}
+
+func projectIds(ps projects) []string {
+	var ids []string
+	for _, p := range ps {
+		ids = append(ids, p.Spec.Id)
+	}
+	return ids
+}
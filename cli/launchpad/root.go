@@ -11,6 +11,8 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+//go:generate go run gen_statics.go
+
 // NewGenerate takes file patterns as input YAMLs and output Infrastructure as
 // Code ready scripts based on specified output flavor.
 //
@@ -18,16 +20,41 @@ import (
 //
 //	$ cft launchpad generate *.yaml
 //	$ cft lp g *.yaml
-func NewGenerate(rawPaths []string, outFlavor OutputFlavor, outputDir string) {
-	// attempt to load all configs with best effort
-	log.Println("debug: output location", outputDir) // Remove after generate code is written
-	log.Println("debug: output flavor", outFlavor)   // Remove after generate code is written
-	resources := loadResources(rawPaths)
-	log.Println(len(resources), "YAML documents loaded")
+//
+// In strict mode, every load, parse, validation and reference error is reported together
+// and the application terminates without generating output if any error occurred.
+func NewGenerate(rawPaths []string, outFlavor OutputFlavor, outputDir string, strict bool) {
+	var assembled *assembledOrg
+	if strict {
+		resources, errs := collectResources(rawPaths, true)
+		log.Println(len(resources), "YAML documents loaded")
+		var assembleErrs loadErrors
+		assembled, assembleErrs = assembleResources(resources)
+		errs = append(errs, assembleErrs...)
+		if len(errs) > 0 {
+			fmt.Fprintln(os.Stderr, errs.Error())
+			log.Fatalf("%d error(s) found in strict mode", len(errs))
+		}
+	} else {
+		// attempt to load all configs with best effort
+		resources := loadResources(rawPaths)
+		log.Println(len(resources), "YAML documents loaded")
+		assembled = assembleResourcesToOrg(resources)
+	}
 
-	assembled := assembleResourcesToOrg(resources)
+	if err := generateOutput(assembled, outFlavor, outputDir); err != nil {
+		log.Fatalln("Unable to generate output:", err.Error())
+	}
+	log.Printf("%s output generated in %s\n", outFlavor, outputDir)
+}
 
-	print(assembled.String()) // Place-holder for future trigger point of code generation
+// generateOutput writes the assembled organization into outputDir in the given flavor.
+func generateOutput(ao *assembledOrg, outFlavor OutputFlavor, outputDir string) error {
+	rendererFunc, ok := supportedFlavor[outFlavor]
+	if !ok {
+		return fmt.Errorf("output flavor %s not yet supported", outFlavor)
+	}
+	return rendererFunc().render(ao, outputDir)
 }
 
 // OutputFlavor defines launchpad's generated output language.
@@ -49,7 +76,6 @@ func (f OutputFlavor) String() string {
 func NewOutputFlavor(fStr string) OutputFlavor {
 	switch strings.ToLower(fStr) {
 	case "deploymentmanager", "dm":
-		log.Println("Warning: Deployment Manager format not yet supported")
 		return DeploymentManager
 	case "terraform", "tf":
 		return Terraform
@@ -63,39 +89,69 @@ const yamlDelimiter = "---\n"
 
 // loadResources attempts to load YAMLs from all given path patterns in best effort.
 //
-// loadResources will silently ignore file I/O related errors, attempt to parse all
-// files and extract resources if possible.
+// loadResources will log and skip file I/O, parse and validation errors, attempt to
+// parse all files and extract resources if possible.
 func loadResources(rawPaths []string) []resourceHandler {
+	resources, errs := collectResources(rawPaths, false)
+	for _, err := range errs {
+		log.Println("warning:", err.Error())
+	}
+	return resources
+}
+
+// collectResources loads YAMLs from all given path patterns and collects every error
+// encountered along with the file path, document index and line it occurred.
+//
+// In strict mode, path patterns matching no file and unknown YAML fields are also
+// treated as errors.
+func collectResources(rawPaths []string, strict bool) ([]resourceHandler, loadErrors) {
 	var buff []resourceHandler
+	var errs loadErrors
 	for _, pathPattern := range rawPaths {
 		matches, err := filepath.Glob(pathPattern)
 		if err != nil {
-			log.Println("warning: Invalid file path pattern", pathPattern)
+			errs = append(errs, &loadError{pos: sourcePos{path: pathPattern}, err: err, detail: "invalid file path pattern"})
+			continue
+		}
+		if len(matches) == 0 && strict {
+			errs = append(errs, &loadError{pos: sourcePos{path: pathPattern}, err: os.ErrNotExist, detail: "no file matched"})
 			continue
 		}
 		for _, fp := range matches {
 			content, err := loadFile(fp)
 			if err != nil {
-				log.Println("warning: Unable to load requested file", fp)
+				errs = append(errs, &loadError{pos: sourcePos{path: fp}, err: err, detail: "unable to load requested file"})
 				continue
 			}
 			// Multiple YAML doc can exist within one file
 			docStrs := strings.Split(content, yamlDelimiter)
-			for _, docStr := range docStrs {
+			line := 1
+			for i, docStr := range docStrs {
+				pos := sourcePos{path: fp, docIdx: i + 1, line: line}
+				line += strings.Count(docStr, "\n") + 1 // account for the delimiter line
+				if strings.TrimSpace(docStr) == "" {
+					continue
+				}
 				resource, err := loadYAML([]byte(docStr))
 				if err != nil {
-					log.Printf("warning: unable to parse YAML [%s]:\n%s", err.Error(), docStr)
+					errs = append(errs, &loadError{pos: pos.withYAMLErrLine(err), err: err, detail: "unable to parse YAML"})
 					continue
 				}
+				*resource.source() = pos
 				if err = resource.validate(); err != nil {
-					log.Printf("warning: YAML validation failed [%s]:\n%s", err.Error(), docStr)
+					errs = append(errs, &loadError{pos: pos, err: err, detail: resource.resId()})
+					continue
+				}
+				if fields := unknownFields(resource); strict && len(fields) > 0 {
+					errs = append(errs, &loadError{pos: pos, err: errUnknownField,
+						detail: fmt.Sprintf("%s has unknown fields %s", resource.resId(), strings.Join(fields, ", "))})
 					continue
 				}
 				buff = append(buff, resource)
 			}
 		}
 	}
-	return buff
+	return buff, errs
 }
 
 // loadFile return the file content with the specified relative path to current location.
@@ -134,18 +190,18 @@ func loadYAML(docStr []byte) (resourceHandler, error) {
 	kinds, ok := supportedVersion[h.APIVersion]
 	if !ok {
 		log.Printf("Not supported version")
-		return nil, errors.New("unsupported version")
+		return nil, errors.Errorf("unsupported version %q", h.APIVersion)
 	}
 	resourceFunc, ok := kinds[h.kind()]
 	if !ok {
 		log.Printf("Not supported kind")
-		return nil, errors.New("unsupported custom resource kind for the version")
+		return nil, errors.Errorf("unsupported custom resource kind %q for version %s", h.KindStr, h.APIVersion)
 	}
 	resource := resourceFunc()
 	err = yaml.Unmarshal(docStr, resource)
 	if err != nil {
 		log.Printf("Malformed YAML")
-		return nil, errors.New("unsupported custom resource kind")
+		return nil, err
 	}
 	return resource, nil
 }
@@ -0,0 +1,75 @@
+package launchpad
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectResources(t *testing.T) {
+	var testCases = []struct {
+		name          string
+		strict        bool
+		expectedCount int
+		expectedErrs  []string
+	}{{
+		"best_effort",
+		false,
+		2,
+		[]string{
+			`../testdata/launchpad/strict/errors_1.yaml:12 (document 2): unable to parse YAML: unsupported custom resource kind "Folders" for version cft.dev/v1alpha1`,
+			"../testdata/launchpad/strict/errors_1.yaml:17 (document 3): Folder.g3: validation failed",
+			"../testdata/launchpad/strict/errors_2.yaml:6 (document 1): unable to parse YAML: yaml: unmarshal errors:\n  line 6: cannot unmarshal !!str `not a list` into []*launchpad.folderSpecYAML",
+		},
+	}, {
+		"strict",
+		true,
+		1,
+		[]string{
+			"../testdata/launchpad/strict/missing/*.yaml: no file matched: file does not exist",
+			"../testdata/launchpad/strict/errors_1.yaml:1 (document 1): Organization.12345678 has unknown fields spec.folders[group1].parentref: unknown field",
+			`../testdata/launchpad/strict/errors_1.yaml:12 (document 2): unable to parse YAML: unsupported custom resource kind "Folders" for version cft.dev/v1alpha1`,
+			"../testdata/launchpad/strict/errors_1.yaml:17 (document 3): Folder.g3: validation failed",
+			"../testdata/launchpad/strict/errors_2.yaml:6 (document 1): unable to parse YAML: yaml: unmarshal errors:\n  line 6: cannot unmarshal !!str `not a list` into []*launchpad.folderSpecYAML",
+		},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resources, errs := collectResources([]string{
+				"../testdata/launchpad/strict/missing/*.yaml",
+				"../testdata/launchpad/strict/*.yaml",
+			}, tc.strict)
+			assert.Len(t, resources, tc.expectedCount)
+			var msgs []string
+			for _, err := range errs {
+				msgs = append(msgs, err.Error())
+			}
+			assert.Equal(t, tc.expectedErrs, msgs)
+		})
+	}
+}
+
+func TestAssembleResources(t *testing.T) {
+	resources, errs := collectResources([]string{"../testdata/launchpad/strict/*.yaml"}, true)
+	assert.Len(t, errs, 4, "load errors are expected")
+
+	_, errs = assembleResources(resources)
+	assert.Len(t, errs, 1, "undefined reference is expected")
+	assert.True(t, errors.Is(errs[0], errUndefinedReference))
+	assert.Equal(t, "../testdata/launchpad/strict/errors_2.yaml:8 (document 2): Folder.group5 references Folder.group404: undefined reference", errs[0].Error())
+}
+
+func TestLoadErrors(t *testing.T) {
+	errs := loadErrors{
+		{pos: sourcePos{path: "a.yaml", docIdx: 2, line: 10}, err: errValidationFailed, detail: "Folder.f1"},
+		{pos: sourcePos{path: "b.yaml"}, err: os.ErrNotExist},
+		{err: errUndefinedReference},
+	}
+	expected := "a.yaml:10 (document 2): Folder.f1: validation failed\n" +
+		"b.yaml: file does not exist\n" +
+		"<unknown>: undefined reference"
+	assert.Equal(t, expected, errs.Error())
+}
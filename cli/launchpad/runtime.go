@@ -34,6 +34,10 @@ func (rm resourceMap) getInit(rId string, yaml resourceHandler) (*resource, erro
 	if yaml == nil {
 		return res, nil
 	}
+	if res.yaml == nil { // resource was initialized via inbound reference, pending definition
+		res.yaml = yaml
+		return res, nil
+	}
 	if res.yaml.kind() == Organization {
 		// newer organization definition, pull sub-resources into current
 		o, ok := res.yaml.(*orgYAML)
@@ -90,23 +94,35 @@ func (ao *assembledOrg) String() string {
 }
 
 // assembleResourcesToOrg takes in resources and assembles into an organization.
+//
+// assembleResourcesToOrg panics on the first error encountered, see assembleResources
+// to collect all errors instead.
 func assembleResourcesToOrg(rs []resourceHandler) *assembledOrg {
+	ao, errs := assembleResources(rs)
+	if len(errs) > 0 {
+		log.Println("unable to assemble YAML resources:", errs[0].Error())
+		panic(errs[0].Error())
+	}
+	return ao
+}
+
+// assembleResources takes in resources and assembles into an organization.
+//
+// assembleResources collects errors from all resources along with where the offending
+// resource is defined.
+func assembleResources(rs []resourceHandler) (*assembledOrg, loadErrors) {
 	ao := newAssembledOrg()
+	var errs loadErrors
 
 	// discover resources in a DFS style
 	// initialize resource into resourceMap or update references if already exist.
 	for _, r := range rs {
 		if err := r.addToOrg(ao); err != nil {
-			log.Println("error validating YAML", err.Error())
-			panic(err.Error())
+			errs = append(errs, &loadError{pos: *r.source(), err: err, detail: r.resId()})
 		}
 	}
 	// assemble each discovered resource onto a finalized org
-	if err := ao.resolveReferences(); err != nil {
-		log.Println("unable to resolve referenceTracker between YAML resources:", err.Error())
-		panic(err.Error())
-	}
-	return ao
+	return ao, append(errs, ao.resolveAllReferences()...)
 }
 
 // registerResource registers a resource into resourceMap for later resolution.
@@ -147,20 +163,40 @@ func (ao *assembledOrg) registerResource(src resourceHandler, dst *referenceYAML
 }
 
 // resolveReferences loops through resourceMap to link up resource to sub resources.
+//
+// resolveReferences returns the first error encountered, see resolveAllReferences to
+// collect all errors instead.
 func (ao *assembledOrg) resolveReferences() error {
-	for resId, res := range ao.resourceMap {
+	if errs := ao.resolveAllReferences(); len(errs) > 0 {
+		return errs[0].err
+	}
+	return nil
+}
+
+// resolveAllReferences loops through resourceMap to link up resource to sub resources.
+//
+// resolveAllReferences collects errors from all resources, an undefined reference is
+// reported at each resource referencing it.
+func (ao *assembledOrg) resolveAllReferences() loadErrors {
+	var errs loadErrors
+	for _, resId := range ao.resourceMap.sortedResId() {
+		res := ao.resourceMap[resId]
 		if res.yaml == nil {
 			// an item is initialized but the resourceHandler never provided
 			// only happen when this item is initialized via inbound reference(s)
 			log.Printf("fatal: reference to %s was not found\n", resId)
-			return errUndefinedReference
+			for _, ref := range res.inRefs {
+				errs = append(errs, &loadError{pos: *ref.source(), err: errUndefinedReference,
+					detail: fmt.Sprintf("%s references %s", ref.resId(), resId)})
+			}
+			continue
 		}
 		// each resource holds its own resolving logic
 		if err := res.yaml.resolveReferences(res.inRefs); err != nil {
-			return err
+			errs = append(errs, &loadError{pos: *res.yaml.source(), err: err, detail: resId})
 		}
 	}
-	return nil
+	return errs
 }
 
 // dump writes resource's string representation into provided buffer.
@@ -1,6 +1,7 @@
 package launchpad
 
 import (
+	"io"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -16,6 +17,7 @@ func (d *dummyResource) validate() error                                { return
 func (d *dummyResource) kind() crdKind                                  { return crdKind(0) }
 func (d *dummyResource) addToOrg(ao *assembledOrg) error                { return nil }
 func (d *dummyResource) resolveReferences(refs []resourceHandler) error { return nil }
+func (d *dummyResource) dump(ind int, buff io.Writer) error             { return nil }
 
 type registerResourceArg struct {
 	src resourceHandler
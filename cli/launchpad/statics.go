@@ -3,6 +3,125 @@ package launchpad
 // WARNING: Generated file, do not modify directly!
 
 var statics = map[string]string{
+	"static/tmpl/dm/config.yaml.tmpl": `# Organization: {{.Org.Spec.Id}}{{with .Org.Spec.DisplayName}} ({{.}}){{end}}
+
+imports:
+- path: templates/folder.jinja
+  name: folder.jinja
+
+resources:
+{{- range .Folders}}
+- name: {{.Id}}
+  type: folder.jinja
+  properties:
+    parent: {{yamlString .Parent}}
+    displayName: {{yamlString .DisplayName}}
+{{- else}} []
+{{- end}}
+`,
+	"static/tmpl/dm/folder.jinja": `{#
+Creates a folder under the given parent, parent is either organizations/{org_id}
+or folders/{folder_id}.
+#}
+
+resources:
+- name: {{ env['name'] }}-folder
+  type: gcp-types/cloudresourcemanager-v2:folders
+  properties:
+    parent: {{ properties['parent'] }}
+    displayName: {{ properties['displayName'] }}
+
+outputs:
+- name: name
+  value: $(ref.{{ env['name'] }}-folder.name)
+`,
+	"static/tmpl/dm/folder.jinja.schema": `info:
+  title: Folder
+  description: Creates a folder under an organization or another folder.
+
+required:
+- parent
+- displayName
+
+properties:
+  parent:
+    type: string
+    description: Parent in the form of organizations/{org_id} or folders/{folder_id}.
+  displayName:
+    type: string
+    description: Display name of the folder.
+
+outputs:
+  name:
+    type: string
+    description: Resource name of the folder in the form of folders/{folder_id}.
+`,
+	"static/tmpl/dm/license.yaml.tmpl": `# Copyright 2019 Google LLC
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+`,
+	"static/tmpl/tf/backend.tf.tmpl": `# Uncomment and update the bucket to store Terraform state remotely.
+# terraform {
+#   backend "gcs" {
+#     bucket = "UPDATE_ME"
+#     prefix = "launchpad"
+#   }
+# }
+`,
+	"static/tmpl/tf/folder_main.tf.tmpl": `resource "google_folder" "{{.Folder.Spec.Id}}" {
+  display_name = {{hclString .Folder.Spec.DisplayName}}
+  parent       = var.parent
+}
+{{- range .SubFolders}}
+
+module "{{.Spec.Id}}" {
+  source = "./{{.Spec.Id}}"
+  parent = google_folder.{{$.Folder.Spec.Id}}.name
+{{- with index $.ModuleDependsOn .Spec.Id}}
+
+  depends_on = [
+{{- range .}}
+    {{.}},
+{{- end}}
+  ]
+{{- end}}
+}
+{{- end}}
+`,
+	"static/tmpl/tf/folder_outputs.tf.tmpl": `output "name" {
+  description = "Resource name of the folder in the form of folders/{folder_id}."
+  value       = google_folder.{{.Folder.Spec.Id}}.name
+}
+`,
+	"static/tmpl/tf/folder_variables.tf.tmpl": `variable "parent" {
+  description = "Parent of the folder in the form of organizations/{org_id} or folders/{folder_id}."
+  type        = string
+}
+`,
+	"static/tmpl/tf/iam.tf.tmpl": `{{- range $i, $b := .IAMBindings}}
+{{- if $i}}{{"\n\n"}}{{end -}}
+resource "google_{{$b.ResourceType}}_iam_binding" "{{$b.Name}}" {
+  {{pad $b.TargetKey 7}} = {{$b.TargetValue}}
+  role    = {{hclString $b.Role}}
+  members = [
+{{- range $b.Members}}
+    {{hclString .}},
+{{- end}}
+  ]
+}
+{{- end}}
+`,
 	"static/tmpl/tf/license.tf.tmpl": `/**
  * Copyright 2019 Google LLC
  *
@@ -19,5 +138,100 @@ var statics = map[string]string{
  * limitations under the License.
  */
 
+`,
+	"static/tmpl/tf/org_main.tf.tmpl": `locals {
+  organization_id = {{hclString .Org.Spec.Id}}
+}
+{{- range .SubFolders}}
+
+module "{{.Spec.Id}}" {
+  source = "./{{.Spec.Id}}"
+  parent = "organizations/${local.organization_id}"
+{{- with index $.ModuleDependsOn .Spec.Id}}
+
+  depends_on = [
+{{- range .}}
+    {{.}},
+{{- end}}
+  ]
+{{- end}}
+}
+{{- end}}
+`,
+	"static/tmpl/tf/projects.tf.tmpl": `{{- range $i, $p := .Projects}}
+{{- if $i}}{{"\n\n"}}{{end -}}
+resource "google_project" "{{$p.Project.Spec.Id}}" {
+  name       = {{hclString $p.Project.Spec.DisplayName}}
+  project_id = {{hclString $p.Project.Spec.Id}}
+  {{pad $.ProjectParentKey 10}} = {{$.ProjectParentValue}}
+{{- with $p.Project.Spec.BillingAccount}}
+
+  billing_account = {{hclString .}}
+{{- end}}
+}
+{{- with $p.Project.Spec.Services}}
+
+resource "google_project_service" "{{$p.Project.Spec.Id}}" {
+  for_each = toset([
+{{- range .}}
+    {{hclString .}},
+{{- end}}
+  ])
+
+  project = google_project.{{$p.Project.Spec.Id}}.project_id
+  service = each.value
+}
+{{- end}}
+{{- range $p.Networks}}
+
+resource "google_compute_network" "{{.Spec.Id}}" {
+  name                    = {{hclString .Spec.Id}}
+  project                 = google_project.{{$p.Project.Spec.Id}}.project_id
+  auto_create_subnetworks = false
+}
+{{- $n := .}}
+{{- range .Spec.SubnetSpecs}}
+
+resource "google_compute_subnetwork" "{{$n.Spec.Id}}_{{.Id}}" {
+  name          = {{hclString .Id}}
+  project       = google_project.{{$p.Project.Spec.Id}}.project_id
+  region        = {{hclString .Region}}
+  network       = google_compute_network.{{$n.Spec.Id}}.id
+  ip_cidr_range = {{hclString .IpCidrRange}}
+}
+{{- end}}
+{{- if .Spec.Shared}}
+
+resource "google_compute_shared_vpc_host_project" "{{.Spec.Id}}" {
+  project = google_project.{{$p.Project.Spec.Id}}.project_id
+}
+{{- end}}
+{{- end}}
+{{- with $p.HostProjectId}}
+
+resource "google_compute_shared_vpc_service_project" "{{$p.Project.Spec.Id}}" {
+  host_project    = {{$p.HostProject}}
+  service_project = google_project.{{$p.Project.Spec.Id}}.project_id
+{{- with $p.DependsOn}}
+
+  depends_on = [
+{{- range .}}
+    {{.}},
+{{- end}}
+  ]
+{{- end}}
+}
+{{- end}}
+{{- end}}
+`,
+	"static/tmpl/tf/provider.tf.tmpl": `terraform {
+  required_providers {
+    google = {
+      source = "hashicorp/google"
+    }
+  }
+}
+
+provider "google" {}
 `,
 }
@@ -0,0 +1,275 @@
+// Package launchpad file terraform.go contains Terraform output generation
+// from an assembled organization.
+package launchpad
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	tfTmplDir     = "static/tmpl/tf"
+	tfLicenseTmpl = "license.tf.tmpl"
+)
+
+var (
+	errUndefinedOrg    = errors.New("organization is not defined")
+	tfNameInvalidChars = regexp.MustCompile(`[^a-zA-Z\d\-\_]+`)
+)
+
+var (
+	// tfOrgFiles are generated at the root module representing the organization.
+	tfOrgFiles = []outputFile{
+		{name: "main.tf", tmpl: "org_main.tf.tmpl"},
+		{name: "projects.tf", tmpl: "projects.tf.tmpl"},
+		{name: "iam.tf", tmpl: "iam.tf.tmpl"},
+		{name: "provider.tf", tmpl: "provider.tf.tmpl"},
+		{name: "backend.tf", tmpl: "backend.tf.tmpl"},
+	}
+	// tfFolderFiles are generated in each folder's module.
+	tfFolderFiles = []outputFile{
+		{name: "main.tf", tmpl: "folder_main.tf.tmpl"},
+		{name: "projects.tf", tmpl: "projects.tf.tmpl"},
+		{name: "iam.tf", tmpl: "iam.tf.tmpl"},
+		{name: "variables.tf", tmpl: "folder_variables.tf.tmpl"},
+		{name: "outputs.tf", tmpl: "folder_outputs.tf.tmpl"},
+	}
+)
+
+// tfModuleData is the template input for a generated Terraform module.
+//
+// Only one of Org and Folder is set depending on which resource the module represents.
+type tfModuleData struct {
+	Org                *orgYAML
+	Folder             *folderYAML
+	SubFolders         folders
+	ModuleDependsOn    map[string][]string // ModuleDependsOn is depends_on of sub folder modules keyed by folder id.
+	ProjectParentKey   string              // ProjectParentKey is google_project attribute holding the parent.
+	ProjectParentValue string              // ProjectParentValue is Terraform expression of the parent.
+	Projects           []tfProjectData
+	IAMBindings        []tfIAMBinding
+}
+
+// tfProjectData is the template input for a project and its sub resources.
+type tfProjectData struct {
+	Project       *projectYAML
+	Networks      networks
+	HostProjectId string   // HostProjectId is set when project is attached to a shared network.
+	HostProject   string   // HostProject is Terraform expression of the shared network host project.
+	DependsOn     []string // DependsOn orders the service project attachment after its host project.
+}
+
+// tfSharedVPCDeps orders shared VPC service projects after their host projects across modules.
+type tfSharedVPCDeps struct {
+	hostProjects map[string]string   // hostProjects are host project expressions keyed by service project id.
+	projects     map[string][]string // projects are depends_on of service project attachments keyed by project id.
+	modules      map[string][]string // modules are depends_on of folder modules keyed by folder id.
+}
+
+// tfIAMBinding is the template input for a single role binding on a resource.
+type tfIAMBinding struct {
+	Name         string // Name is the Terraform resource name.
+	ResourceType string // ResourceType is one of organization, folder or project.
+	TargetKey    string // TargetKey is the IAM binding attribute identifying the resource.
+	TargetValue  string // TargetValue is Terraform expression of the resource.
+	Role         string
+	Members      []string
+}
+
+// tfRenderer renders an assembled organization as Terraform.
+type tfRenderer struct{}
+
+// render writes the assembled organization as a Terraform module tree.
+//
+// The organization is written to outputDir as the root module, each folder is
+// written into a sub directory named after its id under its parent's module.
+// Projects and IAM policies are written into the module of the resource they belong to.
+func (r *tfRenderer) render(ao *assembledOrg, outputDir string) error {
+	if ao.org.Spec.Id == "" {
+		return errUndefinedOrg
+	}
+	deps := newTfSharedVPCDeps(ao)
+	data := tfModuleData{
+		Org:                &ao.org,
+		SubFolders:         ao.org.subFolders.sortedCopy(),
+		ModuleDependsOn:    deps.modules,
+		ProjectParentKey:   "org_id",
+		ProjectParentValue: "local.organization_id",
+	}
+	data.Projects, data.IAMBindings = newTfProjectsData(ao, deps, ao.org.projects)
+	data.IAMBindings = append(newTfIAMBindings(ao.org.iamPolicies, "organization", "org_id", "local.organization_id"),
+		data.IAMBindings...)
+
+	if err := writeOutputFiles(outputDir, tfTmplDir, tfLicenseTmpl, tfOrgFiles, data); err != nil {
+		return err
+	}
+	for _, f := range data.SubFolders {
+		if err := generateTfFolder(ao, deps, f, filepath.Join(outputDir, f.Spec.Id)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generateTfFolder recursively writes the folder and its sub folders as Terraform modules.
+func generateTfFolder(ao *assembledOrg, deps *tfSharedVPCDeps, f *folderYAML, dir string) error {
+	folderExpr := fmt.Sprintf("google_folder.%s.name", f.Spec.Id)
+	data := tfModuleData{
+		Folder:             f,
+		SubFolders:         f.subFolders.sortedCopy(),
+		ModuleDependsOn:    deps.modules,
+		ProjectParentKey:   "folder_id",
+		ProjectParentValue: folderExpr,
+	}
+	data.Projects, data.IAMBindings = newTfProjectsData(ao, deps, f.projects)
+	data.IAMBindings = append(newTfIAMBindings(f.iamPolicies, "folder", "folder", folderExpr), data.IAMBindings...)
+
+	if err := writeOutputFiles(dir, tfTmplDir, tfLicenseTmpl, tfFolderFiles, data); err != nil {
+		return err
+	}
+	for _, sf := range data.SubFolders {
+		if err := generateTfFolder(ao, deps, sf, filepath.Join(dir, sf.Spec.Id)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newTfProjectsData converts projects into template inputs along with IAM bindings on these projects.
+func newTfProjectsData(ao *assembledOrg, deps *tfSharedVPCDeps, ps projects) ([]tfProjectData, []tfIAMBinding) {
+	var data []tfProjectData
+	var bindings []tfIAMBinding
+	for _, p := range ps.sortedCopy() {
+		pd := tfProjectData{Project: p, Networks: p.networks.sortedCopy()}
+		if n := sharedNetwork(ao, p); n != nil {
+			pd.HostProjectId = n.Spec.ProjectRef.TargetId
+			pd.HostProject = deps.hostProjects[p.Spec.Id]
+			pd.DependsOn = deps.projects[p.Spec.Id]
+		}
+		data = append(data, pd)
+		projectExpr := fmt.Sprintf("google_project.%s.project_id", p.Spec.Id)
+		bindings = append(bindings, newTfIAMBindings(p.iamPolicies, "project", "project", projectExpr)...)
+	}
+	return data, bindings
+}
+
+// sharedNetwork returns the shared network the project is attached to, nil if not attached.
+func sharedNetwork(ao *assembledOrg, p *projectYAML) *networkYAML {
+	if p.Spec.SharedNetworkRef == nil {
+		return nil
+	}
+	if res, ok := ao.resourceMap[p.Spec.SharedNetworkRef.resId()]; ok {
+		if n, ok := res.yaml.(*networkYAML); ok {
+			return n
+		}
+	}
+	return nil
+}
+
+// newTfSharedVPCDeps resolves how each service project refers to its shared VPC host project.
+//
+// A service project in the module of its host project references the host project resource.
+// Otherwise the host project id is a literal and the service project, or the folder module
+// containing it, depends on the host project resource or the folder module containing it.
+// Both are looked up from the closest module containing the service and host projects.
+func newTfSharedVPCDeps(ao *assembledOrg) *tfSharedVPCDeps {
+	deps := &tfSharedVPCDeps{
+		hostProjects: make(map[string]string),
+		projects:     make(map[string][]string),
+		modules:      make(map[string][]string),
+	}
+	for _, rId := range ao.resourceMap.sortedResId() {
+		p, ok := ao.resourceMap[rId].yaml.(*projectYAML)
+		if !ok {
+			continue
+		}
+		n := sharedNetwork(ao, p)
+		if n == nil {
+			continue
+		}
+		hostPath := tfModulePath(ao, &n.Spec.ProjectRef)
+		servicePath := tfModulePath(ao, &p.Spec.ParentRef)
+		common := 0
+		for common < len(hostPath) && common < len(servicePath) && hostPath[common] == servicePath[common] {
+			common++
+		}
+		hostRes := fmt.Sprintf("google_compute_shared_vpc_host_project.%s", n.Spec.Id)
+		if common == len(hostPath) && common == len(servicePath) {
+			deps.hostProjects[p.Spec.Id] = hostRes + ".project"
+			continue
+		}
+		deps.hostProjects[p.Spec.Id] = hclString(n.Spec.ProjectRef.TargetId)
+		dep := hostRes
+		if common < len(hostPath) {
+			dep = "module." + hostPath[common]
+		}
+		if common < len(servicePath) {
+			deps.modules[servicePath[common]] = appendUnique(deps.modules[servicePath[common]], dep)
+		} else {
+			deps.projects[p.Spec.Id] = appendUnique(deps.projects[p.Spec.Id], dep)
+		}
+	}
+	return deps
+}
+
+// tfModulePath returns ids of folders from the organization down to the module of the referenced parent.
+func tfModulePath(ao *assembledOrg, ref *referenceYAML) []string {
+	var path []string
+	for res, ok := ao.resourceMap[ref.resId()]; ok; res, ok = ao.resourceMap[ref.resId()] {
+		switch r := res.yaml.(type) {
+		case *projectYAML:
+			ref = &r.Spec.ParentRef
+		case *folderYAML:
+			path = append([]string{r.Spec.Id}, path...)
+			ref = &r.Spec.ParentRef
+		default:
+			return path
+		}
+	}
+	return path
+}
+
+// appendUnique appends s to ss if it is not in ss already.
+func appendUnique(ss []string, s string) []string {
+	for _, e := range ss {
+		if e == s {
+			return ss
+		}
+	}
+	return append(ss, s)
+}
+
+// newTfIAMBindings flattens IAM policies on a resource into per role bindings.
+func newTfIAMBindings(ips iamPolicies, resType, key, value string) []tfIAMBinding {
+	var bindings []tfIAMBinding
+	for _, ip := range ips.sortedCopy() {
+		for _, b := range ip.Spec.Bindings {
+			bindings = append(bindings, tfIAMBinding{
+				Name:         ip.Spec.Id + "_" + tfRoleName(b.Role),
+				ResourceType: resType,
+				TargetKey:    key,
+				TargetValue:  value,
+				Role:         b.Role,
+				Members:      b.Members,
+			})
+		}
+	}
+	return bindings
+}
+
+// tfRoleName converts an IAM role into a Terraform name friendly format.
+//
+// For example, roles/compute.networkUser becomes compute_networkUser.
+func tfRoleName(role string) string {
+	role = strings.TrimPrefix(role, "roles/")
+	return strings.Trim(tfNameInvalidChars.ReplaceAllString(role, "_"), "_")
+}
+
+// hclString returns s as a quoted HCL string literal with interpolation escaped.
+func hclString(s string) string {
+	return strings.ReplaceAll(strconv.Quote(s), "${", "$${")
+}
@@ -0,0 +1,154 @@
+package launchpad
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	updateEnvVar  = "UPDATE_EXPECTED"
+	testDataDir   = "../testdata/launchpad"
+	tfExpectedDir = "../testdata/launchpad/tf"
+)
+
+func TestTfRenderer_render(t *testing.T) {
+	var testCases = []struct {
+		name       string
+		inputYAMLs []string
+	}{{
+		"org_crd_subfolder",
+		[]string{"folder/org_1.yaml"},
+	}, {
+		"nested_folder_out_of_order",
+		[]string{"folder/folder_1.yaml", "folder/folder_12.yaml"},
+	}, {
+		"deep_nested",
+		[]string{"folder/folder_1_nested.yaml"},
+	}, {
+		"projects_networks_iam",
+		[]string{"project/org_1_projects.yaml", "project/iam_1.yaml"},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var inputs []string
+			for _, fp := range tc.inputYAMLs {
+				inputs = append(inputs, filepath.Join(testDataDir, fp))
+			}
+			outDir := t.TempDir()
+			err := (&tfRenderer{}).render(assembleResourcesToOrg(loadResources(inputs)), outDir)
+			assert.Nil(t, err, "generation should succeed")
+
+			expectedDir := filepath.Join(tfExpectedDir, tc.name)
+			updateExpected(t, expectedDir, outDir)
+			assert.Equal(t, readDir(t, expectedDir), readDir(t, outDir), "generated files should match expected")
+		})
+	}
+}
+
+func TestTfRenderer_renderUndefinedOrg(t *testing.T) {
+	err := (&tfRenderer{}).render(newAssembledOrg(), t.TempDir())
+	assert.Equal(t, errUndefinedOrg, err)
+}
+
+func TestNewTfSharedVPCDeps(t *testing.T) {
+	ao := newAssembledOrg()
+	add := func(r resourceHandler) { ao.resourceMap[r.resId()] = &resource{yaml: r} }
+	folder := func(id string, parent referenceYAML) {
+		add(&folderYAML{Spec: folderSpecYAML{Id: id, ParentRef: parent}})
+	}
+	project := func(id string, parent referenceYAML, network string) {
+		p := &projectYAML{Spec: projectSpecYAML{Id: id, ParentRef: parent}}
+		if network != "" {
+			p.Spec.SharedNetworkRef = &referenceYAML{Network.String(), network}
+		}
+		add(p)
+	}
+	org := referenceYAML{Organization.String(), "12345678"}
+	inFolder := func(id string) referenceYAML { return referenceYAML{Folder.String(), id} }
+	add(&orgYAML{Spec: orgSpecYAML{Id: "12345678"}})
+	folder("a", org)
+	folder("a1", inFolder("a"))
+	folder("b", org)
+	project("host-1", inFolder("a1"), "")
+	project("host-2", org, "")
+	add(&networkYAML{Spec: networkSpecYAML{Id: "vpc-1", Shared: true, ProjectRef: referenceYAML{Project.String(), "host-1"}}})
+	add(&networkYAML{Spec: networkSpecYAML{Id: "vpc-2", Shared: true, ProjectRef: referenceYAML{Project.String(), "host-2"}}})
+	project("same-module", inFolder("a1"), "vpc-1")
+	project("host-in-sub-folder", inFolder("a"), "vpc-1")
+	project("host-in-sub-sub-folder", org, "vpc-1")
+	project("host-in-sibling-folder", inFolder("b"), "vpc-1")
+	project("host-in-parent", inFolder("a1"), "vpc-2")
+
+	deps := newTfSharedVPCDeps(ao)
+	assert.Equal(t, map[string]string{
+		"same-module":            "google_compute_shared_vpc_host_project.vpc-1.project",
+		"host-in-sub-folder":     `"host-1"`,
+		"host-in-sub-sub-folder": `"host-1"`,
+		"host-in-sibling-folder": `"host-1"`,
+		"host-in-parent":         `"host-2"`,
+	}, deps.hostProjects)
+	assert.Equal(t, map[string][]string{
+		"host-in-sub-folder":     {"module.a1"},
+		"host-in-sub-sub-folder": {"module.a"},
+	}, deps.projects)
+	assert.Equal(t, map[string][]string{
+		"a": {"google_compute_shared_vpc_host_project.vpc-2"},
+		"b": {"module.a"},
+	}, deps.modules)
+}
+
+func TestHclString(t *testing.T) {
+	assert.Equal(t, `"group1"`, hclString("group1"))
+	assert.Equal(t, `"a \"b\""`, hclString(`a "b"`))
+	assert.Equal(t, `"$${var}"`, hclString("${var}"))
+}
+
+// readDir returns content of all files under dir keyed by path relative to dir.
+func readDir(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.Walk(dir, func(fp string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(fp)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, fp)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = string(content)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error reading dir %s: %v", dir, err)
+	}
+	return files
+}
+
+// updateExpected replaces expected directory with generated output when update env var is set.
+func updateExpected(t *testing.T, expectedDir, outDir string) {
+	t.Helper()
+	if strings.ToLower(os.Getenv(updateEnvVar)) != "true" {
+		return
+	}
+	if err := os.RemoveAll(expectedDir); err != nil {
+		t.Fatalf("error removing %s: %v", expectedDir, err)
+	}
+	for rel, content := range readDir(t, outDir) {
+		fp := filepath.Join(expectedDir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+			t.Fatalf("error updating result: %v", err)
+		}
+		if err := os.WriteFile(fp, []byte(content), 0644); err != nil {
+			t.Fatalf("error updating result: %v", err)
+		}
+	}
+}
@@ -97,6 +97,13 @@ func getDataFromFile(caiDirName string) ([]*validator.Asset, error) {
 	return pbAssets, nil
 }
 
+// GetAssetsFromDir reads Cloud Asset Inventory export files from a local directory.
+//
+// Export files are expected to be named the same as when exported by scorecard.
+func GetAssetsFromDir(caiDirName string) ([]*validator.Asset, error) {
+	return getDataFromFile(caiDirName)
+}
+
 func getDataFromStdin() ([]*validator.Asset, error) {
 	return getDataFromReader(os.Stdin)
 }
@@ -0,0 +1,30 @@
+/**
+ * Copyright 2019 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+resource "google_folder" "group1_2" {
+  display_name = "group1_2"
+  parent       = var.parent
+}
+
+module "group1_2_1" {
+  source = "./group1_2_1"
+  parent = google_folder.group1_2.name
+}
+
+module "group1_2_3" {
+  source = "./group1_2_3"
+  parent = google_folder.group1_2.name
+}
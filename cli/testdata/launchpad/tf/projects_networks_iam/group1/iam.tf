@@ -0,0 +1,32 @@
+/**
+ * Copyright 2019 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+resource "google_folder_iam_binding" "group1_viewers_resourcemanager_folderViewer" {
+  folder  = google_folder.group1.name
+  role    = "roles/resourcemanager.folderViewer"
+  members = [
+    "group:viewers@example.com",
+    "user:jane@example.com",
+  ]
+}
+
+resource "google_project_iam_binding" "network_users_compute_networkUser" {
+  project = google_project.host-project-1.project_id
+  role    = "roles/compute.networkUser"
+  members = [
+    "serviceAccount:cloudservices@service-project-1.iam.gserviceaccount.com",
+  ]
+}
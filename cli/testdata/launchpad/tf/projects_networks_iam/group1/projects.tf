@@ -0,0 +1,58 @@
+/**
+ * Copyright 2019 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+resource "google_project" "host-project-1" {
+  name       = "host project"
+  project_id = "host-project-1"
+  folder_id  = google_folder.group1.name
+
+  billing_account = "000000-000000-000000"
+}
+
+resource "google_project_service" "host-project-1" {
+  for_each = toset([
+    "compute.googleapis.com",
+  ])
+
+  project = google_project.host-project-1.project_id
+  service = each.value
+}
+
+resource "google_compute_network" "shared-vpc" {
+  name                    = "shared-vpc"
+  project                 = google_project.host-project-1.project_id
+  auto_create_subnetworks = false
+}
+
+resource "google_compute_subnetwork" "shared-vpc_subnet1" {
+  name          = "subnet1"
+  project       = google_project.host-project-1.project_id
+  region        = "us-central1"
+  network       = google_compute_network.shared-vpc.id
+  ip_cidr_range = "10.0.0.0/24"
+}
+
+resource "google_compute_subnetwork" "shared-vpc_subnet2" {
+  name          = "subnet2"
+  project       = google_project.host-project-1.project_id
+  region        = "us-east1"
+  network       = google_compute_network.shared-vpc.id
+  ip_cidr_range = "10.0.1.0/24"
+}
+
+resource "google_compute_shared_vpc_host_project" "shared-vpc" {
+  project = google_project.host-project-1.project_id
+}
@@ -0,0 +1,30 @@
+/**
+ * Copyright 2019 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+resource "google_project" "service-project-1" {
+  name       = "service project"
+  project_id = "service-project-1"
+  org_id     = local.organization_id
+}
+
+resource "google_compute_shared_vpc_service_project" "service-project-1" {
+  host_project    = "host-project-1"
+  service_project = google_project.service-project-1.project_id
+
+  depends_on = [
+    module.group1,
+  ]
+}
@@ -3,6 +3,9 @@ package discovery
 import (
 	"fmt"
 	"os"
+	"regexp"
+	"slices"
+	"time"
 
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
@@ -13,14 +16,60 @@ const (
 	blueprintTestAPIVersion   = "blueprints.cloud.google.com/v1alpha1"
 )
 
+// knownStages are the stages that can be configured in a BlueprintTestConfig.
+var knownStages = []string{"init", "plan", "apply", "verify", "teardown", "upgrade", "guardrail"}
+
 type BlueprintTestConfig struct {
 	yaml.ResourceMeta `json:",inline" yaml:",inline"`
 	Spec              struct {
 		Skip bool `json:"skip" yaml:"skip"`
+		// Vars are variables passed to Terraform or setters of a KRM package.
+		Vars map[string]interface{} `json:"vars" yaml:"vars"`
+		// EnvVars are passed to Terraform as TF_VAR_ prefixed environment variables or as is to kpt.
+		EnvVars map[string]string `json:"envVars" yaml:"envVars"`
+		// SetupVars override outputs of the setup stage or for KRM packages setters discovered from the environment.
+		SetupVars map[string]string `json:"setupVars" yaml:"setupVars"`
+		// Stages limits the stages run by the test, all stages are run if empty.
+		Stages []string `json:"stages" yaml:"stages"`
+		// Labels are used to select tests, e.g. bptest run --label smoke.
+		Labels []string `json:"labels" yaml:"labels"`
+		// Timeout is the duration after which stages without a timeout in StageTimeouts are cancelled.
+		Timeout string `json:"timeout" yaml:"timeout"`
+		// StageTimeouts maps stage names to durations such as 30m after which the stage is cancelled.
+		StageTimeouts map[string]string `json:"stageTimeouts" yaml:"stageTimeouts"`
+		// RetryableErrors maps regular expressions matching transient errors to a message displayed on retry.
+		RetryableErrors map[string]string `json:"retryableErrors" yaml:"retryableErrors"`
+		// AllowedDiffs are known perpetual diffs ignored by the post apply idempotency check.
+		AllowedDiffs []AllowedDiff `json:"allowedDiffs" yaml:"allowedDiffs"`
+		// Guardrails limit the resources planned by the test before apply.
+		Guardrails Guardrails `json:"guardrails" yaml:"guardrails"`
 	} `json:"spec" yaml:"spec"`
 	Path string
 }
 
+// AllowedDiff is a known perpetual diff ignored by the post apply idempotency check.
+type AllowedDiff struct {
+	// Address is the resource address with * matching any characters, any resource if empty.
+	Address string `json:"address" yaml:"address"`
+	// Attributes are attribute paths such as labels or metadata.ssh-keys, all attributes if empty.
+	Attributes []string `json:"attributes" yaml:"attributes"`
+}
+
+// Guardrails limit the resources planned by a test to protect shared projects from expensive mistakes.
+type Guardrails struct {
+	// MaxResources maps resource types to the maximum number of resources of the type planned.
+	MaxResources map[string]int `json:"maxResources" yaml:"maxResources"`
+	// DenyGPUs fails the test if resources with GPUs are planned.
+	DenyGPUs bool `json:"denyGPUs" yaml:"denyGPUs"`
+	// MachineTypes are the allowed machine types of planned resources, any machine type if empty.
+	MachineTypes []string `json:"machineTypes" yaml:"machineTypes"`
+}
+
+// Enabled returns true if any guardrail is configured.
+func (g Guardrails) Enabled() bool {
+	return len(g.MaxResources) > 0 || g.DenyGPUs || len(g.MachineTypes) > 0
+}
+
 // GetTestConfig returns BlueprintTestConfig if found
 func GetTestConfig(path string) (BlueprintTestConfig, error) {
 	_, err := os.Stat(path)
@@ -55,5 +104,85 @@ func isValidTestConfig(b BlueprintTestConfig) error {
 	if b.ResourceMeta.Kind != blueprintTestKind {
 		return fmt.Errorf("invalid Kind %s expected %s", b.ResourceMeta.Kind, blueprintTestKind)
 	}
+	for _, stage := range b.Spec.Stages {
+		if !slices.Contains(knownStages, stage) {
+			return fmt.Errorf("invalid stage %s - one of %+q expected", stage, knownStages)
+		}
+	}
+	for _, label := range b.Spec.Labels {
+		if label == "" {
+			return fmt.Errorf("invalid empty label")
+		}
+	}
+	if b.Spec.Timeout != "" {
+		if err := isValidTimeout(b.Spec.Timeout); err != nil {
+			return fmt.Errorf("invalid timeout %s: %v", b.Spec.Timeout, err)
+		}
+	}
+	for stage, timeout := range b.Spec.StageTimeouts {
+		if !slices.Contains(knownStages, stage) {
+			return fmt.Errorf("invalid stage %s in stageTimeouts - one of %+q expected", stage, knownStages)
+		}
+		if err := isValidTimeout(timeout); err != nil {
+			return fmt.Errorf("invalid timeout %s for stage %s: %v", timeout, stage, err)
+		}
+	}
+	for pattern := range b.Spec.RetryableErrors {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid retryable error %s: %v", pattern, err)
+		}
+	}
+	for _, vars := range []map[string]string{b.Spec.EnvVars, b.Spec.SetupVars} {
+		if _, ok := vars[""]; ok {
+			return fmt.Errorf("invalid empty variable name")
+		}
+	}
+	if _, ok := b.Spec.Vars[""]; ok {
+		return fmt.Errorf("invalid empty variable name")
+	}
+	for _, d := range b.Spec.AllowedDiffs {
+		if d.Address == "" && len(d.Attributes) == 0 {
+			return fmt.Errorf("invalid allowed diff, address or attributes expected")
+		}
+		if slices.Contains(d.Attributes, "") {
+			return fmt.Errorf("invalid empty attribute in allowed diff %s", d.Address)
+		}
+	}
+	for resourceType, max := range b.Spec.Guardrails.MaxResources {
+		if max < 0 {
+			return fmt.Errorf("invalid max resources %d for %s: must not be negative", max, resourceType)
+		}
+	}
+	if slices.Contains(b.Spec.Guardrails.MachineTypes, "") {
+		return fmt.Errorf("invalid empty machine type in guardrails")
+	}
+	return nil
+}
+
+// isValidTimeout validates a timeout is a positive duration.
+func isValidTimeout(timeout string) error {
+	d, err := time.ParseDuration(timeout)
+	if err != nil {
+		return err
+	}
+	if d <= 0 {
+		return fmt.Errorf("must be positive")
+	}
 	return nil
 }
+
+// StageTimeout returns the configured timeout for stage or zero if there is none.
+func (b BlueprintTestConfig) StageTimeout(stage string) time.Duration {
+	timeout, ok := b.Spec.StageTimeouts[stage]
+	if !ok {
+		timeout = b.Spec.Timeout
+	}
+	// timeouts are validated when loading the config
+	d, _ := time.ParseDuration(timeout)
+	return d
+}
+
+// RunsStage returns true if stage should be run according to the configured stages.
+func (b BlueprintTestConfig) RunsStage(stage string) bool {
+	return len(b.Spec.Stages) == 0 || slices.Contains(b.Spec.Stages, stage)
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -72,3 +73,206 @@ func setupTestCfg(t *testing.T, data string) string {
 	assert.NoError(err)
 	return fPath
 }
+
+func TestStageTimeouts(t *testing.T) {
+	tests := []struct {
+		name    string
+		testCfg string
+		want    map[string]time.Duration
+		errMsg  string
+	}{
+		{
+			name: "valid",
+			testCfg: `apiVersion: blueprints.cloud.google.com/v1alpha1
+kind: BlueprintTest
+metadata:
+  name: test
+spec:
+  stageTimeouts:
+    apply: 30m
+    verify: 90s
+`,
+			want: map[string]time.Duration{"apply": 30 * time.Minute, "verify": 90 * time.Second, "teardown": 0},
+		},
+		{
+			name: "invalid duration",
+			testCfg: `apiVersion: blueprints.cloud.google.com/v1alpha1
+kind: BlueprintTest
+metadata:
+  name: test
+spec:
+  stageTimeouts:
+    apply: soon
+`,
+			errMsg: "invalid timeout soon for stage apply",
+		},
+		{
+			name: "negative duration",
+			testCfg: `apiVersion: blueprints.cloud.google.com/v1alpha1
+kind: BlueprintTest
+metadata:
+  name: test
+spec:
+  stageTimeouts:
+    apply: -1m
+`,
+			errMsg: "invalid timeout -1m for stage apply: must be positive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			testCfgPath := setupTestCfg(t, tt.testCfg)
+			defer os.RemoveAll(testCfgPath)
+			bpTestCfg, err := GetTestConfig(testCfgPath)
+			if tt.errMsg != "" {
+				assert.ErrorContains(err, tt.errMsg)
+				return
+			}
+			assert.NoError(err)
+			for stage, want := range tt.want {
+				assert.Equal(want, bpTestCfg.StageTimeout(stage))
+			}
+		})
+	}
+}
+
+func TestSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		testCfg string
+		errMsg  string
+	}{
+		{
+			name: "full",
+			testCfg: `apiVersion: blueprints.cloud.google.com/v1alpha1
+kind: BlueprintTest
+metadata:
+  name: test
+spec:
+  vars:
+    name: foo
+    count: 2
+  envVars:
+    region: us-central1
+  setupVars:
+    project_id: my-project
+  stages: [init, apply, teardown]
+  labels: [smoke]
+  timeout: 1h
+  retryableErrors:
+    ".*quota.*": "Quota exceeded."
+  allowedDiffs:
+  - address: google_compute_instance.vm[*]
+    attributes: [metadata.ssh-keys]
+  - attributes: [labels]
+  guardrails:
+    maxResources:
+      google_container_node_pool: 2
+    denyGPUs: true
+    machineTypes: [e2-medium]
+`,
+		},
+		{
+			name: "invalid stage",
+			testCfg: `apiVersion: blueprints.cloud.google.com/v1alpha1
+kind: BlueprintTest
+metadata:
+  name: test
+spec:
+  stages: [deploy]
+`,
+			errMsg: "invalid stage deploy",
+		},
+		{
+			name: "invalid timeout",
+			testCfg: `apiVersion: blueprints.cloud.google.com/v1alpha1
+kind: BlueprintTest
+metadata:
+  name: test
+spec:
+  timeout: 0s
+`,
+			errMsg: "invalid timeout 0s: must be positive",
+		},
+		{
+			name: "invalid retryable error",
+			testCfg: `apiVersion: blueprints.cloud.google.com/v1alpha1
+kind: BlueprintTest
+metadata:
+  name: test
+spec:
+  retryableErrors:
+    "(": "Unbalanced."
+`,
+			errMsg: "invalid retryable error (",
+		},
+		{
+			name: "empty label",
+			testCfg: `apiVersion: blueprints.cloud.google.com/v1alpha1
+kind: BlueprintTest
+metadata:
+  name: test
+spec:
+  labels: [""]
+`,
+			errMsg: "invalid empty label",
+		},
+		{
+			name: "empty allowed diff",
+			testCfg: `apiVersion: blueprints.cloud.google.com/v1alpha1
+kind: BlueprintTest
+metadata:
+  name: test
+spec:
+  allowedDiffs:
+  - attributes: []
+`,
+			errMsg: "invalid allowed diff",
+		},
+		{
+			name: "negative max resources",
+			testCfg: `apiVersion: blueprints.cloud.google.com/v1alpha1
+kind: BlueprintTest
+metadata:
+  name: test
+spec:
+  guardrails:
+    maxResources:
+      google_container_node_pool: -1
+`,
+			errMsg: "invalid max resources -1 for google_container_node_pool",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			testCfgPath := setupTestCfg(t, tt.testCfg)
+			defer os.RemoveAll(testCfgPath)
+			bpTestCfg, err := GetTestConfig(testCfgPath)
+			if tt.errMsg != "" {
+				assert.ErrorContains(err, tt.errMsg)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(map[string]interface{}{"name": "foo", "count": 2}, bpTestCfg.Spec.Vars)
+			assert.Equal(map[string]string{"region": "us-central1"}, bpTestCfg.Spec.EnvVars)
+			assert.Equal(map[string]string{"project_id": "my-project"}, bpTestCfg.Spec.SetupVars)
+			assert.Equal([]string{"smoke"}, bpTestCfg.Spec.Labels)
+			assert.Equal(map[string]string{".*quota.*": "Quota exceeded."}, bpTestCfg.Spec.RetryableErrors)
+			assert.Equal([]AllowedDiff{
+				{Address: "google_compute_instance.vm[*]", Attributes: []string{"metadata.ssh-keys"}},
+				{Attributes: []string{"labels"}},
+			}, bpTestCfg.Spec.AllowedDiffs)
+			assert.Equal(Guardrails{
+				MaxResources: map[string]int{"google_container_node_pool": 2},
+				DenyGPUs:     true,
+				MachineTypes: []string{"e2-medium"},
+			}, bpTestCfg.Spec.Guardrails)
+			assert.True(bpTestCfg.Spec.Guardrails.Enabled())
+			assert.True(bpTestCfg.RunsStage("apply"))
+			assert.False(bpTestCfg.RunsStage("verify"))
+			assert.Equal(time.Hour, bpTestCfg.StageTimeout("apply"))
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package git
 
 import (
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/utils"
@@ -10,6 +13,9 @@ import (
 	"github.com/mitchellh/go-testing-interface"
 )
 
+// ErrNoPreviousTag is returned if no tag matching the pattern exists before the latest commit.
+var ErrNoPreviousTag = errors.New("no previous tag")
+
 type CmdCfg struct {
 	gitBinary string         // git binary
 	dir       string         // dir to execute commands in
@@ -100,3 +106,65 @@ func (g *CmdCfg) Commit() {
 	currentTime := time.Now()
 	g.CommitWithMsg(fmt.Sprintf("commit %s", currentTime.Format(time.RFC1123)), []string{"--author", "BlueprintsTest <blueprints-ci-test@google.com>"})
 }
+
+// GetTopLevel returns the absolute path of the top-level directory of the repo.
+func (g *CmdCfg) GetTopLevel() string {
+	dir, err := g.RunCmdE("rev-parse", "--show-toplevel")
+	if err != nil {
+		g.t.Fatalf("error getting top-level directory: %v", err)
+	}
+	return dir
+}
+
+// GetPreviousTag returns the most recent tag matching pattern before the latest commit,
+// i.e. excluding tags of the latest commit itself.
+func (g *CmdCfg) GetPreviousTag(pattern string) string {
+	tag, err := g.GetPreviousTagE(pattern)
+	if err != nil {
+		g.t.Fatalf("error getting previous tag matching %s: %v", pattern, err)
+	}
+	return tag
+}
+
+// GetPreviousTagE returns the most recent tag matching pattern before the latest commit,
+// i.e. excluding tags of the latest commit itself.
+//
+// The tag is described from the parent of the latest commit. If the parent is not available,
+// as in shallow clones or repos with a single commit, the highest version of the tags matching
+// pattern which do not point at the latest commit is returned instead.
+// ErrNoPreviousTag is returned if there is no such tag.
+func (g *CmdCfg) GetPreviousTagE(pattern string) (string, error) {
+	if tag, err := g.RunCmdE("describe", "--tags", "--abbrev=0", "--match", pattern, "HEAD^"); err == nil {
+		return tag, nil
+	}
+	tags, err := g.RunCmdE("tag", "--list", "--sort=-v:refname", pattern)
+	if err != nil {
+		return "", err
+	}
+	headTags, err := g.RunCmdE("tag", "--list", "--points-at", "HEAD", pattern)
+	if err != nil {
+		return "", err
+	}
+	for _, tag := range strings.Fields(tags) {
+		if !slices.Contains(strings.Fields(headTags), tag) {
+			return tag, nil
+		}
+	}
+	return "", ErrNoPreviousTag
+}
+
+// AddWorktree checks out ref in a new detached worktree at dir.
+func (g *CmdCfg) AddWorktree(dir, ref string) {
+	_, err := g.RunCmdE("worktree", "add", "--detach", dir, ref)
+	if err != nil {
+		g.t.Fatalf("error adding worktree for %s: %v", ref, err)
+	}
+}
+
+// RemoveWorktree removes the worktree at dir.
+func (g *CmdCfg) RemoveWorktree(dir string) {
+	_, err := g.RunCmdE("worktree", "remove", "--force", dir)
+	if err != nil {
+		g.t.Fatalf("error removing worktree %s: %v", dir, err)
+	}
+}
@@ -1,7 +1,9 @@
 package kpt
 
 import (
+	"context"
 	"fmt"
+	"maps"
 	"time"
 
 	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/utils"
@@ -19,11 +21,13 @@ import (
 const MIN_KPT_VERSION = "v1.0.0-beta.16"
 
 type CmdCfg struct {
-	kptBinary string         // kpt binary
-	dir       string         // dir to execute commands in
-	logger    *logger.Logger // custom logger
-	t         testing.TB     // TestingT or TestingB
-	tries     int            // qty to try kpt command, default: 3
+	kptBinary       string            // kpt binary
+	dir             string            // dir to execute commands in
+	logger          *logger.Logger    // custom logger
+	t               testing.TB        // TestingT or TestingB
+	tries           int               // qty to try kpt command, default: 3
+	env             map[string]string // additional env vars for kpt commands
+	retryableErrors map[string]string // errors to retry, all errors are retried if empty
 }
 
 type cmdOption func(*CmdCfg)
@@ -46,6 +50,21 @@ func WithLogger(logger *logger.Logger) cmdOption {
 	}
 }
 
+// WithEnv sets additional environment variables for kpt commands.
+func WithEnv(env map[string]string) cmdOption {
+	return func(f *CmdCfg) {
+		f.env = env
+	}
+}
+
+// WithRetryableErrors limits retries of kpt commands to errors matching one of the regular
+// expressions in retryableErrors. The values are messages displayed on retry.
+func WithRetryableErrors(retryableErrors map[string]string) cmdOption {
+	return func(f *CmdCfg) {
+		f.retryableErrors = retryableErrors
+	}
+}
+
 // NewCmdConfig sets defaults and validates values for kpt Options.
 func NewCmdConfig(t testing.TB, opts ...cmdOption) *CmdCfg {
 	kOpts := &CmdCfg{
@@ -73,16 +92,40 @@ func NewCmdConfig(t testing.TB, opts ...cmdOption) *CmdCfg {
 }
 
 func (k *CmdCfg) RunCmd(args ...string) string {
+	return k.RunCmdContext(context.Background(), args...)
+}
+
+// RunCmdContext runs kpt with args as part of the stage running with ctx.
+// The command is interrupted if the stage times out and is not retried once ctx is cancelled.
+func (k *CmdCfg) RunCmdContext(ctx context.Context, args ...string) string {
+	env := k.env
+	if stageEnv := utils.StageEnv(ctx); stageEnv != nil {
+		env = maps.Clone(k.env)
+		if env == nil {
+			env = map[string]string{}
+		}
+		maps.Copy(env, stageEnv)
+	}
 	kptCmd := shell.Command{
 		Command:    "kpt",
 		Args:       args,
 		Logger:     k.logger,
 		WorkingDir: k.dir,
+		Env:        env,
 	}
 	command := func() (string, error) {
+		if err := ctx.Err(); err != nil {
+			return "", retry.FatalError{Underlying: err}
+		}
 		return shell.RunCommandAndGetStdOutE(k.t, kptCmd)
 	}
-	op, err := retry.DoWithRetryE(k.t, fmt.Sprintf("kpt %v",  kptCmd.Args), k.tries, 15*time.Second, command)
+	var op string
+	var err error
+	if len(k.retryableErrors) > 0 {
+		op, err = retry.DoWithRetryableErrorsE(k.t, fmt.Sprintf("kpt %v", kptCmd.Args), k.retryableErrors, k.tries, 15*time.Second, command)
+	} else {
+		op, err = retry.DoWithRetryE(k.t, fmt.Sprintf("kpt %v",  kptCmd.Args), k.tries, 15*time.Second, command)
+	}
 	if err != nil {
 		k.t.Fatal(err)
 	}
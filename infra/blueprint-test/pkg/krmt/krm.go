@@ -1,12 +1,15 @@
 package krmt
 
 import (
+	"context"
 	"fmt"
+	"maps"
 	"os"
 	"path"
 	"path/filepath"
 
 	gotest "testing"
+	"time"
 
 	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/discovery"
 	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/git"
@@ -43,6 +46,8 @@ type KRMBlueprintTest struct {
 	apply                         func(*assert.Assertions) // apply function
 	verify                        func(*assert.Assertions) // verify function
 	teardown                      func(*assert.Assertions) // teardown function
+	stageTimeouts                 map[string]time.Duration // optional timeouts of stages overriding the test config
+	stageCtx                      context.Context          // context of the running stage, cancelled if the stage times out
 }
 
 type krmtOption func(*KRMBlueprintTest)
@@ -89,6 +94,17 @@ func WithTimeout(timeout string) krmtOption {
 	}
 }
 
+// WithStageTimeout cancels stage if it does not complete within timeout, overriding
+// stageTimeouts of the test config. Teardown is still run if a stage times out.
+func WithStageTimeout(stage string, timeout time.Duration) krmtOption {
+	return func(f *KRMBlueprintTest) {
+		if f.stageTimeouts == nil {
+			f.stageTimeouts = make(map[string]time.Duration)
+		}
+		f.stageTimeouts[stage] = timeout
+	}
+}
+
 func WithLogger(logger *logger.Logger) krmtOption {
 	return func(f *KRMBlueprintTest) {
 		f.logger = logger
@@ -152,12 +168,13 @@ func NewKRMBlueprintTest(t testing.TB, opts ...krmtOption) *KRMBlueprintTest {
 	if err != nil {
 		t.Fatal(err)
 	}
+	krmt.applyTestConfig()
 	// if no explicit build directory is provided, setup build directory
 	if krmt.buildDir == "" {
 		krmt.buildDir = krmt.getDefaultBuildDir()
 	}
 	// configure kpt to run in buildDir
-	krmt.kpt = kpt.NewCmdConfig(t, kpt.WithDir(krmt.buildDir))
+	krmt.kpt = krmt.newKptCmdConfig()
 	// get well known setters from env vars
 	krmt.getKnownSettersFromEnv()
 
@@ -165,6 +182,27 @@ func NewKRMBlueprintTest(t testing.TB, opts ...krmtOption) *KRMBlueprintTest {
 	return krmt
 }
 
+// applyTestConfig merges vars and setup vars from the test config as setters.
+// Setters set via options take precedence over vars which take precedence over setup vars.
+func (b *KRMBlueprintTest) applyTestConfig() {
+	spec := b.BlueprintTestConfig.Spec
+	setters := make(map[string]string)
+	maps.Copy(setters, spec.SetupVars)
+	for k, v := range spec.Vars {
+		setters[k] = fmt.Sprint(v)
+	}
+	b.setters = kpt.MergeSetters(setters, b.setters)
+}
+
+// newKptCmdConfig returns a kpt cmd config running in buildDir with env vars and retryable errors from the test config.
+func (b *KRMBlueprintTest) newKptCmdConfig() *kpt.CmdCfg {
+	return kpt.NewCmdConfig(b.t,
+		kpt.WithDir(b.buildDir),
+		kpt.WithEnv(b.BlueprintTestConfig.Spec.EnvVars),
+		kpt.WithRetryableErrors(b.BlueprintTestConfig.Spec.RetryableErrors),
+	)
+}
+
 // getDefaultBuildDir returns a temporary build directory for hydrated configs.
 func (b *KRMBlueprintTest) getDefaultBuildDir() string {
 	buildDir := path.Join(utils.GetWD(b.t), tmpBuildDir)
@@ -256,7 +294,7 @@ func (b *KRMBlueprintTest) updatePkg() {
 			commit = g.GetLatestCommit()
 		}
 	}
-	b.kpt.RunCmd("pkg", "update", fmt.Sprintf(".@%s", commit))
+	b.kpt.RunCmdContext(b.stageContext(), "pkg", "update", fmt.Sprintf(".@%s", commit))
 
 }
 
@@ -267,20 +305,20 @@ func (b *KRMBlueprintTest) DefaultInit(assert *assert.Assertions) {
 		b.updatePkg()
 	}
 	b.updateSetters()
-	kpt.NewCmdConfig(b.t, kpt.WithDir(b.buildDir)).RunCmd("fn", "render")
-	b.kpt.RunCmd("live", "install-resource-group")
-	b.kpt.RunCmd("live", "init")
+	b.newKptCmdConfig().RunCmdContext(b.stageContext(), "fn", "render")
+	b.kpt.RunCmdContext(b.stageContext(), "live", "install-resource-group")
+	b.kpt.RunCmdContext(b.stageContext(), "live", "init")
 }
 
 // DefaultApply installs resource-group, initializes inventory, applies pkg and polls resource statuses until current.
 func (b *KRMBlueprintTest) DefaultApply(assert *assert.Assertions) {
-	b.kpt.RunCmd("live", "apply")
-	b.kpt.RunCmd("live", "status", "--output", "json", "--poll-until", "current", "--timeout", b.timeout)
+	b.kpt.RunCmdContext(b.stageContext(), "live", "apply")
+	b.kpt.RunCmdContext(b.stageContext(), "live", "status", "--output", "json", "--poll-until", "current", "--timeout", b.timeout)
 }
 
 // DefaultVerify asserts all resources are status successful
 func (b *KRMBlueprintTest) DefaultVerify(assert *assert.Assertions) {
-	jsonOp := b.kpt.RunCmd("live", "apply", "--output", "json")
+	jsonOp := b.kpt.RunCmdContext(b.stageContext(), "live", "apply", "--output", "json")
 
 	// assert each resource status is successful
 	resourceStatus, err := kpt.GetPkgApplyResourcesStatus(jsonOp)
@@ -298,8 +336,16 @@ func (b *KRMBlueprintTest) DefaultVerify(assert *assert.Assertions) {
 
 // DefaultTeardown destroys resources from cluster and polls until deleted.
 func (b *KRMBlueprintTest) DefaultTeardown(assert *assert.Assertions) {
-	b.kpt.RunCmd("live", "destroy")
-	b.kpt.RunCmd("live", "status", "--output", "json", "--poll-until", "deleted", "--timeout", b.timeout)
+	b.kpt.RunCmdContext(b.stageContext(), "live", "destroy")
+	b.kpt.RunCmdContext(b.stageContext(), "live", "status", "--output", "json", "--poll-until", "deleted", "--timeout", b.timeout)
+}
+
+// stageTimeout returns the timeout for stage set via WithStageTimeout or the test config.
+func (b *KRMBlueprintTest) stageTimeout(stage string) time.Duration {
+	if d, ok := b.stageTimeouts[stage]; ok {
+		return d
+	}
+	return b.BlueprintTestConfig.StageTimeout(stage)
 }
 
 // ShouldSkip checks if a test should be skipped
@@ -367,10 +413,31 @@ func (b *KRMBlueprintTest) Test() {
 	}
 	a := assert.New(b.t)
 	// run stages
-	utils.RunStage("init", func() { b.Init(a) })
-	defer utils.RunStage("teardown", func() { b.Teardown(a) })
-	utils.RunStage("apply", func() { b.Apply(a) })
-	utils.RunStage("verify", func() { b.Verify(a) })
+	b.runStage("init", func() { b.Init(a) })
+	defer b.runStage("teardown", func() { b.Teardown(a) })
+	b.runStage("apply", func() { b.Apply(a) })
+	b.runStage("verify", func() { b.Verify(a) })
+}
+
+// runStage runs stage with its timeout unless the stage is disabled by the test config.
+func (b *KRMBlueprintTest) runStage(stage string, fn func()) {
+	if !b.BlueprintTestConfig.RunsStage(stage) {
+		b.logger.Logf(b.t, "Skipping stage %s due to config %s", stage, b.BlueprintTestConfig.Path)
+		return
+	}
+	utils.RunStageWithTimeout(b.t, stage, b.stageTimeout(stage), func(ctx context.Context) {
+		b.stageCtx = ctx
+		defer func() { b.stageCtx = nil }()
+		fn()
+	})
+}
+
+// stageContext returns the context of the running stage.
+func (b *KRMBlueprintTest) stageContext() context.Context {
+	if b.stageCtx == nil {
+		return context.Background()
+	}
+	return b.stageCtx
 }
 
 // GetBuildDir returns the temporary build dir created for hydrating config. Defaults to .build/test-name.
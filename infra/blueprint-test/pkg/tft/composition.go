@@ -0,0 +1,291 @@
+/**
+ * Copyright 2026 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tft
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/utils"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/mitchellh/go-testing-interface"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// outputExprRe matches output expressions of an output name followed by attributes and indexes, such as network.subnets[0].id.
+var outputExprRe = regexp.MustCompile(`^[A-Za-z_][\w-]*(\.[A-Za-z_][\w-]*|\[\d+\])*$`)
+
+// TFCompositionTest tests a composition of Terraform blueprints such as network, cluster and app.
+// Components are applied in order with outputs of earlier components wired into variables of later
+// components, and torn down in reverse order.
+type TFCompositionTest struct {
+	t                 testing.TB              // TestingT or TestingB
+	components        []*compositionComponent // components in apply order
+	connections       []compositionConnection // connections set with options
+	connectionSources map[string]string       // connection sources of metadata.yaml mapped to component names
+	logger            *logger.Logger          // custom logger
+}
+
+// compositionComponent is a blueprint of a composition.
+type compositionComponent struct {
+	name        string                  // name referred to by connections
+	dir         string                  // directory containing Terraform configs
+	opts        []tftOption             // options of the blueprint test
+	test        *TFBlueprintTest        // blueprint test of the component
+	vars        map[string]interface{}  // variables set with options
+	connections []compositionConnection // connections wiring outputs of earlier components to variables
+}
+
+// compositionConnection wires an output of a source component to a variable of a component.
+type compositionConnection struct {
+	component  string // component with the variable
+	variable   string // variable set to the output
+	inputPath  string // optional dot separated attribute of an object variable set to the output
+	source     string // component with the output
+	outputExpr string // output name optionally followed by attributes and indexes
+}
+
+type compositionOption func(*TFCompositionTest)
+
+// WithComponent adds the blueprint in dir as component name, applied after previously added components.
+// opts configure the blueprint test of the component.
+func WithComponent(name, dir string, opts ...tftOption) compositionOption {
+	return func(f *TFCompositionTest) {
+		f.components = append(f.components, &compositionComponent{name: name, dir: dir, opts: opts})
+	}
+}
+
+// WithConnection sets variable of component to the output expression outputExpr of an earlier component source.
+// outputExpr is an output name optionally followed by attributes and indexes such as network.subnets[0].id.
+// Connections set with options take precedence over connections of metadata.yaml.
+func WithConnection(component, variable, source, outputExpr string) compositionOption {
+	return func(f *TFCompositionTest) {
+		f.connections = append(f.connections, compositionConnection{component: component, variable: variable, source: source, outputExpr: outputExpr})
+	}
+}
+
+// WithConnectionSources wires variables using the connections declared in metadata.yaml of components.
+// sources maps connection sources such as github.com/terraform-google-modules/terraform-google-network
+// to component names. Connections to sources of later or unknown components are ignored.
+func WithConnectionSources(sources map[string]string) compositionOption {
+	return func(f *TFCompositionTest) {
+		f.connectionSources = sources
+	}
+}
+
+// WithCompositionLogger sets a custom logger for the composition and its components.
+func WithCompositionLogger(logger *logger.Logger) compositionOption {
+	return func(f *TFCompositionTest) {
+		f.logger = logger
+	}
+}
+
+// NewTFCompositionTest sets defaults, validates and returns a TFCompositionTest.
+func NewTFCompositionTest(t testing.TB, opts ...compositionOption) *TFCompositionTest {
+	c := &TFCompositionTest{t: t}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.logger == nil {
+		c.logger = utils.GetLoggerFromT()
+	}
+	if len(c.components) == 0 {
+		t.Fatalf("composition has no components")
+	}
+	index := map[string]int{}
+	for i, comp := range c.components {
+		if _, exists := index[comp.name]; exists {
+			t.Fatalf("duplicate component %s", comp.name)
+		}
+		index[comp.name] = i
+		comp.test = NewTFBlueprintTest(t, append([]tftOption{WithTFDir(comp.dir), WithLogger(c.logger)}, comp.opts...)...)
+		comp.vars = comp.test.vars
+		comp.connections = c.metadataConnections(comp, index)
+	}
+	for _, conn := range c.connections {
+		i, exists := index[conn.component]
+		if !exists {
+			t.Fatalf("unknown component %s of connection to %s", conn.component, conn.variable)
+		}
+		if j, exists := index[conn.source]; !exists || j >= i {
+			t.Fatalf("source %s of connection to %s.%s must be an earlier component", conn.source, conn.component, conn.variable)
+		}
+		if !outputExprRe.MatchString(conn.outputExpr) {
+			t.Fatalf("unsupported output expression %s of connection to %s.%s", conn.outputExpr, conn.component, conn.variable)
+		}
+		c.components[i].connections = append(c.components[i].connections, conn)
+	}
+	return c
+}
+
+// connectionsMetadata is the part of a blueprint metadata.yaml declaring variable connections.
+type connectionsMetadata struct {
+	Spec struct {
+		Interfaces struct {
+			Variables []struct {
+				Name        string `yaml:"name"`
+				Connections []struct {
+					Source struct {
+						Source string `yaml:"source"`
+					} `yaml:"source"`
+					Spec struct {
+						OutputExpr string `yaml:"outputExpr"`
+						InputPath  string `yaml:"inputPath"`
+					} `yaml:"spec"`
+				} `yaml:"connections"`
+			} `yaml:"variables"`
+		} `yaml:"interfaces"`
+	} `yaml:"spec"`
+}
+
+// metadataConnections returns the connections of comp declared in its metadata.yaml to the earlier components in index.
+// The first connection for a variable and input path is used.
+func (c *TFCompositionTest) metadataConnections(comp *compositionComponent, index map[string]int) []compositionConnection {
+	if len(c.connectionSources) == 0 {
+		return nil
+	}
+	metadataPath := filepath.Join(comp.dir, metadataFilename)
+	data, err := os.ReadFile(metadataPath)
+	if errors.Is(err, os.ErrNotExist) {
+		c.logger.Logf(c.t, "No %s found for component %s, skipping connections", metadataFilename, comp.name)
+		return nil
+	}
+	if err != nil {
+		c.t.Fatalf("error reading connections from %s: %v", metadataPath, err)
+	}
+	var metadata connectionsMetadata
+	if err := yaml.Unmarshal(data, &metadata); err != nil {
+		c.t.Fatalf("error parsing connections from %s: %v", metadataPath, err)
+	}
+	connections := []compositionConnection{}
+	connected := map[string]bool{}
+	for _, v := range metadata.Spec.Interfaces.Variables {
+		for _, conn := range v.Connections {
+			source, exists := c.connectionSources[conn.Source.Source]
+			if !exists {
+				continue
+			}
+			// components are added to index in order, so only earlier components can be sources
+			if _, earlier := index[source]; !earlier || source == comp.name {
+				continue
+			}
+			if !outputExprRe.MatchString(conn.Spec.OutputExpr) {
+				c.logger.Logf(c.t, "Skipping connection of %s.%s with unsupported output expression %s", comp.name, v.Name, conn.Spec.OutputExpr)
+				continue
+			}
+			key := v.Name + "." + conn.Spec.InputPath
+			if connected[key] {
+				continue
+			}
+			connected[key] = true
+			connections = append(connections, compositionConnection{
+				component:  comp.name,
+				variable:   v.Name,
+				inputPath:  conn.Spec.InputPath,
+				source:     source,
+				outputExpr: conn.Spec.OutputExpr,
+			})
+		}
+	}
+	return connections
+}
+
+// component returns the component name.
+func (c *TFCompositionTest) component(name string) *compositionComponent {
+	for _, comp := range c.components {
+		if comp.name == name {
+			return comp
+		}
+	}
+	c.t.Fatalf("unknown component %s", name)
+	return nil
+}
+
+// wire sets the variables of comp to the variables set with options and the outputs of its connections.
+func (c *TFCompositionTest) wire(comp *compositionComponent) {
+	vars := maps.Clone(comp.vars)
+	if vars == nil {
+		vars = map[string]interface{}{}
+	}
+	for _, conn := range comp.connections {
+		name, path, _ := strings.Cut(strings.ReplaceAll(strings.ReplaceAll(conn.outputExpr, "[", "."), "]", ""), ".")
+		value := c.component(conn.source).test.getOutput(name)
+		if path != "" {
+			value = value.Get(path)
+		}
+		if !value.Exists() {
+			c.t.Fatalf("output expression %s of component %s for %s.%s not found", conn.outputExpr, conn.source, comp.name, conn.variable)
+		}
+		c.logger.Logf(c.t, "Wiring %s.%s to %s of %s", comp.name, strings.TrimSuffix(conn.variable+"."+conn.inputPath, "."), conn.outputExpr, conn.source)
+		setVar(vars, conn.variable, conn.inputPath, value.Value())
+	}
+	comp.test.vars = vars
+}
+
+// setVar sets variable in vars to value, or its object attribute at the dot separated inputPath.
+func setVar(vars map[string]interface{}, variable, inputPath string, value interface{}) {
+	if inputPath == "" {
+		vars[variable] = value
+		return
+	}
+	obj, ok := vars[variable].(map[string]interface{})
+	if !ok {
+		obj = map[string]interface{}{}
+		vars[variable] = obj
+	}
+	attrs := strings.Split(inputPath, ".")
+	for _, attr := range attrs[:len(attrs)-1] {
+		child, ok := obj[attr].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			obj[attr] = child
+		}
+		obj = child
+	}
+	obj[attrs[len(attrs)-1]] = value
+}
+
+// Test runs init, plan, guardrail, apply and verify of the components in order and tears down
+// applied components in reverse order, even if a later component fails.
+// Variables of a component are wired from outputs of earlier components before each stage after init.
+func (c *TFCompositionTest) Test() {
+	a := assert.New(c.t)
+	for _, comp := range c.components {
+		b := comp.test
+		if b.ShouldSkip() {
+			c.logger.Logf(c.t, "Skipping composition due to config %s of component %s", b.BlueprintTestConfig.Path, comp.name)
+			c.t.SkipNow()
+			return
+		}
+		c.logger.Logf(c.t, "Testing component %s in %s", comp.name, comp.dir)
+		b.runStage(initStage, func() { b.Init(a) })
+		defer b.runStage(teardownStage, func() {
+			c.logger.Logf(c.t, "Tearing down component %s", comp.name)
+			c.wire(comp)
+			b.Teardown(a)
+		})
+		b.runStage(planStage, func() { c.wire(comp); b.Plan(a) })
+		b.runStage(guardrailStage, func() { c.wire(comp); b.Guardrail(a) })
+		b.runStage(applyStage, func() { c.wire(comp); b.Apply(a) })
+		b.runStage(verifyStage, func() { c.wire(comp); b.Verify(a) })
+	}
+}
@@ -0,0 +1,156 @@
+/**
+ * Copyright 2026 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tft
+
+import (
+	"maps"
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	testingiface "github.com/mitchellh/go-testing-interface"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	compositionTestDir = filepath.Join("testdata", "composition")
+	compositionOutputs = map[string]string{
+		"network": `{
+  "network_name": {"value": "vpc-name"},
+  "network": {"value": {"name": "vpc"}},
+  "subnets": {"value": [{"id": "sub-1", "region": "us-central1"}, {"id": "sub-2", "region": "us-east1"}]}
+}`,
+		"cluster": `{"endpoint": {"value": "10.0.0.1"}}`,
+		"app":     `{}`,
+	}
+)
+
+// compositionRecorder records the stages run and variables applied by components of a composition.
+type compositionRecorder struct {
+	events []string
+	vars   map[string]map[string]interface{}
+}
+
+// newCompositionTest returns a network, cluster and app composition with stubbed stages.
+// The apply stage of the component failApply fails.
+func newCompositionTest(t testingiface.T, failApply string, opts ...compositionOption) (*TFCompositionTest, *compositionRecorder) {
+	r := &compositionRecorder{vars: map[string]map[string]interface{}{}}
+	components := []compositionOption{
+		WithComponent("network", filepath.Join(compositionTestDir, "network"), WithPlanFile(planTestFile)),
+		WithComponent("cluster", filepath.Join(compositionTestDir, "cluster"), WithPlanFile(planTestFile), WithVars(map[string]interface{}{"node_count": 3})),
+		WithComponent("app", filepath.Join(compositionTestDir, "app"), WithPlanFile(planTestFile)),
+		WithCompositionLogger(logger.Discard),
+	}
+	c := NewTFCompositionTest(t, append(components, opts...)...)
+	for _, comp := range c.components {
+		name, b := comp.name, comp.test
+		outputs := compositionOutputs[name]
+		b.outputsJSON = func() string { return outputs }
+		b.idempotencyPlan = func() *terraform.PlanStruct { return &terraform.PlanStruct{} }
+		b.DefineInit(func(*assert.Assertions) { r.events = append(r.events, "init "+name) })
+		b.DefineApply(func(*assert.Assertions) {
+			r.events = append(r.events, "apply "+name)
+			r.vars[name] = maps.Clone(b.GetTFOptions().Vars)
+			if name == failApply {
+				t.Fatalf("apply %s failed", name)
+			}
+		})
+		b.DefineVerify(func(*assert.Assertions) { r.events = append(r.events, "verify "+name) })
+		b.DefineTeardown(func(*assert.Assertions) { r.events = append(r.events, "teardown "+name) })
+	}
+	return c, r
+}
+
+func TestComposition(t *testing.T) {
+	c, r := newCompositionTest(t, "",
+		WithConnectionSources(map[string]string{
+			"github.com/terraform-google-modules/terraform-google-network": "network",
+			"github.com/terraform-google-modules/terraform-google-app":     "app",
+		}),
+		WithConnection("app", "endpoint", "cluster", "endpoint"),
+		WithConnection("app", "network_name", "network", "network_name"))
+	c.Test()
+
+	assert := assert.New(t)
+	assert.Equal([]string{
+		"init network", "apply network", "verify network",
+		"init cluster", "apply cluster", "verify cluster",
+		"init app", "apply app", "verify app",
+		"teardown app", "teardown cluster", "teardown network",
+	}, r.events)
+	assert.Empty(r.vars["network"])
+	assert.Equal(map[string]interface{}{
+		"node_count":   3,
+		"network_name": "vpc",
+		"subnet":       map[string]interface{}{"id": "sub-2", "region": "us-east1"},
+	}, r.vars["cluster"])
+	assert.Equal(map[string]interface{}{"endpoint": "10.0.0.1", "network_name": "vpc-name"}, r.vars["app"])
+}
+
+func TestCompositionPartialFailure(t *testing.T) {
+	ft := &fatalT{}
+	c, r := newCompositionTest(ft, "cluster")
+	func() {
+		defer func() {
+			assert.NotNil(t, recover(), "composition should fail")
+		}()
+		c.Test()
+	}()
+
+	assert.Equal(t, "apply cluster failed", ft.msg)
+	assert.Equal(t, []string{
+		"init network", "apply network", "verify network",
+		"init cluster", "apply cluster",
+		"teardown cluster", "teardown network",
+	}, r.events)
+}
+
+func TestCompositionInvalidConnections(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  compositionOption
+		want string
+	}{
+		{name: "later source", opt: WithConnection("cluster", "app_url", "app", "url"), want: "source app of connection to cluster.app_url must be an earlier component"},
+		{name: "unknown component", opt: WithConnection("db", "network_name", "network", "network_name"), want: "unknown component db of connection to network_name"},
+		{name: "unsupported expression", opt: WithConnection("app", "network_name", "network", `lookup(network, "name")`), want: `unsupported output expression lookup(network, "name") of connection to app.network_name`},
+		{name: "duplicate component", opt: WithComponent("app", filepath.Join(compositionTestDir, "app"), WithPlanFile(planTestFile)), want: "duplicate component app"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ft := &fatalT{}
+			func() {
+				defer func() { recover() }()
+				newCompositionTest(ft, "", tt.opt)
+			}()
+			assert.Equal(t, tt.want, ft.msg)
+		})
+	}
+}
+
+func TestSetVar(t *testing.T) {
+	vars := map[string]interface{}{"name": "foo"}
+	setVar(vars, "network", "", "vpc")
+	setVar(vars, "subnet", "id", "sub-1")
+	setVar(vars, "subnet", "secondary.range", "10.0.0.0/24")
+	assert.Equal(t, map[string]interface{}{
+		"name":    "foo",
+		"network": "vpc",
+		"subnet":  map[string]interface{}{"id": "sub-1", "secondary": map[string]interface{}{"range": "10.0.0.0/24"}},
+	}, vars)
+}
@@ -0,0 +1,116 @@
+/**
+ * Copyright 2026 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tft
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/discovery"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+// gpuMachineFamilies are the prefixes of machine types with attached GPUs.
+var gpuMachineFamilies = []string{"a2-", "a3-", "a4-", "g2-"}
+
+// GuardrailViolations returns the violations of guardrails by the resources planned in ps.
+// Machine types and GPUs are found in machine_type and guest_accelerator attributes at any depth,
+// such as node_config.0.machine_type of node pools.
+func GuardrailViolations(ps *terraform.PlanStruct, g discovery.Guardrails) []string {
+	violations := []string{}
+	resourceTypes := make([]string, 0, len(g.MaxResources))
+	for resourceType := range g.MaxResources {
+		resourceTypes = append(resourceTypes, resourceType)
+	}
+	sort.Strings(resourceTypes)
+	for _, resourceType := range resourceTypes {
+		addresses := PlanResourcesByType(ps, resourceType)
+		if max := g.MaxResources[resourceType]; len(addresses) > max {
+			violations = append(violations, fmt.Sprintf("%d %s resources planned, at most %d allowed: %v", len(addresses), resourceType, max, addresses))
+		}
+	}
+
+	addresses := make([]string, 0, len(ps.ResourcePlannedValuesMap))
+	for address := range ps.ResourcePlannedValuesMap {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	for _, address := range addresses {
+		attributes := map[string]interface{}{}
+		flattenAttributes("", ps.ResourcePlannedValuesMap[address].AttributeValues, attributes)
+		paths := make([]string, 0, len(attributes))
+		for p := range attributes {
+			paths = append(paths, p)
+		}
+		sort.Strings(paths)
+		for _, p := range paths {
+			segments := strings.Split(p, ".")
+			switch attribute := segments[len(segments)-1]; {
+			case attribute == "machine_type":
+				machineType, ok := attributes[p].(string)
+				if !ok {
+					continue
+				}
+				// machine types may be given as URLs such as zones/us-central1-a/machineTypes/e2-medium
+				machineType = path.Base(machineType)
+				if len(g.MachineTypes) > 0 && !slices.Contains(g.MachineTypes, machineType) {
+					violations = append(violations, fmt.Sprintf("%s: machine type %s at %s not allowed, one of %v expected", address, machineType, p, g.MachineTypes))
+				}
+				if g.DenyGPUs && isGPUMachineType(machineType) {
+					violations = append(violations, fmt.Sprintf("%s: GPU machine type %s at %s not allowed", address, machineType, p))
+				}
+			case attribute == "count" && g.DenyGPUs && slices.Contains(segments, "guest_accelerator"):
+				if count, ok := attributes[p].(float64); ok && count > 0 {
+					accelerator := attributes[strings.TrimSuffix(p, "count")+"type"]
+					violations = append(violations, fmt.Sprintf("%s: %v GPUs of type %v at %s not allowed", address, count, accelerator, strings.TrimSuffix(p, ".count")))
+				}
+			}
+		}
+	}
+	return violations
+}
+
+// isGPUMachineType returns true if machine types of the series of machineType have attached GPUs.
+func isGPUMachineType(machineType string) bool {
+	for _, family := range gpuMachineFamilies {
+		if strings.HasPrefix(machineType, family) {
+			return true
+		}
+	}
+	return false
+}
+
+// AssertGuardrails asserts that the resources planned in ps do not violate guardrails.
+func AssertGuardrails(a *assert.Assertions, ps *terraform.PlanStruct, g discovery.Guardrails) bool {
+	violations := GuardrailViolations(ps, g)
+	return a.Emptyf(violations, "plan violates guardrails:\n%s", strings.Join(violations, "\n"))
+}
+
+// Guardrail asserts that the plan does not violate the guardrails configured with options or the test config.
+// If no guardrails are configured, this stage is skipped.
+func (b *TFBlueprintTest) Guardrail(assert *assert.Assertions) {
+	b.outputs = nil
+	if !b.guardrails.Enabled() {
+		b.logger.Logf(b.t, "skipping guardrail as no guardrails configured")
+		return
+	}
+	AssertGuardrails(assert, b.stagePlan(), b.guardrails)
+}
@@ -0,0 +1,115 @@
+/**
+ * Copyright 2026 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tft
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/discovery"
+	"github.com/stretchr/testify/assert"
+)
+
+var guardrailsTestFile = filepath.Join("testdata", "plan", "guardrails.json")
+
+func TestGuardrailViolations(t *testing.T) {
+	tests := []struct {
+		name       string
+		guardrails discovery.Guardrails
+		want       []string
+	}{
+		{name: "none"},
+		{
+			name:       "max resources",
+			guardrails: discovery.Guardrails{MaxResources: map[string]int{"google_container_node_pool": 2, "google_compute_instance": 1}},
+			want:       []string{`3 google_container_node_pool resources planned, at most 2 allowed: [google_container_node_pool.pools["default"] google_container_node_pool.pools["gpu"] google_container_node_pool.pools["highmem"]]`},
+		},
+		{
+			name:       "denied resource type",
+			guardrails: discovery.Guardrails{MaxResources: map[string]int{"google_compute_instance": 0}},
+			want:       []string{"1 google_compute_instance resources planned, at most 0 allowed: [google_compute_instance.trainer]"},
+		},
+		{
+			name:       "no GPUs",
+			guardrails: discovery.Guardrails{DenyGPUs: true},
+			want: []string{
+				"google_compute_instance.trainer: GPU machine type a2-highgpu-1g at machine_type not allowed",
+				`google_container_node_pool.pools["gpu"]: 2 GPUs of type nvidia-tesla-t4 at node_config.0.guest_accelerator.0 not allowed`,
+			},
+		},
+		{
+			name:       "machine types",
+			guardrails: discovery.Guardrails{MachineTypes: []string{"e2-standard-4", "n1-standard-8"}},
+			want: []string{
+				"google_compute_instance.trainer: machine type a2-highgpu-1g at machine_type not allowed, one of [e2-standard-4 n1-standard-8] expected",
+				`google_container_node_pool.pools["highmem"]: machine type n2-highmem-64 at node_config.0.machine_type not allowed, one of [e2-standard-4 n1-standard-8] expected`,
+			},
+		},
+	}
+	ps := LoadPlanFile(t, guardrailsTestFile)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GuardrailViolations(ps, tt.guardrails)
+			if len(tt.want) == 0 {
+				assert.Empty(t, got)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGuardrailStage(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []tftOption
+		wantErrors int
+	}{
+		{name: "skipped"},
+		{name: "within budget", opts: []tftOption{WithMaxResources("google_container_node_pool", 3), WithMachineTypes("e2-standard-4", "n1-standard-8", "n2-highmem-64", "a2-highgpu-1g")}},
+		{name: "over budget", opts: []tftOption{WithMaxResources("google_container_node_pool", 2)}, wantErrors: 1},
+		{name: "GPUs", opts: []tftOption{WithoutGPUs()}, wantErrors: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]tftOption{WithTFDir(filepath.Join("testdata", "plan")), WithPlanFile(guardrailsTestFile)}, tt.opts...)
+			bpt := NewTFBlueprintTest(t, opts...)
+			a := &errorT{}
+			bpt.Guardrail(assert.New(a))
+			assert.Len(t, a.errors, tt.wantErrors)
+			if tt.wantErrors > 0 {
+				assert.Contains(t, a.errors[0], "plan violates guardrails")
+			}
+		})
+	}
+}
+
+func TestApplyGuardrailsConfig(t *testing.T) {
+	b := &TFBlueprintTest{guardrails: discovery.Guardrails{MaxResources: map[string]int{"google_container_node_pool": 1}, MachineTypes: []string{"e2-medium"}}}
+	b.BlueprintTestConfig.Spec.Guardrails = discovery.Guardrails{
+		MaxResources: map[string]int{"google_container_node_pool": 2, "google_compute_instance": 2},
+		DenyGPUs:     true,
+		MachineTypes: []string{"e2-small"},
+	}
+	b.applyTestConfig()
+
+	assert.Equal(t, discovery.Guardrails{
+		MaxResources: map[string]int{"google_container_node_pool": 1, "google_compute_instance": 2},
+		DenyGPUs:     true,
+		MachineTypes: []string{"e2-medium", "e2-small"},
+	}, b.guardrails)
+}
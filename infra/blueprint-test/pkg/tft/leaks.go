@@ -0,0 +1,117 @@
+/**
+ * Copyright 2026 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tft
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	gotest "testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/cai"
+	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	defaultLeakCheckTimeout  = 5 * time.Minute
+	defaultLeakCheckInterval = 10 * time.Second
+	maxLeakCheckInterval     = time.Minute
+)
+
+// AssetFetcher returns the Cloud Asset Inventory resources of project as output by gcloud asset list.
+type AssetFetcher func(project string) gjson.Result
+
+// defaultAssetFetcher returns the project resources using Cloud Asset Inventory.
+func (b *TFBlueprintTest) defaultAssetFetcher(project string) gjson.Result {
+	t, ok := b.t.(gotest.TB)
+	if !ok {
+		b.t.Fatalf("leak check requires a *testing.T or *testing.B")
+	}
+	return cai.GetProjectResources(t, project)
+}
+
+// leakCheckProject returns the project checked for leaked assets.
+// Defaults to the project_id output of the setup.
+func (b *TFBlueprintTest) leakCheckProject() string {
+	if b.leakProject != "" {
+		return b.leakProject
+	}
+	return b.GetTFSetupStringOutput("project_id")
+}
+
+// shouldCheckLeaks returns true if the test runs all stages from init to teardown
+// so that assets before init and after teardown can be compared.
+func (b *TFBlueprintTest) shouldCheckLeaks() bool {
+	if stage := os.Getenv(utils.RUN_STAGE_ENV_VAR); stage != "" {
+		b.logger.Logf(b.t, "Skipping leak check as only stage %s is run", stage)
+		return false
+	}
+	if !b.BlueprintTestConfig.RunsStage(initStage) || !b.BlueprintTestConfig.RunsStage(teardownStage) {
+		b.logger.Logf(b.t, "Skipping leak check as init or teardown is not run due to config %s", b.BlueprintTestConfig.Path)
+		return false
+	}
+	return true
+}
+
+// projectAssets returns the names of the assets in project mapped to their asset types.
+func (b *TFBlueprintTest) projectAssets(project string) map[string]string {
+	assets := map[string]string{}
+	for _, asset := range b.assetFetcher(project).Array() {
+		assets[asset.Get("name").String()] = asset.Get("assetType").String()
+	}
+	return assets
+}
+
+// leakedAssets returns the sorted assets of project which are not in before.
+func (b *TFBlueprintTest) leakedAssets(project string, before map[string]string) []string {
+	leaked := []string{}
+	for name, assetType := range b.projectAssets(project) {
+		if _, existed := before[name]; !existed {
+			leaked = append(leaked, fmt.Sprintf("%s %s", assetType, name))
+		}
+	}
+	sort.Strings(leaked)
+	return leaked
+}
+
+// checkLeaks asserts that assets of project which did not exist before the test
+// have been deleted by teardown.
+//
+// Cloud Asset Inventory is eventually consistent so deleted assets may still be listed
+// shortly after teardown. Assets are listed again with an exponential backoff until
+// none are leaked or the leak check timeout expires.
+func (b *TFBlueprintTest) checkLeaks(assert *assert.Assertions, project string, before map[string]string) {
+	b.logger.Logf(b.t, "Checking for assets leaked in project %s", project)
+	deadline := time.Now().Add(b.leakCheckTimeout)
+	interval := b.leakCheckInterval
+	leaked := b.leakedAssets(project, before)
+	for len(leaked) > 0 && time.Now().Before(deadline) {
+		wait := min(interval, time.Until(deadline))
+		b.logger.Logf(b.t, "%d assets created by the test still exist in project %s, checking again in %s", len(leaked), project, wait)
+		time.Sleep(wait)
+		interval = min(interval*2, maxLeakCheckInterval)
+		leaked = b.leakedAssets(project, before)
+	}
+	if len(leaked) == 0 {
+		return
+	}
+	assert.Failf("assets leaked after teardown", "%d assets created by the test still exist in project %s after %s:\n%s", len(leaked), project, b.leakCheckTimeout, strings.Join(leaked, "\n"))
+}
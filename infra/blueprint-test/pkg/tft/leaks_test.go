@@ -0,0 +1,121 @@
+/**
+ * Copyright 2026 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tft
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
+)
+
+// fixtureAssetFetcher returns a fetcher returning the asset fixtures in order, repeating the last one,
+// and the projects fetched.
+func fixtureAssetFetcher(t *testing.T, fixtures ...string) (AssetFetcher, *[]string) {
+	projects := []string{}
+	return func(project string) gjson.Result {
+		projects = append(projects, project)
+		fixture := fixtures[min(len(projects), len(fixtures))-1]
+		assets, err := os.ReadFile(filepath.Join("testdata", "assets", fixture))
+		assert.NoError(t, err)
+		return gjson.ParseBytes(assets)
+	}, &projects
+}
+
+func TestCheckLeaks(t *testing.T) {
+	tests := []struct {
+		name       string
+		project    string
+		after      []string
+		wantLeaked []string
+	}{
+		{name: "no leaks", project: "my-project", after: []string{"before.json"}},
+		{name: "deleted eventually", project: "my-project", after: []string{"after.json", "after.json", "before.json"}},
+		{
+			name:    "leaks",
+			project: "my-project",
+			after:   []string{"after.json"},
+			wantLeaked: []string{
+				"compute.googleapis.com/Disk //compute.googleapis.com/projects/my-project/zones/us-central1-a/disks/vm-disk",
+				"iam.googleapis.com/ServiceAccount //iam.googleapis.com/projects/my-project/serviceAccounts/vm-sa@my-project.iam.gserviceaccount.com",
+			},
+		},
+		{name: "setup project", after: []string{"before.json"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher, projects := fixtureAssetFetcher(t, append([]string{"before.json"}, tt.after...)...)
+			bpt := NewTFBlueprintTest(t,
+				WithTFDir(filepath.Join("testdata", "plan")),
+				WithPlanFile(planTestFile),
+				WithSetupOutputs(map[string]interface{}{"project_id": "setup-project"}),
+				WithLeakCheck(tt.project),
+				WithAssetFetcher(fetcher),
+				WithLeakCheckTimeout(100*time.Millisecond))
+			bpt.leakCheckInterval = 10 * time.Millisecond
+			project := bpt.leakCheckProject()
+			before := bpt.projectAssets(project)
+			a := &errorT{}
+			bpt.checkLeaks(assert.New(a), project, before)
+
+			wantProject := tt.project
+			if wantProject == "" {
+				wantProject = "setup-project"
+			}
+			for _, p := range *projects {
+				assert.Equal(t, wantProject, p)
+			}
+			if len(tt.wantLeaked) == 0 {
+				assert.Len(t, *projects, len(tt.after)+1, "assets should be listed until none are leaked")
+				assert.Empty(t, a.errors)
+				return
+			}
+			assert.GreaterOrEqual(t, len(*projects), 3, "assets should be listed until the timeout expires")
+			assert.Len(t, a.errors, 1)
+			assert.Contains(t, a.errors[0], "2 assets created by the test still exist in project my-project after 100ms")
+			for _, leaked := range tt.wantLeaked {
+				assert.Contains(t, a.errors[0], leaked)
+			}
+			assert.NotContains(t, a.errors[0], "networks/default")
+		})
+	}
+}
+
+func TestShouldCheckLeaks(t *testing.T) {
+	tests := []struct {
+		name     string
+		runStage string
+		stages   []string
+		want     bool
+	}{
+		{name: "all stages", want: true},
+		{name: "single stage", runStage: "apply"},
+		{name: "configured stages", stages: []string{"init", "apply"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(utils.RUN_STAGE_ENV_VAR, tt.runStage)
+			bpt := NewTFBlueprintTest(t, WithTFDir(filepath.Join("testdata", "plan")), WithPlanFile(planTestFile), WithLeakCheck(""))
+			bpt.BlueprintTestConfig.Spec.Stages = tt.stages
+			assert.Equal(t, tt.want, bpt.shouldCheckLeaks())
+		})
+	}
+}
@@ -0,0 +1,253 @@
+/**
+ * Copyright 2026 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tft
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// metadataFilename is the blueprint metadata file declaring output types.
+const metadataFilename = "metadata.yaml"
+
+// outputsSnapshot returns all outputs of the TF configs as output by terraform output -json.
+// Outputs are read once per stage and cached until the next stage starts.
+func (b *TFBlueprintTest) outputsSnapshot() gjson.Result {
+	if b.outputs == nil {
+		jsonString := b.outputsJSON()
+		if !gjson.Valid(jsonString) {
+			b.t.Fatalf("Invalid JSON: %s", jsonString)
+		}
+		outputs := gjson.Parse(jsonString)
+		b.outputs = &outputs
+	}
+	return *b.outputs
+}
+
+// defaultOutputsJSON returns all outputs of the TF configs as JSON.
+func (b *TFBlueprintTest) defaultOutputsJSON() string {
+	// allow only parallel reads as Terraform plugin cache isn't concurrent safe
+	rUnlockFn := b.rLockFn()
+	defer rUnlockFn()
+	return terraform.OutputJson(b.t, b.GetTFOptions(), "")
+}
+
+// getOutput returns the value of TF output name from the outputs snapshot.
+// It fails test if the output does not exist.
+func (b *TFBlueprintTest) getOutput(name string) gjson.Result {
+	o := b.outputsSnapshot().Get(gjson.Escape(name))
+	if !o.Exists() {
+		b.t.Fatalf("output %s not found", name)
+	}
+	return o.Get("value")
+}
+
+// GetMapOutput returns TF output for a given key as map.
+// It fails test if given key does not exist or does not output a map or object.
+func (b *TFBlueprintTest) GetMapOutput(name string) map[string]interface{} {
+	v := b.getOutput(name)
+	if !v.IsObject() {
+		b.t.Fatalf("output %s is %s, expected map", name, jsonKind(v))
+	}
+	return v.Value().(map[string]interface{})
+}
+
+// GetNumberOutput returns TF output for a given key as float64.
+// It fails test if given key does not exist or does not output a number.
+func (b *TFBlueprintTest) GetNumberOutput(name string) float64 {
+	v := b.getOutput(name)
+	if v.Type != gjson.Number {
+		b.t.Fatalf("output %s is %s, expected number", name, jsonKind(v))
+	}
+	return v.Float()
+}
+
+// GetBoolOutput returns TF output for a given key as bool.
+// It fails test if given key does not exist or does not output a bool.
+func (b *TFBlueprintTest) GetBoolOutput(name string) bool {
+	v := b.getOutput(name)
+	if v.Type != gjson.True && v.Type != gjson.False {
+		b.t.Fatalf("output %s is %s, expected bool", name, jsonKind(v))
+	}
+	return v.Bool()
+}
+
+// MustOutputMatchesSchema fails test if TF output name does not exist or its value does not match schema.
+// schema is a Terraform type in the JSON form used for BlueprintOutput.type in metadata.yaml,
+// for example "string", []interface{}{"list", "string"} or
+// []interface{}{"object", map[string]interface{}{"id": "string"}}.
+func (b *TFBlueprintTest) MustOutputMatchesSchema(name string, schema interface{}) {
+	if err := matchesType(name, b.getOutput(name), schema); err != nil {
+		b.t.Fatalf("output %s does not match schema: %v", name, err)
+	}
+}
+
+// outputTypesMetadata is the part of a blueprint metadata.yaml declaring output types.
+type outputTypesMetadata struct {
+	Spec struct {
+		Interfaces struct {
+			Outputs []struct {
+				Name string      `yaml:"name"`
+				Type interface{} `yaml:"type"`
+			} `yaml:"outputs"`
+		} `yaml:"interfaces"`
+	} `yaml:"spec"`
+}
+
+// checkOutputTypes asserts that outputs exist and match the types declared in the output types metadata.
+// Outputs without a declared type are not checked.
+func (b *TFBlueprintTest) checkOutputTypes(assert *assert.Assertions) {
+	metadataPath := b.outputTypesMetadata
+	if metadataPath == "" {
+		metadataPath = filepath.Join(b.tfDir, metadataFilename)
+	}
+	data, err := os.ReadFile(metadataPath)
+	if err != nil {
+		b.t.Fatalf("error reading output types from %s: %v", metadataPath, err)
+	}
+	var metadata outputTypesMetadata
+	if err := yaml.Unmarshal(data, &metadata); err != nil {
+		b.t.Fatalf("error parsing output types from %s: %v", metadataPath, err)
+	}
+	outputs := b.outputsSnapshot()
+	for _, o := range metadata.Spec.Interfaces.Outputs {
+		if o.Type == nil {
+			continue
+		}
+		output := outputs.Get(gjson.Escape(o.Name))
+		if !assert.Truef(output.Exists(), "output %s declared in %s not found", o.Name, metadataPath) {
+			continue
+		}
+		assert.NoErrorf(matchesType(o.Name, output.Get("value"), o.Type), "output %s does not match type declared in %s", o.Name, metadataPath)
+	}
+}
+
+// matchesType returns an error if the value v at path does not match the Terraform type typ in JSON form.
+// Null values match any type and dynamic matches any value.
+func matchesType(path string, v gjson.Result, typ interface{}) error {
+	if v.Type == gjson.Null {
+		return nil
+	}
+	switch t := typ.(type) {
+	case string:
+		want := map[string]bool{
+			"string":  v.Type == gjson.String,
+			"number":  v.Type == gjson.Number,
+			"bool":    v.Type == gjson.True || v.Type == gjson.False,
+			"dynamic": true,
+			"any":     true,
+		}
+		matches, known := want[t]
+		if !known {
+			return fmt.Errorf("%s: unsupported type %s", path, t)
+		}
+		if !matches {
+			return fmt.Errorf("%s: got %s, expected %s", path, jsonKind(v), t)
+		}
+		return nil
+	case []interface{}:
+		if len(t) != 2 {
+			return fmt.Errorf("%s: invalid type %v", path, t)
+		}
+		kind, _ := t[0].(string)
+		switch kind {
+		case "list", "set":
+			if !v.IsArray() {
+				return fmt.Errorf("%s: got %s, expected %s", path, jsonKind(v), kind)
+			}
+			for i, elem := range v.Array() {
+				if err := matchesType(fmt.Sprintf("%s.%d", path, i), elem, t[1]); err != nil {
+					return err
+				}
+			}
+			return nil
+		case "tuple":
+			elemTypes, ok := t[1].([]interface{})
+			if !ok {
+				return fmt.Errorf("%s: invalid tuple type %v", path, t[1])
+			}
+			if !v.IsArray() || len(v.Array()) != len(elemTypes) {
+				return fmt.Errorf("%s: got %s, expected tuple of %d elements", path, jsonKind(v), len(elemTypes))
+			}
+			for i, elem := range v.Array() {
+				if err := matchesType(fmt.Sprintf("%s.%d", path, i), elem, elemTypes[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		case "map":
+			if !v.IsObject() {
+				return fmt.Errorf("%s: got %s, expected map", path, jsonKind(v))
+			}
+			var err error
+			v.ForEach(func(key, value gjson.Result) bool {
+				err = matchesType(fmt.Sprintf("%s.%s", path, key.String()), value, t[1])
+				return err == nil
+			})
+			return err
+		case "object":
+			attrTypes, ok := t[1].(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s: invalid object type %v", path, t[1])
+			}
+			if !v.IsObject() {
+				return fmt.Errorf("%s: got %s, expected object", path, jsonKind(v))
+			}
+			attrs := make([]string, 0, len(attrTypes))
+			for attr := range attrTypes {
+				attrs = append(attrs, attr)
+			}
+			sort.Strings(attrs)
+			for _, attr := range attrs {
+				value := v.Get(gjson.Escape(attr))
+				if !value.Exists() {
+					return fmt.Errorf("%s: missing attribute %s", path, attr)
+				}
+				if err := matchesType(fmt.Sprintf("%s.%s", path, attr), value, attrTypes[attr]); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return fmt.Errorf("%s: unsupported type %v", path, t)
+	}
+	return fmt.Errorf("%s: invalid type %v", path, typ)
+}
+
+// jsonKind describes the kind of JSON value v.
+func jsonKind(v gjson.Result) string {
+	switch {
+	case v.IsObject():
+		return "object"
+	case v.IsArray():
+		return "list"
+	case v.Type == gjson.True || v.Type == gjson.False:
+		return "bool"
+	case v.Type == gjson.Number:
+		return "number"
+	case v.Type == gjson.String:
+		return "string"
+	}
+	return "null"
+}
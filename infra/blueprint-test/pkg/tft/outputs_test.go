@@ -0,0 +1,187 @@
+/**
+ * Copyright 2026 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tft
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	testingiface "github.com/mitchellh/go-testing-interface"
+	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
+)
+
+var outputsTestDir = filepath.Join("testdata", "outputs")
+
+// fatalT records the message of a fatal test failure.
+type fatalT struct {
+	testingiface.RuntimeT
+	msg string
+}
+
+func (f *fatalT) Fatalf(format string, args ...interface{}) {
+	f.msg = fmt.Sprintf(format, args...)
+	f.FailNow()
+}
+
+// newOutputsTest returns a test reading outputs from the outputs fixture and the number of reads.
+func newOutputsTest(t *testing.T, tt testingiface.T) (*TFBlueprintTest, *int) {
+	outputs, err := os.ReadFile(filepath.Join(outputsTestDir, "outputs.json"))
+	assert.NoError(t, err)
+	reads := 0
+	return &TFBlueprintTest{
+		t:      tt,
+		tfDir:  outputsTestDir,
+		logger: logger.Discard,
+		outputsJSON: func() string {
+			reads++
+			return string(outputs)
+		},
+	}, &reads
+}
+
+// outputsFatal returns the fatal failure message of fn or an empty string if fn does not fail.
+func outputsFatal(t *testing.T, fn func(b *TFBlueprintTest)) (msg string) {
+	ft := &fatalT{}
+	b, _ := newOutputsTest(t, ft)
+	defer func() {
+		recover()
+		msg = ft.msg
+	}()
+	fn(b)
+	return ""
+}
+
+func TestOutputAccessors(t *testing.T) {
+	b, reads := newOutputsTest(t, t)
+
+	assert := assert.New(t)
+	assert.Equal("my-project", b.GetStringOutput("project_id"))
+	assert.Equal("3", b.GetStringOutput("node_count"))
+	assert.Equal("123456789012", b.GetStringOutput("project_number"))
+	assert.Equal("true", b.GetStringOutput("private"))
+	assert.Equal([]string{"123456789012", "1.5"}, b.GetStringOutputList("zone_counts"))
+	assert.Equal([]string{"subnet-01", "subnet-02"}, b.GetStringOutputList("subnets"))
+	assert.Equal("vpc", b.GetJsonOutput("network").Get("name").String())
+	assert.Equal("my-project", b.GetJsonOutput("").Get("project_id.value").String())
+	assert.Equal(map[string]interface{}{"env": "test"}, b.GetMapOutput("labels"))
+	assert.Equal(3.0, b.GetNumberOutput("node_count"))
+	assert.True(b.GetBoolOutput("private"))
+	b.MustOutputMatchesSchema("network", []interface{}{"object", map[string]interface{}{"id": "string"}})
+	assert.Equal(1, *reads, "outputs should be read once per stage")
+
+	b.outputs = nil
+	b.GetStringOutput("project_id")
+	assert.Equal(2, *reads, "outputs should be read again in a new stage")
+}
+
+func TestOutputAccessorFailures(t *testing.T) {
+	tests := []struct {
+		name string
+		get  func(b *TFBlueprintTest)
+		want string
+	}{
+		{name: "missing", get: func(b *TFBlueprintTest) { b.GetStringOutput("missing") }, want: "output missing not found"},
+		{name: "map", get: func(b *TFBlueprintTest) { b.GetMapOutput("subnets") }, want: "output subnets is list, expected map"},
+		{name: "number", get: func(b *TFBlueprintTest) { b.GetNumberOutput("project_id") }, want: "output project_id is string, expected number"},
+		{name: "bool", get: func(b *TFBlueprintTest) { b.GetBoolOutput("missing") }, want: "output missing not found"},
+		{name: "list", get: func(b *TFBlueprintTest) { b.GetStringOutputList("labels") }, want: "output labels is object, expected list"},
+		{name: "string null", get: func(b *TFBlueprintTest) { b.GetStringOutput("folder_id") }, want: "output folder_id is null, expected a primitive"},
+		{name: "string list", get: func(b *TFBlueprintTest) { b.GetStringOutput("subnets") }, want: "output subnets is list, expected a primitive"},
+		{name: "string object", get: func(b *TFBlueprintTest) { b.GetStringOutput("network") }, want: "output network is object, expected a primitive"},
+		{name: "list of lists", get: func(b *TFBlueprintTest) { b.GetStringOutputList("nested") }, want: "output nested[0] is list, expected a primitive"},
+		{name: "schema", get: func(b *TFBlueprintTest) { b.MustOutputMatchesSchema("labels", []interface{}{"map", "number"}) }, want: "output labels does not match schema: labels.env: got string, expected number"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, outputsFatal(t, tt.get))
+		})
+	}
+}
+
+func TestMatchesType(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		typ    interface{}
+		errMsg string
+	}{
+		{name: "string", value: `"foo"`, typ: "string"},
+		{name: "number", value: `1`, typ: "number"},
+		{name: "bool", value: `false`, typ: "bool"},
+		{name: "null", value: `null`, typ: "string"},
+		{name: "dynamic", value: `{"foo":[1]}`, typ: "dynamic"},
+		{name: "list", value: `["a","b"]`, typ: []interface{}{"list", "string"}},
+		{name: "set", value: `[1]`, typ: []interface{}{"set", "number"}},
+		{name: "tuple", value: `["a",1]`, typ: []interface{}{"tuple", []interface{}{"string", "number"}}},
+		{name: "map", value: `{"a":["b"]}`, typ: []interface{}{"map", []interface{}{"list", "string"}}},
+		{name: "object", value: `{"id":"a","extra":1}`, typ: []interface{}{"object", map[string]interface{}{"id": "string"}}},
+		{name: "wrong primitive", value: `"1"`, typ: "number", errMsg: "o: got string, expected number"},
+		{name: "wrong list element", value: `["a",1]`, typ: []interface{}{"list", "string"}, errMsg: "o.1: got number, expected string"},
+		{name: "wrong tuple length", value: `["a"]`, typ: []interface{}{"tuple", []interface{}{"string", "number"}}, errMsg: "o: got list, expected tuple of 2 elements"},
+		{name: "missing attribute", value: `{"id":"a"}`, typ: []interface{}{"object", map[string]interface{}{"id": "string", "name": "string"}}, errMsg: "o: missing attribute name"},
+		{name: "not a map", value: `[]`, typ: []interface{}{"map", "string"}, errMsg: "o: got list, expected map"},
+		{name: "unsupported", value: `"a"`, typ: "text", errMsg: "o: unsupported type text"},
+		{name: "invalid", value: `"a"`, typ: []interface{}{"list"}, errMsg: "o: invalid type [list]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := matchesType("o", gjson.Parse(tt.value), tt.typ)
+			if tt.errMsg != "" {
+				assert.EqualError(t, err, tt.errMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckOutputTypes(t *testing.T) {
+	tests := []struct {
+		name       string
+		metadata   string
+		wantErrors []string
+	}{
+		{name: "default metadata"},
+		{
+			name:     "mismatched metadata",
+			metadata: filepath.Join(outputsTestDir, "invalid_metadata.yaml"),
+			wantErrors: []string{
+				"network: missing attribute self_link",
+				"node_count: got number, expected string",
+				"output router declared in testdata/outputs/invalid_metadata.yaml not found",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, _ := newOutputsTest(t, t)
+			b.outputTypesMetadata = tt.metadata
+			a := &errorT{}
+			b.checkOutputTypes(assert.New(a))
+			assert.Len(t, a.errors, len(tt.wantErrors))
+			for i, want := range tt.wantErrors {
+				if i < len(a.errors) {
+					assert.Contains(t, a.errors[i], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,311 @@
+/**
+ * Copyright 2026 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tft
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/discovery"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/mitchellh/go-testing-interface"
+	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
+)
+
+// LoadPlanFile parses the Terraform JSON plan in path, as output by terraform show -json.
+// It fails the test if the plan can not be read or parsed.
+func LoadPlanFile(t testing.TB, path string) *terraform.PlanStruct {
+	planJSON, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unable to read plan %s: %v", path, err)
+	}
+	ps, err := terraform.ParsePlanJSON(string(planJSON))
+	if err != nil {
+		t.Fatalf("unable to parse plan %s: %v", path, err)
+	}
+	return ps
+}
+
+// PlanResourcesByType returns the sorted addresses of resources of resourceType planned to exist after apply.
+func PlanResourcesByType(ps *terraform.PlanStruct, resourceType string) []string {
+	addresses := []string{}
+	for address, r := range ps.ResourcePlannedValuesMap {
+		if r.Type == resourceType {
+			addresses = append(addresses, address)
+		}
+	}
+	sort.Strings(addresses)
+	return addresses
+}
+
+// PlanAttribute returns the planned value for the attribute path of the resource at address.
+// Nested attributes use gjson path syntax, for example network_interface.0.network.
+// Values only known after apply and unknown resources are returned as a non existent result.
+func PlanAttribute(ps *terraform.PlanStruct, address, path string) gjson.Result {
+	r, exists := ps.ResourcePlannedValuesMap[address]
+	if !exists {
+		return gjson.Result{}
+	}
+	values, err := json.Marshal(r.AttributeValues)
+	if err != nil {
+		return gjson.Result{}
+	}
+	return gjson.GetBytes(values, path)
+}
+
+// PlanDestroys returns the sorted addresses of resources planned to be deleted or replaced.
+func PlanDestroys(ps *terraform.PlanStruct) []string {
+	addresses := []string{}
+	for address, rc := range ps.ResourceChangesMap {
+		if rc.Change != nil && (rc.Change.Actions.Delete() || rc.Change.Actions.Replace()) {
+			addresses = append(addresses, address)
+		}
+	}
+	sort.Strings(addresses)
+	return addresses
+}
+
+// AssertPlanResourceCount asserts that count resources of resourceType are planned to exist after apply.
+func AssertPlanResourceCount(a *assert.Assertions, ps *terraform.PlanStruct, resourceType string, count int) bool {
+	addresses := PlanResourcesByType(ps, resourceType)
+	return a.Lenf(addresses, count, "expected %d %s resources planned, got %v", count, resourceType, addresses)
+}
+
+// AssertPlanAttribute asserts that the planned value for the attribute path of the resource at address equals want.
+// want is compared with the value as decoded from JSON, so numbers compare equal regardless of their Go type.
+func AssertPlanAttribute(a *assert.Assertions, ps *terraform.PlanStruct, address, path string, want interface{}) bool {
+	if !a.Containsf(ps.ResourcePlannedValuesMap, address, "resource %s not planned", address) {
+		return false
+	}
+	got := PlanAttribute(ps, address, path)
+	if !a.Truef(got.Exists(), "attribute %s of %s not planned or only known after apply", path, address) {
+		return false
+	}
+	wantJSON, err := json.Marshal(want)
+	if !a.NoError(err) {
+		return false
+	}
+	return a.Equalf(gjson.ParseBytes(wantJSON).Value(), got.Value(), "attribute %s of %s", path, address)
+}
+
+// AssertPlanNoDestroys asserts that no resources are planned to be deleted or replaced.
+func AssertPlanNoDestroys(a *assert.Assertions, ps *terraform.PlanStruct) bool {
+	destroys := PlanDestroys(ps)
+	return a.Emptyf(destroys, "expected no resources to be deleted or replaced, got %v", destroys)
+}
+
+// knownAfterApply is the value reported for attributes only known after apply.
+const knownAfterApply = "(known after apply)"
+
+// PlanDiff is a planned change of a resource.
+type PlanDiff struct {
+	Address string
+	Actions tfjson.Actions
+	// Attributes are the changed attributes of updated or replaced resources.
+	Attributes []AttributeDiff
+}
+
+// AttributeDiff is a planned change of a resource attribute.
+type AttributeDiff struct {
+	// Path is the attribute path such as labels.env or network_interface.0.network.
+	Path   string
+	Before interface{}
+	After  interface{}
+}
+
+// String returns the address, actions and changed attributes of the diff.
+func (d PlanDiff) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%s)", d.Address, d.action())
+	for _, a := range d.Attributes {
+		fmt.Fprintf(&b, "\n  %s: %s -> %s", a.Path, diffValue(a.Before), diffValue(a.After))
+	}
+	return b.String()
+}
+
+// action returns a description of the planned actions.
+func (d PlanDiff) action() string {
+	switch {
+	case d.Actions.Replace():
+		return "replace"
+	case d.Actions.Create():
+		return "create"
+	case d.Actions.Delete():
+		return "delete"
+	case d.Actions.Update():
+		return "update"
+	}
+	actions := make([]string, 0, len(d.Actions))
+	for _, a := range d.Actions {
+		actions = append(actions, string(a))
+	}
+	return strings.Join(actions, ", ")
+}
+
+// diffValue formats an attribute value of a diff.
+func diffValue(v interface{}) string {
+	if s, ok := v.(string); ok && s == knownAfterApply {
+		return s
+	}
+	j, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(j)
+}
+
+// PlanDiffs returns the planned changes sorted by address excluding no-op and read actions.
+// Changes matching allowed are excluded. Resources are excluded if their address is allowed
+// without attributes or all their changed attributes are allowed.
+func PlanDiffs(ps *terraform.PlanStruct, allowed ...discovery.AllowedDiff) []PlanDiff {
+	diffs := []PlanDiff{}
+	for address, rc := range ps.ResourceChangesMap {
+		if rc.Change == nil || rc.Change.Actions.NoOp() || rc.Change.Actions.Read() {
+			continue
+		}
+		d := PlanDiff{Address: address, Actions: rc.Change.Actions}
+		if rc.Change.Actions.Update() || rc.Change.Actions.Replace() {
+			d.Attributes = attributeDiffs(rc.Change)
+		}
+		if isAllowedDiff(d, allowed) {
+			continue
+		}
+		diffs = append(diffs, filterAllowedAttributes(d, allowed))
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Address < diffs[j].Address })
+	return diffs
+}
+
+// attributeDiffs returns the changed leaf attributes of change sorted by path.
+func attributeDiffs(change *tfjson.Change) []AttributeDiff {
+	before, after := map[string]interface{}{}, map[string]interface{}{}
+	flattenAttributes("", change.Before, before)
+	flattenAttributes("", change.After, after)
+	unknown := map[string]interface{}{}
+	flattenAttributes("", change.AfterUnknown, unknown)
+	for p, v := range unknown {
+		if v == true {
+			after[p] = knownAfterApply
+		}
+	}
+	paths := map[string]bool{}
+	for p := range before {
+		paths[p] = true
+	}
+	for p := range after {
+		paths[p] = true
+	}
+	diffs := []AttributeDiff{}
+	for p := range paths {
+		if !reflect.DeepEqual(before[p], after[p]) {
+			diffs = append(diffs, AttributeDiff{Path: p, Before: before[p], After: after[p]})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	return diffs
+}
+
+// flattenAttributes adds the leaf values of v to leaves keyed by their path under prefix.
+// Empty objects and lists are leaves.
+func flattenAttributes(prefix string, v interface{}, leaves map[string]interface{}) {
+	join := func(k string) string {
+		if prefix == "" {
+			return k
+		}
+		return prefix + "." + k
+	}
+	switch val := v.(type) {
+	case map[string]interface{}:
+		if len(val) == 0 && prefix != "" {
+			leaves[prefix] = val
+		}
+		for k, child := range val {
+			flattenAttributes(join(k), child, leaves)
+		}
+	case []interface{}:
+		if len(val) == 0 && prefix != "" {
+			leaves[prefix] = val
+		}
+		for i, child := range val {
+			flattenAttributes(join(fmt.Sprint(i)), child, leaves)
+		}
+	default:
+		if prefix != "" && v != nil {
+			leaves[prefix] = v
+		}
+	}
+}
+
+// isAllowedDiff returns true if all changes of d are allowed.
+func isAllowedDiff(d PlanDiff, allowed []discovery.AllowedDiff) bool {
+	for _, a := range allowed {
+		if len(a.Attributes) == 0 && matchesAddress(a.Address, d.Address) {
+			return true
+		}
+	}
+	return len(d.Attributes) > 0 && len(filterAllowedAttributes(d, allowed).Attributes) == 0
+}
+
+// filterAllowedAttributes returns d without allowed attribute changes.
+func filterAllowedAttributes(d PlanDiff, allowed []discovery.AllowedDiff) PlanDiff {
+	if d.Attributes == nil {
+		return d
+	}
+	attrs := []AttributeDiff{}
+	for _, attr := range d.Attributes {
+		if !isAllowedAttribute(d.Address, attr.Path, allowed) {
+			attrs = append(attrs, attr)
+		}
+	}
+	d.Attributes = attrs
+	return d
+}
+
+// isAllowedAttribute returns true if the attribute path of the resource at address is allowed.
+func isAllowedAttribute(address, path string, allowed []discovery.AllowedDiff) bool {
+	for _, a := range allowed {
+		if !matchesAddress(a.Address, address) {
+			continue
+		}
+		for _, attr := range a.Attributes {
+			if path == attr || strings.HasPrefix(path, attr+".") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchesAddress returns true if address matches pattern where * matches any characters.
+// An empty pattern matches any address.
+func matchesAddress(pattern, address string) bool {
+	if pattern == "" {
+		return true
+	}
+	parts := strings.Split(pattern, "*")
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$").MatchString(address)
+}
@@ -0,0 +1,193 @@
+/**
+ * Copyright 2026 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tft
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/discovery"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	planTestFile          = filepath.Join("testdata", "plan", "plan.json")
+	perpetualDiffTestFile = filepath.Join("testdata", "plan", "perpetual_diff.json")
+)
+
+func TestPlanHelpers(t *testing.T) {
+	ps := LoadPlanFile(t, planTestFile)
+
+	assert := assert.New(t)
+	assert.Equal([]string{"module.vm.google_compute_instance.vm[0]", "module.vm.google_compute_instance.vm[1]"}, PlanResourcesByType(ps, "google_compute_instance"))
+	assert.Empty(PlanResourcesByType(ps, "google_pubsub_topic"))
+	assert.Equal("default", PlanAttribute(ps, "module.vm.google_compute_instance.vm[0]", "network_interface.0.network").String())
+	assert.True(PlanAttribute(ps, "google_storage_bucket.logs", "versioning.0.enabled").Bool())
+	assert.False(PlanAttribute(ps, "google_storage_bucket.logs", "self_link").Exists())
+	assert.False(PlanAttribute(ps, "google_storage_bucket.missing", "name").Exists())
+	assert.Equal([]string{"google_pubsub_topic.old", "module.vm.google_compute_instance.vm[0]"}, PlanDestroys(ps))
+
+	assert.True(AssertPlanResourceCount(assert, ps, "google_storage_bucket", 1))
+	assert.True(AssertPlanAttribute(assert, ps, "google_storage_bucket.logs", "location", "US"))
+	assert.True(AssertPlanAttribute(assert, ps, "google_storage_bucket.logs", "versioning", []map[string]interface{}{{"enabled": true}}))
+}
+
+func TestPlanAssertionFailures(t *testing.T) {
+	ps := LoadPlanFile(t, planTestFile)
+	tests := []struct {
+		name   string
+		assert func(a *assert.Assertions) bool
+	}{
+		{name: "count", assert: func(a *assert.Assertions) bool { return AssertPlanResourceCount(a, ps, "google_compute_instance", 3) }},
+		{name: "attribute value", assert: func(a *assert.Assertions) bool {
+			return AssertPlanAttribute(a, ps, "google_storage_bucket.logs", "location", "EU")
+		}},
+		{name: "missing attribute", assert: func(a *assert.Assertions) bool {
+			return AssertPlanAttribute(a, ps, "google_storage_bucket.logs", "self_link", "")
+		}},
+		{name: "missing resource", assert: func(a *assert.Assertions) bool {
+			return AssertPlanAttribute(a, ps, "google_storage_bucket.missing", "name", "")
+		}},
+		{name: "destroys", assert: func(a *assert.Assertions) bool { return AssertPlanNoDestroys(a, ps) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &errorT{}
+			assert.False(t, tt.assert(assert.New(a)))
+			assert.NotEmpty(t, a.errors)
+		})
+	}
+}
+
+// errorT records assertion errors without failing the test.
+type errorT struct {
+	errors []string
+}
+
+func (e *errorT) Errorf(format string, args ...interface{}) {
+	e.errors = append(e.errors, fmt.Sprintf(format, args...))
+}
+
+func TestPlanOnly(t *testing.T) {
+	var planned bool
+	bpt := NewTFBlueprintTest(t, WithTFDir(filepath.Join("testdata", "plan")), WithPlanFile(planTestFile))
+	bpt.DefinePlan(func(ps *terraform.PlanStruct, assert *assert.Assertions) {
+		planned = true
+		AssertPlanResourceCount(assert, ps, "google_compute_instance", 2)
+		AssertPlanAttribute(assert, ps, "module.vm.google_compute_instance.vm[1]", "zone", "us-central1-b")
+	})
+	bpt.DefineApply(func(assert *assert.Assertions) {
+		t.Error("apply should not run in plan only mode")
+	})
+	bpt.Test()
+	assert.True(t, planned)
+}
+
+func TestPlanDiffs(t *testing.T) {
+	ps := LoadPlanFile(t, perpetualDiffTestFile)
+	tests := []struct {
+		name    string
+		allowed []discovery.AllowedDiff
+		want    []string
+	}{
+		{
+			name: "all",
+			want: []string{
+				"google_pubsub_topic.topic (replace)\n  kms_key_name: \"\" -> null",
+				"google_service_account.sa (create)",
+				"module.vm.google_compute_instance.vm[0] (update)\n" +
+					"  labels.env: \"dev\" -> \"prod\"\n" +
+					"  metadata.ssh-keys: \"a\" -> \"b\"\n" +
+					"  network_interface.0.access_config: [] -> null\n" +
+					"  network_interface.0.access_config.0.nat_ip: null -> (known after apply)",
+			},
+		},
+		{
+			name: "allowed attributes",
+			allowed: []discovery.AllowedDiff{
+				{Address: "module.vm.google_compute_instance.vm[*]", Attributes: []string{"metadata.ssh-keys", "network_interface"}},
+				{Attributes: []string{"kms_key_name"}},
+			},
+			want: []string{
+				"google_service_account.sa (create)",
+				"module.vm.google_compute_instance.vm[0] (update)\n  labels.env: \"dev\" -> \"prod\"",
+			},
+		},
+		{
+			name: "allowed addresses",
+			allowed: []discovery.AllowedDiff{
+				{Address: "module.vm.*"},
+				{Address: "google_service_account.sa"},
+				{Address: "google_pubsub_topic.other", Attributes: []string{"kms_key_name"}},
+			},
+			want: []string{"google_pubsub_topic.topic (replace)\n  kms_key_name: \"\" -> null"},
+		},
+		{
+			name:    "allowed all",
+			allowed: []discovery.AllowedDiff{{Address: "*"}},
+			want:    []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, d := range PlanDiffs(ps, tt.allowed...) {
+				got = append(got, d.String())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestVerifyIdempotency(t *testing.T) {
+	tests := []struct {
+		name          string
+		defaultVerify bool
+		allowed       []discovery.AllowedDiff
+		wantErrors    int
+	}{
+		{name: "default verify", defaultVerify: true, wantErrors: 1},
+		{name: "custom verify", wantErrors: 1},
+		{name: "allowed", allowed: []discovery.AllowedDiff{{Address: "*"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bpt := NewTFBlueprintTest(t, WithTFDir(filepath.Join("testdata", "plan")), WithPlanFile(planTestFile))
+			bpt.allowedDiffs = tt.allowed
+			plans := 0
+			bpt.idempotencyPlan = func() *terraform.PlanStruct {
+				plans++
+				return LoadPlanFile(t, perpetualDiffTestFile)
+			}
+			bpt.DefineVerify(func(assert *assert.Assertions) {
+				if tt.defaultVerify {
+					bpt.DefaultVerify(assert)
+				}
+			})
+			a := &errorT{}
+			bpt.Verify(assert.New(a))
+			assert.Equal(t, 1, plans)
+			assert.Len(t, a.errors, tt.wantErrors)
+			if tt.wantErrors > 0 {
+				assert.Contains(t, a.errors[0], "plan after apply should have no diff")
+				assert.Contains(t, a.errors[0], "module.vm.google_compute_instance.vm[0] (update)")
+			}
+		})
+	}
+}
@@ -18,8 +18,11 @@
 package tft
 
 import (
+	"context"
 	b64 "encoding/base64"
+	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"path"
 	"path/filepath"
@@ -43,6 +46,8 @@ const (
 	setupKeyOutputName    = "sa_key"
 	tftCacheMutexFilename = "bpt-tft-cache.lock"
 	planFilename          = "plan.tfplan"
+	// This must be kept in sync with what github.com/GoogleCloudPlatform/cloud-foundation-toolkit/cli/bptest sets.
+	stateFileEnvVar = "BPTEST_STATE_FILE"
 )
 
 var (
@@ -82,6 +87,27 @@ type TFBlueprintTest struct {
 	setupOutputOverrides          map[string]interface{}                          // override outputs from the Setup phase
 	tftCacheMutex                 *filemutex.FileMutex                            // Mutex to protect Terraform plugin cache
 	parallelism                   int                                             // Set the parallelism setting for Terraform
+	stageTimeouts                 map[string]time.Duration                        // optional timeouts of stages overriding the test config
+	stageCtx                      context.Context                                 // context of the running stage, cancelled if the stage times out
+	planFile                      string                                          // optional Terraform JSON plan to run the plan stage offline
+	allowedDiffs                  []discovery.AllowedDiff                         // known perpetual diffs ignored by the idempotency check
+	idempotencyChecked            bool                                            // whether the idempotency check ran in the current verify stage
+	idempotencyPlan               func() *terraform.PlanStruct                    // plans after apply for the idempotency check
+	upgradeTest                   bool                                            // whether to test the upgrade from the previous release
+	previousRelease               string                                          // optional git ref of the previous release for the upgrade test
+	allowedReplacements           []string                                        // address patterns of resources the upgrade may delete or replace
+	upgradeApply                  func()                                          // applies the previous release in the upgrade stage
+	upgradePlan                   func() *terraform.PlanStruct                    // plans the upgrade to the working tree in the upgrade stage
+	outputs                       *gjson.Result                                   // outputs cached in the current stage
+	outputsJSON                   func() string                                   // returns all outputs as JSON
+	checkOutputs                  bool                                            // whether to assert outputs match declared output types in verify
+	outputTypesMetadata           string                                          // optional path of the metadata.yaml declaring output types
+	leakCheck                     bool                                            // whether to check for assets leaked after teardown
+	leakProject                   string                                          // optional project checked for leaked assets
+	assetFetcher                  AssetFetcher                                    // returns the assets of a project for the leak check
+	leakCheckTimeout              time.Duration                                   // how long to wait for leaked assets to be deleted from Cloud Asset Inventory
+	leakCheckInterval             time.Duration                                   // initial interval between listing assets in the leak check
+	guardrails                    discovery.Guardrails                            // limits of the resources planned checked before apply
 }
 
 type tftOption func(*TFBlueprintTest)
@@ -155,6 +181,115 @@ func WithVars(vars map[string]interface{}) tftOption {
 	}
 }
 
+// WithStageTimeout cancels stage if it does not complete within timeout, overriding
+// stageTimeouts of the test config. Teardown is still run if a stage times out.
+func WithStageTimeout(stage string, timeout time.Duration) tftOption {
+	return func(f *TFBlueprintTest) {
+		if f.stageTimeouts == nil {
+			f.stageTimeouts = make(map[string]time.Duration)
+		}
+		f.stageTimeouts[stage] = timeout
+	}
+}
+
+// WithPlanFile runs the test in plan only mode against the Terraform JSON plan in planFile,
+// as output by terraform show -json, instead of planning the TF configs.
+// Only the plan stage is run and no credentials, providers or Terraform binary are required.
+func WithPlanFile(planFile string) tftOption {
+	return func(f *TFBlueprintTest) {
+		f.planFile = planFile
+	}
+}
+
+// WithAllowedDiff ignores a known perpetual diff in the post apply idempotency check, in addition to
+// allowedDiffs of the test config. address may contain * to match any characters and matches any
+// resource if empty. If no attributes are given, all changes of matching resources are ignored.
+func WithAllowedDiff(address string, attributes ...string) tftOption {
+	return func(f *TFBlueprintTest) {
+		f.allowedDiffs = append(f.allowedDiffs, discovery.AllowedDiff{Address: address, Attributes: attributes})
+	}
+}
+
+// WithUpgradeTest runs an upgrade stage after init which applies the previous release of the module
+// and asserts that upgrading to the working tree does not delete or replace resources other than
+// allowedReplacements. allowedReplacements are resource addresses where * matches any characters.
+// Module calls of the TF configs must refer to the module by a local path, as set by module-swapper.
+// The upgrade stage is skipped if there is no previous release.
+func WithUpgradeTest(allowedReplacements ...string) tftOption {
+	return func(f *TFBlueprintTest) {
+		f.upgradeTest = true
+		f.allowedReplacements = append(f.allowedReplacements, allowedReplacements...)
+	}
+}
+
+// WithPreviousRelease sets the git ref of the previous release applied by the upgrade test.
+// Defaults to the most recent v* tag before the latest commit, or the highest v* tag not on the
+// latest commit if its parent is not available as in shallow clones.
+func WithPreviousRelease(ref string) tftOption {
+	return func(f *TFBlueprintTest) {
+		f.previousRelease = ref
+	}
+}
+
+// WithOutputTypes asserts in the verify stage that outputs match the BlueprintOutput types declared in
+// metadataPath. If metadataPath is empty, metadata.yaml of the TF dir is used.
+func WithOutputTypes(metadataPath string) tftOption {
+	return func(f *TFBlueprintTest) {
+		f.checkOutputs = true
+		f.outputTypesMetadata = metadataPath
+	}
+}
+
+// WithLeakCheck fails the test if assets created by the test in project still exist after teardown.
+// Assets are listed with Cloud Asset Inventory before init and after teardown. If project is empty,
+// the project_id setup output is used. The check only runs if all stages from init to teardown run.
+func WithLeakCheck(project string) tftOption {
+	return func(f *TFBlueprintTest) {
+		f.leakCheck = true
+		f.leakProject = project
+	}
+}
+
+// WithLeakCheckTimeout sets how long the leak check waits for assets deleted by teardown to be
+// removed from Cloud Asset Inventory, which is eventually consistent. Defaults to 5 minutes.
+// A zero timeout lists assets once.
+func WithLeakCheckTimeout(timeout time.Duration) tftOption {
+	return func(f *TFBlueprintTest) {
+		f.leakCheckTimeout = timeout
+	}
+}
+
+// WithAssetFetcher sets a custom function listing the assets of a project for the leak check.
+func WithAssetFetcher(fetcher AssetFetcher) tftOption {
+	return func(f *TFBlueprintTest) {
+		f.assetFetcher = fetcher
+	}
+}
+
+// WithMaxResources fails the guardrail stage if more than max resources of resourceType are planned.
+func WithMaxResources(resourceType string, max int) tftOption {
+	return func(f *TFBlueprintTest) {
+		if f.guardrails.MaxResources == nil {
+			f.guardrails.MaxResources = make(map[string]int)
+		}
+		f.guardrails.MaxResources[resourceType] = max
+	}
+}
+
+// WithoutGPUs fails the guardrail stage if resources with GPUs are planned.
+func WithoutGPUs() tftOption {
+	return func(f *TFBlueprintTest) {
+		f.guardrails.DenyGPUs = true
+	}
+}
+
+// WithMachineTypes fails the guardrail stage if resources with other machine types are planned.
+func WithMachineTypes(machineTypes ...string) tftOption {
+	return func(f *TFBlueprintTest) {
+		f.guardrails.MachineTypes = append(f.guardrails.MachineTypes, machineTypes...)
+	}
+}
+
 func WithLogger(logger *logger.Logger) tftOption {
 	return func(f *TFBlueprintTest) {
 		f.logger = logger
@@ -195,6 +330,16 @@ func NewTFBlueprintTest(t testing.TB, opts ...tftOption) *TFBlueprintTest {
 	}
 	// default TF blueprint methods
 	tft.init = tft.DefaultInit
+	tft.idempotencyPlan = func() *terraform.PlanStruct {
+		_, ps := tft.planAndShow()
+		return ps
+	}
+	tft.upgradeApply = tft.defaultUpgradeApply
+	tft.upgradePlan = tft.defaultUpgradePlan
+	tft.outputsJSON = tft.defaultOutputsJSON
+	tft.assetFetcher = tft.defaultAssetFetcher
+	tft.leakCheckTimeout = defaultLeakCheckTimeout
+	tft.leakCheckInterval = defaultLeakCheckInterval
 	// No default plan function, plan is skipped if no custom func provided.
 	tft.apply = tft.DefaultApply
 	tft.verify = tft.DefaultVerify
@@ -219,10 +364,14 @@ func NewTFBlueprintTest(t testing.TB, opts ...tftOption) *TFBlueprintTest {
 		}
 	} else {
 		tfdir, err := discovery.GetConfigDirFromTestDir(utils.GetWD(t))
-		if err != nil {
+		switch {
+		case err != nil && tft.planFile != "":
+			t.Logf("TFDir not found, running plan only test without test config: %v", err)
+		case err != nil:
 			t.Fatalf("unable to detect TFDir :%v", err)
+		default:
+			tft.tfDir = tfdir
 		}
-		tft.tfDir = tfdir
 	}
 
 	// discover test config
@@ -230,6 +379,12 @@ func NewTFBlueprintTest(t testing.TB, opts ...tftOption) *TFBlueprintTest {
 	if err != nil {
 		t.Fatal(err)
 	}
+	tft.applyTestConfig()
+	// plan only tests do not load setup or use Terraform
+	if tft.planFile != "" {
+		tft.logger.Logf(tft.t, "Running plan only test with plan %s", tft.planFile)
+		return tft
+	}
 	// setupDir is empty, try known setupDir paths
 	if tft.setupDir == "" {
 		setupDir, err := discovery.GetKnownDirInParents(discovery.SetupDir, 2)
@@ -244,9 +399,13 @@ func NewTFBlueprintTest(t testing.TB, opts ...tftOption) *TFBlueprintTest {
 		gcloud.ActivateCredsAndEnvVars(tft.t, tft.saKey)
 	}
 	// load TFEnvVars from setup outputs
+	var setupOutputs map[string]interface{}
+	var sensitiveSetupOutputs map[string]bool
 	if tft.setupDir != "" {
 		tft.logger.Logf(tft.t, "Loading env vars from setup %s", tft.setupDir)
-		outputs := tft.getOutputs(tft.sensitiveOutputs(tft.setupDir))
+		sensitiveSetupOutputs = tft.sensitiveOutputs(tft.setupDir)
+		outputs := tft.getOutputs(sensitiveSetupOutputs)
+		setupOutputs = outputs
 		loadTFEnvVar(tft.tfEnvVars, tft.getTFOutputsAsInputs(outputs))
 		if credsEnc, exists := tft.tfEnvVars[fmt.Sprintf("TF_VAR_%s", setupKeyOutputName)]; tft.saKey == "" && exists {
 			if credDec, err := b64.StdEncoding.DecodeString(credsEnc); err == nil {
@@ -260,18 +419,109 @@ func NewTFBlueprintTest(t testing.TB, opts ...tftOption) *TFBlueprintTest {
 	}
 	// Load env vars to supplement/override setup
 	tft.logger.Logf(tft.t, "Loading setup from environment")
-	if tft.setupOutputOverrides == nil {
-		tft.setupOutputOverrides = make(map[string]interface{})
-	}
 	for k, v := range extractFromEnv("CFT_SETUP_") {
 		tft.setupOutputOverrides[k] = v
 	}
 
 	tftVersion := gjson.Get(terraform.RunTerraformCommand(tft.t, tft.GetTFOptions(), "version", "-json"), "terraform_version")
 	tft.logger.Logf(tft.t, "Running tests TF configs in %s with version %s", tft.tfDir, tftVersion)
+
+	// persist state for bptest to resume stages in a separate run
+	if stateFile := os.Getenv(stateFileEnvVar); stateFile != "" {
+		tft.writeState(stateFile, setupOutputs, sensitiveSetupOutputs)
+	}
 	return tft
 }
 
+// testState is the state of a test persisted for resuming stages.
+type testState struct {
+	TFDir        string            `json:"tfDir"`
+	Workspace    string            `json:"workspace"`
+	SetupOutputs map[string]string `json:"setupOutputs"`
+}
+
+// writeState writes the TF dir, workspace and non sensitive setup outputs of the test to path.
+// Setup outputs overridden via WithSetupOutputs or the environment take precedence.
+func (b *TFBlueprintTest) writeState(path string, setupOutputs map[string]interface{}, sensitive map[string]bool) {
+	tfDir, err := filepath.Abs(b.tfDir)
+	if err != nil {
+		b.t.Fatalf("unable to resolve TFDir %s: %v", b.tfDir, err)
+	}
+	// workspace show does not require an initialized working directory
+	ws, err := terraform.RunTerraformCommandE(b.t, &terraform.Options{TerraformDir: b.tfDir, Logger: logger.Discard, NoColor: true}, "workspace", "show")
+	if err != nil {
+		b.logger.Logf(b.t, "Unable to determine TF workspace, assuming default: %v", err)
+		ws = "default"
+	}
+	s := testState{TFDir: tfDir, Workspace: strings.TrimSpace(ws), SetupOutputs: map[string]string{}}
+	for k, v := range setupOutputs {
+		if sensitive[k] {
+			continue
+		}
+		s.SetupOutputs[k] = stateOutputValue(b.t, v)
+	}
+	for k, v := range b.setupOutputOverrides {
+		s.SetupOutputs[k] = stateOutputValue(b.t, v)
+	}
+	j, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		b.t.Fatalf("unable to marshal test state: %v", err)
+	}
+	if err := os.WriteFile(path, j, 0644); err != nil {
+		b.t.Fatalf("unable to write test state %s: %v", path, err)
+	}
+}
+
+// stateOutputValue formats an output value as accepted by the GetTFSetup* helpers.
+// Strings are returned as is, other values as JSON.
+func stateOutputValue(t testing.TB, v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	j, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("unable to marshal output value %v: %v", v, err)
+	}
+	return string(j)
+}
+
+// applyTestConfig merges vars, env vars, setup vars and retryable errors from the test config.
+// Values set via options take precedence over the test config.
+func (b *TFBlueprintTest) applyTestConfig() {
+	spec := b.BlueprintTestConfig.Spec
+	if len(spec.Vars) > 0 {
+		vars := make(map[string]interface{})
+		maps.Copy(vars, spec.Vars)
+		maps.Copy(vars, b.vars)
+		b.vars = vars
+	}
+	envVars := make(map[string]string)
+	loadTFEnvVar(envVars, spec.EnvVars)
+	maps.Copy(envVars, b.tfEnvVars)
+	b.tfEnvVars = envVars
+	setupOutputs := make(map[string]interface{})
+	for k, v := range spec.SetupVars {
+		setupOutputs[k] = v
+	}
+	maps.Copy(setupOutputs, b.setupOutputOverrides)
+	b.setupOutputOverrides = setupOutputs
+	b.allowedDiffs = append(b.allowedDiffs, spec.AllowedDiffs...)
+	if len(spec.Guardrails.MaxResources) > 0 {
+		maxResources := make(map[string]int)
+		maps.Copy(maxResources, spec.Guardrails.MaxResources)
+		maps.Copy(maxResources, b.guardrails.MaxResources)
+		b.guardrails.MaxResources = maxResources
+	}
+	b.guardrails.DenyGPUs = b.guardrails.DenyGPUs || spec.Guardrails.DenyGPUs
+	b.guardrails.MachineTypes = append(b.guardrails.MachineTypes, spec.Guardrails.MachineTypes...)
+	if len(spec.RetryableErrors) > 0 {
+		retryableErrors := make(map[string]string)
+		maps.Copy(retryableErrors, spec.RetryableErrors)
+		maps.Copy(retryableErrors, b.retryableTerraformErrors)
+		b.retryableTerraformErrors = retryableErrors
+	}
+}
+
 // sensitiveOutputs returns a map of sensitive output keys for module in dir.
 func (b *TFBlueprintTest) sensitiveOutputs(dir string) map[string]bool {
 	mod, err := tfconfig.LoadModule(dir)
@@ -306,9 +556,20 @@ func (b *TFBlueprintTest) getOutputs(sensitive map[string]bool) map[string]inter
 
 // GetTFOptions generates terraform.Options used by Terratest.
 func (b *TFBlueprintTest) GetTFOptions() *terraform.Options {
+	// identify commands of the running stage so that they can be cancelled on timeout
+	envVars := b.tfEnvVars
+	if b.stageCtx != nil {
+		if stageEnv := utils.StageEnv(b.stageCtx); stageEnv != nil {
+			envVars = maps.Clone(b.tfEnvVars)
+			if envVars == nil {
+				envVars = map[string]string{}
+			}
+			maps.Copy(envVars, stageEnv)
+		}
+	}
 	newOptions := terraform.WithDefaultRetryableErrors(b.t, &terraform.Options{
 		TerraformDir:             b.tfDir,
-		EnvVars:                  b.tfEnvVars,
+		EnvVars:                  envVars,
 		Vars:                     b.vars,
 		Logger:                   b.logger,
 		BackendConfig:            b.backendConfig,
@@ -358,39 +619,49 @@ func getKVFromOutputString(v string) (string, string, error) {
 }
 
 // GetStringOutput returns TF output for a given key as string.
-// It fails test if given key does not output a primitive.
+// Outputs are read once per stage. It fails test if given key does not exist or does not output a primitive.
 func (b *TFBlueprintTest) GetStringOutput(name string) string {
-	// allow only parallel reads as Terraform plugin cache isn't concurrent safe
-	rUnlockFn := b.rLockFn()
-	defer rUnlockFn()
-	return terraform.Output(b.t, b.GetTFOptions(), name)
+	return b.primitiveOutputString(name, b.getOutput(name))
 }
 
 // GetStringOutputList returns TF output for a given key as list.
-// It fails test if given key does not output a primitive.
+// It fails test if given key does not exist or does not output a list of primitives.
 //
 // Deprecated: Use GetJsonOutput instead.
 func (b *TFBlueprintTest) GetStringOutputList(name string) []string {
-	// allow only parallel reads as Terraform plugin cache isn't concurrent safe
-	rUnlockFn := b.rLockFn()
-	defer rUnlockFn()
-	return terraform.OutputList(b.t, b.GetTFOptions(), name)
+	v := b.getOutput(name)
+	if !v.IsArray() {
+		b.t.Fatalf("output %s is %s, expected list", name, jsonKind(v))
+	}
+	list := []string{}
+	for i, elem := range v.Array() {
+		list = append(list, b.primitiveOutputString(fmt.Sprintf("%s[%d]", name, i), elem))
+	}
+	return list
+}
+
+// primitiveOutputString returns the primitive output value v of name formatted like terraform output -raw.
+// Numbers are returned as written by Terraform, such as 123456789012 for project numbers.
+// It fails test if v is null, a list or an object.
+func (b *TFBlueprintTest) primitiveOutputString(name string, v gjson.Result) string {
+	switch v.Type {
+	case gjson.String:
+		return v.String()
+	case gjson.Number, gjson.True, gjson.False:
+		return v.Raw
+	}
+	b.t.Fatalf("output %s is %s, expected a primitive", name, jsonKind(v))
+	return ""
 }
 
 // GetJsonOutput returns TF output for key as gjson.Result.
 // An empty string for key can be used to return all values.
-// It fails test on invalid JSON.
+// Outputs are read once per stage. It fails test on invalid JSON or if given key does not exist.
 func (b *TFBlueprintTest) GetJsonOutput(key string) gjson.Result {
-	// allow only parallel reads as Terraform plugin cache isn't concurrent safe
-	rUnlockFn := b.rLockFn()
-	defer rUnlockFn()
-
-	jsonString := terraform.OutputJson(b.t, b.GetTFOptions(), key)
-	if !gjson.Valid(jsonString) {
-		b.t.Fatalf("Invalid JSON: %s", jsonString)
+	if key == "" {
+		return b.outputsSnapshot()
 	}
-
-	return gjson.Parse(jsonString)
+	return b.getOutput(key)
 }
 
 // GetTFSetupOutputListVal returns TF output from setup for a given key as list.
@@ -477,6 +748,14 @@ func extractFromEnv(prefix string) map[string]interface{} {
 	return r
 }
 
+// stageTimeout returns the timeout for stage set via WithStageTimeout or the test config.
+func (b *TFBlueprintTest) stageTimeout(stage string) time.Duration {
+	if d, ok := b.stageTimeouts[stage]; ok {
+		return d
+	}
+	return b.BlueprintTestConfig.StageTimeout(stage)
+}
+
 // ShouldSkip checks if a test should be skipped
 func (b *TFBlueprintTest) ShouldSkip() bool {
 	return b.BlueprintTestConfig.Spec.Skip
@@ -530,11 +809,22 @@ func (b *TFBlueprintTest) DefaultTeardown(assert *assert.Assertions) {
 
 // DefaultVerify asserts no resource changes exist after apply.
 func (b *TFBlueprintTest) DefaultVerify(assert *assert.Assertions) {
-	e := terraform.PlanExitCode(b.t, b.GetTFOptions())
-	// exit code 0 is success with no diffs, 2 is success with non-empty diff
-	// https://www.terraform.io/docs/cli/commands/plan.html#detailed-exitcode
-	assert.NotEqual(1, e, "plan after apply should not fail")
-	assert.NotEqual(2, e, "plan after apply should have no diff")
+	b.checkIdempotency(assert)
+}
+
+// checkIdempotency asserts a plan after apply has no changes other than allowed diffs
+// and reports the changed resources and attributes otherwise.
+func (b *TFBlueprintTest) checkIdempotency(assert *assert.Assertions) {
+	b.idempotencyChecked = true
+	diffs := PlanDiffs(b.idempotencyPlan(), b.allowedDiffs...)
+	if len(diffs) == 0 {
+		return
+	}
+	changes := make([]string, 0, len(diffs))
+	for _, d := range diffs {
+		changes = append(changes, d.String())
+	}
+	assert.Failf("plan after apply should have no diff", "%d resources changed:\n%s", len(diffs), strings.Join(changes, "\n"))
 }
 
 // DefaultInit runs TF init and validate on a blueprint.
@@ -569,10 +859,13 @@ func (b *TFBlueprintTest) DefaultApply(assert *assert.Assertions) {
 		b.Vet(assert)
 	}
 	terraform.Apply(b.t, b.GetTFOptions())
+	// outputs read before apply are outdated
+	b.outputs = nil
 }
 
 // Init runs the default or custom init function for the blueprint.
 func (b *TFBlueprintTest) Init(assert *assert.Assertions) {
+	b.outputs = nil
 	// allow only single write as Terraform plugin cache isn't concurrent safe
 	if err := b.tftCacheMutex.Lock(); err != nil {
 		b.t.Fatalf("Could not acquire lock: %v", err)
@@ -587,6 +880,13 @@ func (b *TFBlueprintTest) Init(assert *assert.Assertions) {
 
 // PlanAndShow performs a Terraform plan, show and returns the parsed plan output.
 func (b *TFBlueprintTest) PlanAndShow() (string, *terraform.PlanStruct) {
+	rUnlockFn := b.rLockFn()
+	defer rUnlockFn()
+	return b.planAndShow()
+}
+
+// planAndShow performs a Terraform plan and show without locking the plugin cache.
+func (b *TFBlueprintTest) planAndShow() (string, *terraform.PlanStruct) {
 	tDir, err := os.MkdirTemp(os.TempDir(), "btp")
 	if err != nil {
 		b.t.Fatalf("Temp directory %q could not created: %v", tDir, err)
@@ -599,8 +899,6 @@ func (b *TFBlueprintTest) PlanAndShow() (string, *terraform.PlanStruct) {
 
 	planOpts := b.GetTFOptions()
 	planOpts.PlanFilePath = filepath.Join(tDir, planFilename)
-	rUnlockFn := b.rLockFn()
-	defer rUnlockFn()
 	terraform.Plan(b.t, planOpts)
 	// Logging show output is not useful since we log plan output above
 	// and show output is parsed and retured.
@@ -614,16 +912,26 @@ func (b *TFBlueprintTest) PlanAndShow() (string, *terraform.PlanStruct) {
 // Plan runs the custom plan function for the blueprint.
 // If not custom plan function is defined, this stage is skipped.
 func (b *TFBlueprintTest) Plan(assert *assert.Assertions) {
+	b.outputs = nil
 	if b.plan == nil {
 		b.logger.Logf(b.t, "skipping plan as no function defined")
 		return
 	}
+	b.plan(b.stagePlan(), assert)
+}
+
+// stagePlan returns the plan loaded from the plan file in plan only mode or plans the TF configs.
+func (b *TFBlueprintTest) stagePlan() *terraform.PlanStruct {
+	if b.planFile != "" {
+		return LoadPlanFile(b.t, b.planFile)
+	}
 	_, ps := b.PlanAndShow()
-	b.plan(ps, assert)
+	return ps
 }
 
 // Apply runs the default or custom apply function for the blueprint.
 func (b *TFBlueprintTest) Apply(assert *assert.Assertions) {
+	b.outputs = nil
 	// allow only parallel reads as Terraform plugin cache isn't concurrent safe
 	rUnlockFn := b.rLockFn()
 	defer rUnlockFn()
@@ -631,15 +939,26 @@ func (b *TFBlueprintTest) Apply(assert *assert.Assertions) {
 }
 
 // Verify runs the default or custom verify function for the blueprint.
+// The idempotency check of DefaultVerify is run if a custom verify function does not call it.
+// Outputs are checked against declared output types if enabled with WithOutputTypes.
 func (b *TFBlueprintTest) Verify(assert *assert.Assertions) {
 	// allow only parallel reads as Terraform plugin cache isn't concurrent safe
 	rUnlockFn := b.rLockFn()
 	defer rUnlockFn()
+	b.outputs = nil
+	b.idempotencyChecked = false
 	b.verify(assert)
+	if !b.idempotencyChecked {
+		b.checkIdempotency(assert)
+	}
+	if b.checkOutputs {
+		b.checkOutputTypes(assert)
+	}
 }
 
 // Teardown runs the default or custom teardown function for the blueprint.
 func (b *TFBlueprintTest) Teardown(assert *assert.Assertions) {
+	b.outputs = nil
 	// allow only parallel reads as Terraform plugin cache isn't concurrent safe
 	rUnlockFn := b.rLockFn()
 	defer rUnlockFn()
@@ -647,14 +966,19 @@ func (b *TFBlueprintTest) Teardown(assert *assert.Assertions) {
 }
 
 const (
-	initStage     = "init"
-	planStage     = "plan"
-	applyStage    = "apply"
-	verifyStage   = "verify"
-	teardownStage = "teardown"
+	initStage      = "init"
+	planStage      = "plan"
+	applyStage     = "apply"
+	verifyStage    = "verify"
+	teardownStage  = "teardown"
+	upgradeStage   = "upgrade"
+	guardrailStage = "guardrail"
 )
 
 // Test runs init, apply, verify, teardown in order for the blueprint.
+// The guardrail stage is run after init. In plan only mode only the plan and guardrail stages are run.
+// In upgrade test mode the upgrade stage is run after the guardrail stage.
+// If enabled, leaked assets are checked after teardown.
 func (b *TFBlueprintTest) Test() {
 	if b.ShouldSkip() {
 		b.logger.Logf(b.t, "Skipping test due to config %s", b.BlueprintTestConfig.Path)
@@ -662,12 +986,40 @@ func (b *TFBlueprintTest) Test() {
 		return
 	}
 	a := assert.New(b.t)
+	if b.planFile != "" {
+		b.runStage(planStage, func() { b.Plan(a) })
+		b.runStage(guardrailStage, func() { b.Guardrail(a) })
+		return
+	}
+	// snapshot assets before init to find assets leaked after teardown
+	if b.leakCheck && b.shouldCheckLeaks() {
+		project := b.leakCheckProject()
+		before := b.projectAssets(project)
+		defer b.checkLeaks(a, project, before)
+	}
 	// run stages
-	utils.RunStage(initStage, func() { b.Init(a) })
-	defer utils.RunStage(teardownStage, func() { b.Teardown(a) })
-	utils.RunStage(planStage, func() { b.Plan(a) })
-	utils.RunStage(applyStage, func() { b.Apply(a) })
-	utils.RunStage(verifyStage, func() { b.Verify(a) })
+	b.runStage(initStage, func() { b.Init(a) })
+	defer b.runStage(teardownStage, func() { b.Teardown(a) })
+	b.runStage(guardrailStage, func() { b.Guardrail(a) })
+	if b.upgradeTest {
+		b.runStage(upgradeStage, func() { b.Upgrade(a) })
+	}
+	b.runStage(planStage, func() { b.Plan(a) })
+	b.runStage(applyStage, func() { b.Apply(a) })
+	b.runStage(verifyStage, func() { b.Verify(a) })
+}
+
+// runStage runs stage with its timeout unless the stage is disabled by the test config.
+func (b *TFBlueprintTest) runStage(stage string, fn func()) {
+	if !b.BlueprintTestConfig.RunsStage(stage) {
+		b.logger.Logf(b.t, "Skipping stage %s due to config %s", stage, b.BlueprintTestConfig.Path)
+		return
+	}
+	utils.RunStageWithTimeout(b.t, stage, b.stageTimeout(stage), func(ctx context.Context) {
+		b.stageCtx = ctx
+		defer func() { b.stageCtx = nil }()
+		fn()
+	})
 }
 
 // RedeployTest deploys the test n times in separate workspaces before teardown.
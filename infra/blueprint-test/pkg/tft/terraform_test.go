@@ -17,10 +17,12 @@
 package tft
 
 import (
+	"encoding/json"
 	"os"
 	"path"
 	"testing"
 
+	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/discovery"
 	"github.com/gruntwork-io/terratest/modules/logger"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	testingiface "github.com/mitchellh/go-testing-interface"
@@ -272,3 +274,59 @@ func TestSetupOverrideFromEnv(t *testing.T) {
 	got := b.GetTFSetupStringOutput("my-key")
 	assert.Equal(t, got, "my-value")
 }
+
+func TestStateOutputValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "string", value: "foo", want: "foo"},
+		{name: "number", value: 1.5, want: "1.5"},
+		{name: "list", value: []interface{}{"foo", "bar"}, want: `["foo","bar"]`},
+		{name: "map", value: map[string]interface{}{"foo": "bar"}, want: `{"foo":"bar"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, stateOutputValue(&testingiface.RuntimeT{}, tt.value))
+		})
+	}
+}
+
+func TestWriteState(t *testing.T) {
+	stateFile := path.Join(t.TempDir(), "test.json")
+	t.Setenv(stateFileEnvVar, stateFile)
+	t.Setenv("CFT_SETUP_my-key", "my-value")
+	emptyDir := newTestDir(t, "empty*", "")
+	defer os.RemoveAll(emptyDir)
+	NewTFBlueprintTest(&testingiface.RuntimeT{},
+		WithTFDir(emptyDir))
+	b, err := os.ReadFile(stateFile)
+	assert.NoError(t, err)
+	var got testState
+	assert.NoError(t, json.Unmarshal(b, &got))
+	assert.Equal(t, testState{TFDir: emptyDir, Workspace: "default", SetupOutputs: map[string]string{"my-key": "my-value"}}, got)
+}
+
+func TestApplyTestConfig(t *testing.T) {
+	b := &TFBlueprintTest{
+		vars:                     map[string]interface{}{"name": "option"},
+		tfEnvVars:                map[string]string{"TF_VAR_region": "option"},
+		setupOutputOverrides:     map[string]interface{}{"project_id": "option"},
+		retryableTerraformErrors: map[string]string{".*quota.*": "option"},
+		allowedDiffs:             []discovery.AllowedDiff{{Address: "option"}},
+	}
+	b.BlueprintTestConfig.Spec.Vars = map[string]interface{}{"name": "config", "count": 2}
+	b.BlueprintTestConfig.Spec.EnvVars = map[string]string{"region": "config", "zone": "config"}
+	b.BlueprintTestConfig.Spec.SetupVars = map[string]string{"project_id": "config", "folder_id": "config"}
+	b.BlueprintTestConfig.Spec.RetryableErrors = map[string]string{".*quota.*": "config", ".*timeout.*": "config"}
+	b.BlueprintTestConfig.Spec.AllowedDiffs = []discovery.AllowedDiff{{Attributes: []string{"labels"}}}
+	b.applyTestConfig()
+
+	assert := assert.New(t)
+	assert.Equal(map[string]interface{}{"name": "option", "count": 2}, b.vars)
+	assert.Equal(map[string]string{"TF_VAR_region": "option", "TF_VAR_zone": "config"}, b.tfEnvVars)
+	assert.Equal(map[string]interface{}{"project_id": "option", "folder_id": "config"}, b.setupOutputOverrides)
+	assert.Equal(map[string]string{".*quota.*": "option", ".*timeout.*": "config"}, b.retryableTerraformErrors)
+	assert.Equal([]discovery.AllowedDiff{{Address: "option"}, {Attributes: []string{"labels"}}}, b.allowedDiffs)
+}
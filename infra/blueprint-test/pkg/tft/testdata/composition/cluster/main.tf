@@ -0,0 +1 @@
+# cluster component
@@ -0,0 +1 @@
+# network component
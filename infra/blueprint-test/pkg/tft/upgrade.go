@@ -0,0 +1,206 @@
+/**
+ * Copyright 2026 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tft
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/git"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+	"github.com/mitchellh/go-testing-interface"
+	"github.com/stretchr/testify/assert"
+)
+
+// releaseTagPattern matches the git tags of module releases.
+const releaseTagPattern = "v*"
+
+// Upgrade applies the previous release of the module, switches the module sources back to the
+// working tree and asserts that the upgrade plan does not delete or replace resources other than
+// the allowed replacements. The upgrade is skipped if the module has no previous release.
+func (b *TFBlueprintTest) Upgrade(assert *assert.Assertions) {
+	b.outputs = nil
+	g := git.NewCmdConfig(b.t, git.WithDir(b.tfDir), git.WithLogger(b.logger))
+	root := g.GetTopLevel()
+	ref := b.previousRelease
+	if ref == "" {
+		var err error
+		ref, err = g.GetPreviousTagE(releaseTagPattern)
+		if errors.Is(err, git.ErrNoPreviousTag) {
+			b.logger.Logf(b.t, "Skipping upgrade test as no previous release matching %s is tagged in %s", releaseTagPattern, root)
+			return
+		}
+		if err != nil {
+			b.t.Fatalf("error getting previous release matching %s: %v", releaseTagPattern, err)
+		}
+	}
+	tmpDir, err := os.MkdirTemp("", "bpt-upgrade-")
+	if err != nil {
+		b.t.Fatalf("unable to create upgrade temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	releaseDir := filepath.Join(tmpDir, "release")
+	g.AddWorktree(releaseDir, ref)
+	defer g.RemoveWorktree(releaseDir)
+
+	b.logger.Logf(b.t, "Applying previous release %s of %s", ref, root)
+	func() {
+		restore := swapModuleSources(b.t, b.tfDir, root, releaseDir)
+		defer restore()
+		b.upgradeApply()
+	}()
+
+	b.logger.Logf(b.t, "Planning upgrade from %s to working tree %s", ref, root)
+	destroys := []string{}
+	for _, address := range PlanDestroys(b.upgradePlan()) {
+		if !b.isAllowedReplacement(address) {
+			destroys = append(destroys, address)
+		}
+	}
+	assert.Emptyf(destroys, "upgrade from %s should not delete or replace resources, got %v", ref, destroys)
+}
+
+// isAllowedReplacement returns true if the resource at address may be deleted or replaced by the upgrade.
+func (b *TFBlueprintTest) isAllowedReplacement(address string) bool {
+	for _, pattern := range b.allowedReplacements {
+		if matchesAddress(pattern, address) {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultUpgradeApply installs the modules of the previous release and applies the TF configs.
+func (b *TFBlueprintTest) defaultUpgradeApply() {
+	b.initModules()
+	rUnlockFn := b.rLockFn()
+	defer rUnlockFn()
+	terraform.Apply(b.t, b.GetTFOptions())
+}
+
+// defaultUpgradePlan installs the modules of the working tree and plans the TF configs.
+func (b *TFBlueprintTest) defaultUpgradePlan() *terraform.PlanStruct {
+	b.initModules()
+	_, ps := b.PlanAndShow()
+	return ps
+}
+
+// initModules runs TF init to install changed module sources.
+func (b *TFBlueprintTest) initModules() {
+	// allow only single write as Terraform plugin cache isn't concurrent safe
+	if err := b.tftCacheMutex.Lock(); err != nil {
+		b.t.Fatalf("Could not acquire lock: %v", err)
+	}
+	defer func() {
+		if err := b.tftCacheMutex.Unlock(); err != nil {
+			b.t.Fatalf("Could not release lock: %v", err)
+		}
+	}()
+	terraform.Init(b.t, b.GetTFOptions())
+}
+
+// swapModuleSources rewrites the local sources of module calls in dir which refer to modules in the
+// from directory to the same modules in the to directory.
+// It returns a function restoring the original sources.
+//
+// Registry and other remote sources are not rewritten, module-swapper must be run first to refer to
+// the module by a local path. The test fails if no module call refers to a module in from.
+func swapModuleSources(t testing.TB, dir, from, to string) func() {
+	absDir := resolvePath(t, dir)
+	from, to = resolvePath(t, from), resolvePath(t, to)
+	mod, diags := tfconfig.LoadModule(absDir)
+	if diags.HasErrors() {
+		t.Fatalf("error loading module in %s: %v", dir, diags.Err())
+	}
+	originals := map[string][]byte{}
+	swapped := map[string][]string{}
+	for _, mc := range mod.ModuleCalls {
+		if !strings.HasPrefix(mc.Source, "./") && !strings.HasPrefix(mc.Source, "../") {
+			continue
+		}
+		rel, err := filepath.Rel(from, filepath.Join(absDir, mc.Source))
+		if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+			continue
+		}
+		source, err := filepath.Rel(absDir, filepath.Join(to, rel))
+		if err != nil {
+			t.Fatalf("unable to find relative path of module %s: %v", mc.Name, err)
+		}
+		if !strings.HasPrefix(source, "../") {
+			source = "./" + source
+		}
+		lines, exists := swapped[mc.Pos.Filename]
+		if !exists {
+			f, err := os.ReadFile(mc.Pos.Filename)
+			if err != nil {
+				t.Fatalf("error reading %s: %v", mc.Pos.Filename, err)
+			}
+			originals[mc.Pos.Filename] = f
+			lines = strings.Split(string(f), "\n")
+		}
+		if !replaceModuleSource(lines, mc.Pos.Line, mc.Source, source) {
+			t.Fatalf("unable to find source %s of module %s in %s", mc.Source, mc.Name, mc.Pos.Filename)
+		}
+		swapped[mc.Pos.Filename] = lines
+	}
+	if len(swapped) == 0 {
+		t.Fatalf("no module call in %s refers to a module in %s by a local path, run module-swapper to replace registry sources first", dir, from)
+	}
+	for filename, lines := range swapped {
+		if err := os.WriteFile(filename, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+			t.Fatalf("error writing %s: %v", filename, err)
+		}
+	}
+	return func() {
+		for filename, f := range originals {
+			if err := os.WriteFile(filename, f, 0644); err != nil {
+				t.Errorf("error restoring %s: %v", filename, err)
+			}
+		}
+	}
+}
+
+// replaceModuleSource replaces the source attribute of the module block at line, counting from one.
+// It returns false if the source attribute is not found.
+func replaceModuleSource(lines []string, line int, source, newSource string) bool {
+	sourceRe := regexp.MustCompile(fmt.Sprintf(`^(\s*source\s*=\s*)"%s"`, regexp.QuoteMeta(source)))
+	for i := line - 1; i >= 0 && i < len(lines); i++ {
+		if sourceRe.MatchString(lines[i]) {
+			lines[i] = sourceRe.ReplaceAllString(lines[i], fmt.Sprintf(`${1}"%s"`, newSource))
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePath returns the absolute path of p with symlinks evaluated.
+func resolvePath(t testing.TB, p string) string {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		t.Fatalf("unable to find absolute path of %s: %v", p, err)
+	}
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		t.Fatalf("unable to resolve %s: %v", abs, err)
+	}
+	return resolved
+}
@@ -0,0 +1,200 @@
+/**
+ * Copyright 2026 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tft
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/cloud-foundation-toolkit/infra/blueprint-test/pkg/git"
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+	"github.com/stretchr/testify/assert"
+)
+
+const upgradeExample = `module "root" {
+  source = "../.."
+}
+
+module "sub" {
+  source     = "../../modules/sub"
+  depends_on = [module.root]
+}
+
+module "remote" {
+  source  = "terraform-google-modules/network/google"
+  version = "~> 9.0"
+}
+`
+
+// newUpgradeRepo creates a module repo with an example, a v1.0.0 release and a later commit.
+func newUpgradeRepo(t *testing.T, tagHead bool) string {
+	t.Helper()
+	t.Setenv("GIT_COMMITTER_NAME", "BlueprintsTest")
+	t.Setenv("GIT_COMMITTER_EMAIL", "blueprints-ci-test@google.com")
+	root := t.TempDir()
+	writeFile := func(name, content string) {
+		t.Helper()
+		p := filepath.Join(root, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		assert.NoError(t, os.WriteFile(p, []byte(content), 0644))
+	}
+	writeFile("main.tf", `output "version" { value = "1.0.0" }`)
+	writeFile(filepath.Join("modules", "sub", "main.tf"), `output "version" { value = "1.0.0" }`)
+	writeFile(filepath.Join("examples", "simple", "main.tf"), upgradeExample)
+
+	g := git.NewCmdConfig(t, git.WithDir(root), git.WithLogger(logger.Discard))
+	g.Init()
+	g.AddAll()
+	g.Commit()
+	_, err := g.RunCmdE("tag", "v1.0.0")
+	assert.NoError(t, err)
+	writeFile("main.tf", `output "version" { value = "2.0.0" }`)
+	g.AddAll()
+	g.Commit()
+	if tagHead {
+		_, err := g.RunCmdE("tag", "v2.0.0")
+		assert.NoError(t, err)
+	}
+	return root
+}
+
+// shallowUpgradeRepo returns a depth 1 clone of the module repo with the v1.0.0 release fetched,
+// so that the parent of the latest commit is not available.
+func shallowUpgradeRepo(t *testing.T) string {
+	t.Helper()
+	root := newUpgradeRepo(t, true)
+	clone := t.TempDir()
+	g := git.NewCmdConfig(t, git.WithDir(clone), git.WithLogger(logger.Discard))
+	_, err := g.RunCmdE("clone", "--depth", "1", "--no-tags", "file://"+root, ".")
+	assert.NoError(t, err)
+	_, err = g.RunCmdE("fetch", "--depth", "1", "origin", "tag", "v1.0.0", "tag", "v2.0.0")
+	assert.NoError(t, err)
+	return clone
+}
+
+// unreleasedRepo returns a module repo with a single commit tagged as the first release.
+func unreleasedRepo(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "examples", "simple"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "examples", "simple", "main.tf"), []byte(upgradeExample), 0644))
+	g := git.NewCmdConfig(t, git.WithDir(root), git.WithLogger(logger.Discard))
+	g.Init()
+	g.AddAll()
+	g.Commit()
+	_, err := g.RunCmdE("tag", "v1.0.0")
+	assert.NoError(t, err)
+	return root
+}
+
+func TestUpgrade(t *testing.T) {
+	tests := []struct {
+		name            string
+		tagHead         bool
+		repo            func(t *testing.T) string
+		previousRelease string
+		allowed         []string
+		wantErrors      int
+		wantSkipped     bool
+	}{
+		{name: "destroys", wantErrors: 1},
+		{name: "allowed replacement", allowed: []string{"google_pubsub_topic.*"}},
+		{name: "head released", tagHead: true, wantErrors: 1},
+		{name: "explicit release", previousRelease: "v1.0.0", wantErrors: 1},
+		{name: "shallow clone", repo: shallowUpgradeRepo, wantErrors: 1},
+		{name: "no previous release", repo: unreleasedRepo, wantSkipped: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GIT_COMMITTER_NAME", "BlueprintsTest")
+			t.Setenv("GIT_COMMITTER_EMAIL", "blueprints-ci-test@google.com")
+			var root string
+			if tt.repo != nil {
+				root = tt.repo(t)
+			} else {
+				root = newUpgradeRepo(t, tt.tagHead)
+			}
+			exampleDir := filepath.Join(root, "examples", "simple")
+			opts := []tftOption{WithTFDir(exampleDir), WithPlanFile(planTestFile), WithUpgradeTest(tt.allowed...)}
+			if tt.previousRelease != "" {
+				opts = append(opts, WithPreviousRelease(tt.previousRelease))
+			}
+			bpt := NewTFBlueprintTest(t, opts...)
+
+			a := assert.New(t)
+			var applied, planned bool
+			bpt.upgradeApply = func() {
+				applied = true
+				mod, diags := tfconfig.LoadModule(exampleDir)
+				a.False(diags.HasErrors())
+				for _, name := range []string{"root", "sub"} {
+					source := mod.ModuleCalls[name].Source
+					a.NotContains([]string{"../..", "../../modules/sub"}, source)
+					got, err := os.ReadFile(filepath.Join(exampleDir, source, "main.tf"))
+					a.NoError(err)
+					a.Contains(string(got), "1.0.0", "module %s should be the previous release", name)
+				}
+				a.Equal("terraform-google-modules/network/google", mod.ModuleCalls["remote"].Source)
+			}
+			bpt.upgradePlan = func() *terraform.PlanStruct {
+				planned = true
+				got, err := os.ReadFile(filepath.Join(exampleDir, "main.tf"))
+				a.NoError(err)
+				a.Equal(upgradeExample, string(got), "module sources should be restored")
+				return LoadPlanFile(t, perpetualDiffTestFile)
+			}
+			errT := &errorT{}
+			bpt.Upgrade(assert.New(errT))
+			a.Equal(!tt.wantSkipped, applied)
+			a.Equal(!tt.wantSkipped, planned)
+			a.Len(errT.errors, tt.wantErrors)
+			if tt.wantErrors > 0 {
+				a.Contains(errT.errors[0], "upgrade from v1.0.0 should not delete or replace resources, got [google_pubsub_topic.topic]")
+			}
+			worktrees, err := git.NewCmdConfig(t, git.WithDir(root), git.WithLogger(logger.Discard)).RunCmdE("worktree", "list")
+			a.NoError(err)
+			a.NotContains(worktrees, "bpt-upgrade-", "release worktree should be removed")
+		})
+	}
+}
+
+func TestSwapModuleSourcesRegistryOnly(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`module "remote" {
+  source = "terraform-google-modules/network/google"
+}
+`), 0644))
+	ft := &fatalT{}
+	func() {
+		defer func() { recover() }()
+		swapModuleSources(ft, dir, t.TempDir(), t.TempDir())
+	}()
+	assert.Contains(t, ft.msg, "refers to a module in")
+	assert.Contains(t, ft.msg, "run module-swapper to replace registry sources first")
+}
+
+func TestReplaceModuleSource(t *testing.T) {
+	lines := []string{`module "a" {`, `  source = "../a"`, `}`, `module "b" {`, `  version = "1.0"`, `  source  = "../a" # same`, `}`}
+	assert := assert.New(t)
+	assert.True(replaceModuleSource(lines, 4, "../a", "../b"))
+	assert.Equal(`  source = "../a"`, lines[1])
+	assert.Equal(`  source  = "../b" # same`, lines[5])
+	assert.False(replaceModuleSource(lines, 1, "../c", "../b"))
+}
@@ -17,11 +17,125 @@
 package utils
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/mitchellh/go-testing-interface"
+)
+
+const (
+	RUN_STAGE_ENV_VAR = "RUN_STAGE"
+
+	// STAGE_ID_ENV_VAR is set for commands started by a stage with a timeout to identify the stage run,
+	// so that commands of the stage can be cancelled without affecting other tests in the process.
+	STAGE_ID_ENV_VAR = "CFT_STAGE_ID"
 )
 
-const RUN_STAGE_ENV_VAR = "RUN_STAGE"
+var (
+	// stageCancelGracePeriod is the time a stage is given to stop after its commands are interrupted on timeout.
+	stageCancelGracePeriod = 2 * time.Minute
+	// stageKillGracePeriod is the time a stage is given to stop after its commands are killed.
+	stageKillGracePeriod = 30 * time.Second
+)
+
+// signalStageCommands is used to cancel commands started by a stage.
+var signalStageCommands = defaultSignalStageCommands
+
+// stageIDKey is the context key of the stage ID.
+type stageIDKey struct{}
+
+// stageIDs generates stage IDs unique within the process.
+var stageIDs atomic.Uint64
+
+// StageEnv returns the environment variables identifying commands started by the stage running with ctx.
+// Commands started with these variables are interrupted if the stage times out.
+func StageEnv(ctx context.Context) map[string]string {
+	id, ok := ctx.Value(stageIDKey{}).(string)
+	if !ok {
+		return nil
+	}
+	return map[string]string{STAGE_ID_ENV_VAR: id}
+}
+
+// defaultSignalStageCommands sends sig to the processes started with the environment of stage id.
+// SIGINT allows commands like Terraform to exit gracefully and release state locks.
+func defaultSignalStageCommands(id string, sig os.Signal) error {
+	pids, err := stageProcesses(id)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, pid := range pids {
+		p, err := os.FindProcess(pid)
+		if err == nil {
+			err = p.Signal(sig)
+		}
+		if err != nil && !errors.Is(err, os.ErrProcessDone) {
+			errs = append(errs, fmt.Errorf("signaling process %d: %w", pid, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// stageProcesses returns the processes started with the environment of stage id.
+// Processes are found in /proc or, on platforms without /proc such as macOS, with ps.
+func stageProcesses(id string) ([]int, error) {
+	if _, err := os.Stat("/proc"); err != nil {
+		out, err := exec.Command("ps", "-A", "-E", "-ww", "-o", "pid=,command=").Output()
+		if err != nil {
+			return nil, fmt.Errorf("unable to list processes: %w", err)
+		}
+		return parsePsStageProcesses(string(out), id), nil
+	}
+	environs, err := filepath.Glob("/proc/[0-9]*/environ")
+	if err != nil {
+		return nil, err
+	}
+	marker := []byte(STAGE_ID_ENV_VAR + "=" + id)
+	var pids []int
+	for _, environ := range environs {
+		env, err := os.ReadFile(environ)
+		if err != nil {
+			// process exited or is owned by another user
+			continue
+		}
+		if !slices.ContainsFunc(bytes.Split(env, []byte{0}), func(v []byte) bool { return bytes.Equal(v, marker) }) {
+			continue
+		}
+		if pid, err := strconv.Atoi(filepath.Base(filepath.Dir(environ))); err == nil {
+			pids = append(pids, pid)
+		}
+	}
+	return pids, nil
+}
+
+// parsePsStageProcesses returns the processes of stage id in the output of ps listing
+// the pid followed by the command and its environment for each process.
+func parsePsStageProcesses(out, id string) []int {
+	marker := STAGE_ID_ENV_VAR + "=" + id
+	var pids []int
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !slices.Contains(fields[1:], marker) {
+			continue
+		}
+		if pid, err := strconv.Atoi(fields[0]); err == nil {
+			pids = append(pids, pid)
+		}
+	}
+	return pids
+}
 
 // RunStage runs stage if stageName matches RUN_STAGE env var or RUN_STAGE is unset.
 // Similar to terratest RunStage but instead of skipping using env var, we match using envvar.
@@ -35,6 +149,77 @@ func RunStage(stageName string, stage func()) {
 
 }
 
+// runStageT runs stage similar to RunStage but logs whether the stage is run with t, so that
+// go test -json attributes the stage to the test when tests run in parallel.
+func runStageT(t testing.TB, stageName string, stage func()) {
+	if !shouldRunStage(stageName) {
+		t.Logf("Skipping stage %s", stageName)
+		return
+	}
+	t.Logf("Running stage %s", stageName)
+	stage()
+}
+
+// RunStageWithTimeout runs stage similar to RunStage but fails the test if stage does not
+// complete within timeout. A timeout of zero or less runs stage without a timeout.
+//
+// stage is called with a context identifying the stage run which is cancelled on timeout.
+// Commands started with the environment of StageEnv are interrupted on timeout, leaving commands
+// of other tests in the process running. Commands still running after a grace period are killed.
+// RunStageWithTimeout waits for stage to return and stops the test with FailNow, so that deferred
+// stages like teardown still run but not concurrently with the cancelled stage. If stage does not
+// return after its commands are killed, for example as it is blocked outside of commands, the test
+// is stopped regardless.
+func RunStageWithTimeout(t testing.TB, stageName string, timeout time.Duration, stage func(ctx context.Context)) {
+	if timeout <= 0 {
+		runStageT(t, stageName, func() { stage(context.Background()) })
+		return
+	}
+	runStageT(t, stageName, func() {
+		id := strconv.FormatUint(stageIDs.Add(1), 10)
+		ctx, cancel := context.WithCancel(context.WithValue(context.Background(), stageIDKey{}, id))
+		defer cancel()
+		done := make(chan struct{})
+		returned := false
+		go func() {
+			defer close(done)
+			stage(ctx)
+			returned = true
+		}()
+		select {
+		case <-done:
+			// stage called FailNow in its goroutine, stop the test
+			if !returned {
+				t.FailNow()
+			}
+			return
+		case <-time.After(timeout):
+		}
+
+		t.Errorf("stage %s exceeded timeout of %s", stageName, timeout)
+		cancel()
+		if err := signalStageCommands(id, os.Interrupt); err != nil {
+			t.Logf("unable to interrupt commands of stage %s: %v", stageName, err)
+		}
+		select {
+		case <-done:
+			t.FailNow()
+		case <-time.After(stageCancelGracePeriod):
+		}
+
+		t.Logf("stage %s did not stop within %s after interrupt, killing its commands", stageName, stageCancelGracePeriod)
+		if err := signalStageCommands(id, os.Kill); err != nil {
+			t.Logf("unable to kill commands of stage %s: %v", stageName, err)
+		}
+		select {
+		case <-done:
+		case <-time.After(stageKillGracePeriod):
+			t.Logf("stage %s did not stop within %s after its commands were killed, stopping the test", stageName, stageKillGracePeriod)
+		}
+		t.FailNow()
+	})
+}
+
 // shouldRunStage returns true if no explicit stage set via RUN_STAGE env var or if stageName matches value in RUN_STAGE.
 func shouldRunStage(stageName string) bool {
 	// no env var set, run all
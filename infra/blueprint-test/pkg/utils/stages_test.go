@@ -0,0 +1,276 @@
+/**
+ * Copyright 2024 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	testingiface "github.com/mitchellh/go-testing-interface"
+	"github.com/stretchr/testify/assert"
+)
+
+// stageT records failures, stopping the calling goroutine on FailNow like testing.T.
+type stageT struct {
+	testingiface.RuntimeT
+	mu      sync.Mutex
+	errs    []string
+	logs    []string
+	stopped bool
+}
+
+func (s *stageT) Errorf(format string, args ...interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errs = append(s.errs, fmt.Sprintf(format, args...))
+}
+
+func (s *stageT) Logf(format string, args ...interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logs = append(s.logs, fmt.Sprintf(format, args...))
+}
+
+func (s *stageT) FailNow() {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+	runtime.Goexit()
+}
+
+// stageSignals are the signals sent to commands of a stage, closed when the signal is sent.
+type stageSignals struct {
+	interrupt chan struct{}
+	kill      chan struct{}
+}
+
+func TestRunStageWithTimeout(t *testing.T) {
+	tests := []struct {
+		name        string
+		timeout     time.Duration
+		stage       func(context.Context, *stageT, stageSignals)
+		wantErrs    []string
+		wantLogs    []string
+		wantStopped bool
+		wantSignals []os.Signal
+		wantHung    bool
+	}{
+		{
+			name:    "no timeout",
+			timeout: 0,
+			stage:   func(context.Context, *stageT, stageSignals) {},
+		},
+		{
+			name:    "within timeout",
+			timeout: time.Minute,
+			stage:   func(context.Context, *stageT, stageSignals) {},
+		},
+		{
+			name:        "stage fails",
+			timeout:     time.Minute,
+			stage:       func(_ context.Context, st *stageT, _ stageSignals) { st.FailNow() },
+			wantStopped: true,
+		},
+		{
+			name:    "timeout",
+			timeout: 10 * time.Millisecond,
+			stage: func(ctx context.Context, _ *stageT, signals stageSignals) {
+				<-ctx.Done()
+				<-signals.interrupt
+			},
+			wantErrs:    []string{"stage apply exceeded timeout of 10ms"},
+			wantStopped: true,
+			wantSignals: []os.Signal{os.Interrupt},
+		},
+		{
+			name:    "interrupt ignored",
+			timeout: 10 * time.Millisecond,
+			stage: func(_ context.Context, _ *stageT, signals stageSignals) {
+				<-signals.kill
+			},
+			wantErrs:    []string{"stage apply exceeded timeout of 10ms"},
+			wantLogs:    []string{"stage apply did not stop within 50ms after interrupt, killing its commands"},
+			wantStopped: true,
+			wantSignals: []os.Signal{os.Interrupt, os.Kill},
+		},
+		{
+			name:    "hung",
+			timeout: 10 * time.Millisecond,
+			stage: func(context.Context, *stageT, stageSignals) {
+				select {}
+			},
+			wantErrs: []string{"stage apply exceeded timeout of 10ms"},
+			wantLogs: []string{
+				"stage apply did not stop within 50ms after interrupt, killing its commands",
+				"stage apply did not stop within 50ms after its commands were killed, stopping the test",
+			},
+			wantStopped: true,
+			wantSignals: []os.Signal{os.Interrupt, os.Kill},
+			wantHung:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signals := stageSignals{interrupt: make(chan struct{}), kill: make(chan struct{})}
+			// a hung stage keeps running after RunStageWithTimeout returns
+			var mu sync.Mutex
+			var sent []os.Signal
+			signaledID := ""
+			signalStageCommands = func(id string, sig os.Signal) error {
+				mu.Lock()
+				defer mu.Unlock()
+				signaledID = id
+				sent = append(sent, sig)
+				if sig == os.Interrupt {
+					close(signals.interrupt)
+				} else {
+					close(signals.kill)
+				}
+				return nil
+			}
+			defer func() { signalStageCommands = defaultSignalStageCommands }()
+			stageCancelGracePeriod, stageKillGracePeriod = 50*time.Millisecond, 50*time.Millisecond
+			defer func() { stageCancelGracePeriod, stageKillGracePeriod = 2*time.Minute, 30*time.Second }()
+
+			st := &stageT{}
+			ran, exited := false, false
+			stageID := ""
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				RunStageWithTimeout(st, "apply", tt.timeout, func(ctx context.Context) {
+					defer func() {
+						mu.Lock()
+						defer mu.Unlock()
+						exited = true
+					}()
+					mu.Lock()
+					ran = true
+					stageID = StageEnv(ctx)[STAGE_ID_ENV_VAR]
+					mu.Unlock()
+					tt.stage(ctx, st, signals)
+				})
+			}()
+			<-done
+			mu.Lock()
+			defer mu.Unlock()
+			assert.True(t, ran)
+			// teardown must not start before the stage stops unless it hangs after its commands are killed
+			assert.Equal(t, !tt.wantHung, exited)
+			assert.Equal(t, tt.wantErrs, st.errs)
+			wantLogs := append([]string{"Running stage apply"}, tt.wantLogs...)
+			assert.Equal(t, wantLogs, st.logs[:min(len(wantLogs), len(st.logs))])
+			assert.Equal(t, tt.wantStopped, st.stopped)
+			assert.Equal(t, tt.wantSignals, sent)
+			if len(tt.wantSignals) > 0 {
+				assert.NotEmpty(t, stageID)
+				assert.Equal(t, stageID, signaledID)
+			}
+		})
+	}
+}
+
+func TestRunStageWithTimeoutConcurrent(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("interrupting stage commands requires /proc")
+	}
+	// runs sleep for duration as a command of the stage running with ctx
+	sleep := func(ctx context.Context, duration string) error {
+		cmd := exec.Command("sleep", duration)
+		cmd.Env = os.Environ()
+		for k, v := range StageEnv(ctx) {
+			cmd.Env = append(cmd.Env, k+"="+v)
+		}
+		return cmd.Run()
+	}
+
+	var wg sync.WaitGroup
+	slowT, fastT := &stageT{}, &stageT{}
+	var slowErr, fastErr error
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		RunStageWithTimeout(slowT, "apply", 200*time.Millisecond, func(ctx context.Context) { slowErr = sleep(ctx, "30") })
+	}()
+	go func() {
+		defer wg.Done()
+		RunStageWithTimeout(fastT, "apply", time.Minute, func(ctx context.Context) { fastErr = sleep(ctx, "1") })
+	}()
+	wg.Wait()
+
+	assert.Equal(t, []string{"stage apply exceeded timeout of 200ms"}, slowT.errs)
+	assert.True(t, slowT.stopped)
+	assert.ErrorContains(t, slowErr, "signal: interrupt")
+	assert.Empty(t, fastT.errs)
+	assert.False(t, fastT.stopped)
+	assert.NoError(t, fastErr, "commands of other stages should not be interrupted")
+}
+
+func TestRunStageWithTimeoutKill(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("finding stage commands in tests requires /proc")
+	}
+	stageCancelGracePeriod = 100 * time.Millisecond
+	defer func() { stageCancelGracePeriod = 2 * time.Minute }()
+
+	st := &stageT{}
+	var err error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		RunStageWithTimeout(st, "apply", 100*time.Millisecond, func(ctx context.Context) {
+			// ignored signals are inherited by sleep
+			cmd := exec.Command("sh", "-c", `trap "" INT; sleep 30`)
+			cmd.Env = os.Environ()
+			for k, v := range StageEnv(ctx) {
+				cmd.Env = append(cmd.Env, k+"="+v)
+			}
+			err = cmd.Run()
+		})
+	}()
+	<-done
+
+	assert.Equal(t, []string{"stage apply exceeded timeout of 100ms"}, st.errs)
+	assert.Contains(t, st.logs, "stage apply did not stop within 100ms after interrupt, killing its commands")
+	assert.True(t, st.stopped)
+	assert.ErrorContains(t, err, "signal: killed")
+}
+
+func TestRunStageWithTimeoutSkipped(t *testing.T) {
+	t.Setenv(RUN_STAGE_ENV_VAR, "verify")
+	st := &stageT{}
+	ran := false
+	RunStageWithTimeout(st, "apply", time.Minute, func(context.Context) { ran = true })
+	assert.False(t, ran)
+	assert.Equal(t, []string{"Skipping stage apply"}, st.logs)
+}
+
+func TestParsePsStageProcesses(t *testing.T) {
+	out := `    1 /sbin/launchd
+  201 terraform apply -auto-approve PATH=/usr/bin CFT_STAGE_ID=1 HOME=/Users/me
+  202 terraform plan PATH=/usr/bin CFT_STAGE_ID=12
+  203 terraform-provider-google_v6 CFT_STAGE_ID=1
+`
+	assert.Equal(t, []int{201, 203}, parsePsStageProcesses(out, "1"))
+	assert.Empty(t, parsePsStageProcesses(out, "2"))
+}